@@ -14,6 +14,7 @@ import (
 	"context"
 	"github.com/goadesign/goa"
 	"net/http"
+	"strconv"
 )
 
 // GetLogResultsContext provides the Results getLog action context.
@@ -21,9 +22,13 @@ type GetLogResultsContext struct {
 	context.Context
 	*goa.ResponseData
 	*goa.RequestData
-	Check string
-	Date  string
-	Scan  string
+	IfModifiedSince *string
+	IfNoneMatch     *string
+	Range           *string
+	Check           string
+	Date            string
+	Redirect        *bool
+	Scan            string
 }
 
 // NewGetLogResultsContext parses the incoming request URL and body, performs validations and creates the
@@ -35,6 +40,24 @@ func NewGetLogResultsContext(ctx context.Context, r *http.Request, service *goa.
 	req := goa.ContextRequest(ctx)
 	req.Request = r
 	rctx := GetLogResultsContext{Context: ctx, ResponseData: resp, RequestData: req}
+	headerIfModifiedSince := req.Header["If-Modified-Since"]
+	if len(headerIfModifiedSince) > 0 {
+		rawIfModifiedSince := headerIfModifiedSince[0]
+		req.Params["If-Modified-Since"] = []string{rawIfModifiedSince}
+		rctx.IfModifiedSince = &rawIfModifiedSince
+	}
+	headerIfNoneMatch := req.Header["If-None-Match"]
+	if len(headerIfNoneMatch) > 0 {
+		rawIfNoneMatch := headerIfNoneMatch[0]
+		req.Params["If-None-Match"] = []string{rawIfNoneMatch}
+		rctx.IfNoneMatch = &rawIfNoneMatch
+	}
+	headerRange := req.Header["Range"]
+	if len(headerRange) > 0 {
+		rawRange := headerRange[0]
+		req.Params["Range"] = []string{rawRange}
+		rctx.Range = &rawRange
+	}
 	paramCheck := req.Params["check"]
 	if len(paramCheck) > 0 {
 		rawCheck := paramCheck[0]
@@ -45,6 +68,16 @@ func NewGetLogResultsContext(ctx context.Context, r *http.Request, service *goa.
 		rawDate := paramDate[0]
 		rctx.Date = rawDate
 	}
+	paramRedirect := req.Params["redirect"]
+	if len(paramRedirect) > 0 {
+		rawRedirect := paramRedirect[0]
+		if redirect, err2 := strconv.ParseBool(rawRedirect); err2 == nil {
+			tmp1 := &redirect
+			rctx.Redirect = tmp1
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("redirect", rawRedirect, "boolean"))
+		}
+	}
 	paramScan := req.Params["scan"]
 	if len(paramScan) > 0 {
 		rawScan := paramScan[0]
@@ -63,20 +96,92 @@ func (ctx *GetLogResultsContext) OK(resp []byte) error {
 	return err
 }
 
-// BadRequest sends a HTTP response with status code 400.
-func (ctx *GetLogResultsContext) BadRequest() error {
-	ctx.ResponseData.WriteHeader(400)
+// PartialContent sends a HTTP response with status code 206.
+func (ctx *GetLogResultsContext) PartialContent() error {
+	ctx.ResponseData.WriteHeader(206)
+	return nil
+}
+
+// Found sends a HTTP response with status code 302.
+func (ctx *GetLogResultsContext) Found() error {
+	ctx.ResponseData.WriteHeader(302)
+	return nil
+}
+
+// NotModified sends a HTTP response with status code 304.
+func (ctx *GetLogResultsContext) NotModified() error {
+	ctx.ResponseData.WriteHeader(304)
 	return nil
 }
 
+// BadRequest sends a HTTP response with status code 400.
+func (ctx *GetLogResultsContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *GetLogResultsContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *GetLogResultsContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// NotFound sends a HTTP response with status code 404.
+func (ctx *GetLogResultsContext) NotFound(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 404, r)
+}
+
+// RequestedRangeNotSatisfiable sends a HTTP response with status code 416.
+func (ctx *GetLogResultsContext) RequestedRangeNotSatisfiable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 416, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *GetLogResultsContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *GetLogResultsContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
 // GetReportResultsContext provides the Results getReport action context.
 type GetReportResultsContext struct {
 	context.Context
 	*goa.ResponseData
 	*goa.RequestData
-	Check string
-	Date  string
-	Scan  string
+	IfModifiedSince *string
+	IfNoneMatch     *string
+	Range           *string
+	Check           string
+	Date            string
+	Redirect        *bool
+	Scan            string
 }
 
 // NewGetReportResultsContext parses the incoming request URL and body, performs validations and creates the
@@ -88,6 +193,24 @@ func NewGetReportResultsContext(ctx context.Context, r *http.Request, service *g
 	req := goa.ContextRequest(ctx)
 	req.Request = r
 	rctx := GetReportResultsContext{Context: ctx, ResponseData: resp, RequestData: req}
+	headerIfModifiedSince := req.Header["If-Modified-Since"]
+	if len(headerIfModifiedSince) > 0 {
+		rawIfModifiedSince := headerIfModifiedSince[0]
+		req.Params["If-Modified-Since"] = []string{rawIfModifiedSince}
+		rctx.IfModifiedSince = &rawIfModifiedSince
+	}
+	headerIfNoneMatch := req.Header["If-None-Match"]
+	if len(headerIfNoneMatch) > 0 {
+		rawIfNoneMatch := headerIfNoneMatch[0]
+		req.Params["If-None-Match"] = []string{rawIfNoneMatch}
+		rctx.IfNoneMatch = &rawIfNoneMatch
+	}
+	headerRange := req.Header["Range"]
+	if len(headerRange) > 0 {
+		rawRange := headerRange[0]
+		req.Params["Range"] = []string{rawRange}
+		rctx.Range = &rawRange
+	}
 	paramCheck := req.Params["check"]
 	if len(paramCheck) > 0 {
 		rawCheck := paramCheck[0]
@@ -98,6 +221,16 @@ func NewGetReportResultsContext(ctx context.Context, r *http.Request, service *g
 		rawDate := paramDate[0]
 		rctx.Date = rawDate
 	}
+	paramRedirect := req.Params["redirect"]
+	if len(paramRedirect) > 0 {
+		rawRedirect := paramRedirect[0]
+		if redirect, err2 := strconv.ParseBool(rawRedirect); err2 == nil {
+			tmp2 := &redirect
+			rctx.Redirect = tmp2
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("redirect", rawRedirect, "boolean"))
+		}
+	}
 	paramScan := req.Params["scan"]
 	if len(paramScan) > 0 {
 		rawScan := paramScan[0]
@@ -116,12 +249,180 @@ func (ctx *GetReportResultsContext) OK(resp []byte) error {
 	return err
 }
 
+// PartialContent sends a HTTP response with status code 206.
+func (ctx *GetReportResultsContext) PartialContent() error {
+	ctx.ResponseData.WriteHeader(206)
+	return nil
+}
+
+// Found sends a HTTP response with status code 302.
+func (ctx *GetReportResultsContext) Found() error {
+	ctx.ResponseData.WriteHeader(302)
+	return nil
+}
+
+// NotModified sends a HTTP response with status code 304.
+func (ctx *GetReportResultsContext) NotModified() error {
+	ctx.ResponseData.WriteHeader(304)
+	return nil
+}
+
 // BadRequest sends a HTTP response with status code 400.
-func (ctx *GetReportResultsContext) BadRequest() error {
-	ctx.ResponseData.WriteHeader(400)
+func (ctx *GetReportResultsContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *GetReportResultsContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *GetReportResultsContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// NotFound sends a HTTP response with status code 404.
+func (ctx *GetReportResultsContext) NotFound(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 404, r)
+}
+
+// RequestedRangeNotSatisfiable sends a HTTP response with status code 416.
+func (ctx *GetReportResultsContext) RequestedRangeNotSatisfiable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 416, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *GetReportResultsContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *GetReportResultsContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
+// PutLogResultsContext provides the Results putLog action context.
+type PutLogResultsContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Check string
+	Date  string
+	Scan  string
+}
+
+// NewPutLogResultsContext parses the incoming request URL and body, performs validations and creates the
+// context used by the Results controller putLog action.
+func NewPutLogResultsContext(ctx context.Context, r *http.Request, service *goa.Service) (*PutLogResultsContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	req.Request = r
+	rctx := PutLogResultsContext{Context: ctx, ResponseData: resp, RequestData: req}
+	paramCheck := req.Params["check"]
+	if len(paramCheck) > 0 {
+		rawCheck := paramCheck[0]
+		rctx.Check = rawCheck
+		if ok := goa.ValidatePattern(`^.+\.log$`, rctx.Check); !ok {
+			err = goa.MergeErrors(err, goa.InvalidPatternError(`check`, rctx.Check, `^.+\.log$`))
+		}
+	}
+	paramDate := req.Params["date"]
+	if len(paramDate) > 0 {
+		rawDate := paramDate[0]
+		rctx.Date = rawDate
+		if ok := goa.ValidatePattern(`^dt=\d{4}-\d{2}-\d{2}$`, rctx.Date); !ok {
+			err = goa.MergeErrors(err, goa.InvalidPatternError(`date`, rctx.Date, `^dt=\d{4}-\d{2}-\d{2}$`))
+		}
+	}
+	paramScan := req.Params["scan"]
+	if len(paramScan) > 0 {
+		rawScan := paramScan[0]
+		rctx.Scan = rawScan
+		if ok := goa.ValidatePattern(`^scan=.+$`, rctx.Scan); !ok {
+			err = goa.MergeErrors(err, goa.InvalidPatternError(`scan`, rctx.Scan, `^scan=.+$`))
+		}
+	}
+	return &rctx, err
+}
+
+// Created sends a HTTP response with status code 201.
+func (ctx *PutLogResultsContext) Created() error {
+	ctx.ResponseData.WriteHeader(201)
 	return nil
 }
 
+// BadRequest sends a HTTP response with status code 400.
+func (ctx *PutLogResultsContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *PutLogResultsContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *PutLogResultsContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// RequestEntityTooLarge sends a HTTP response with status code 413.
+func (ctx *PutLogResultsContext) RequestEntityTooLarge(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 413, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *PutLogResultsContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *PutLogResultsContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
 // RawResultsContext provides the Results raw action context.
 type RawResultsContext struct {
 	context.Context
@@ -148,12 +449,52 @@ func (ctx *RawResultsContext) Created() error {
 	return nil
 }
 
-// BadRequest sends a HTTP response with status code 400.
-func (ctx *RawResultsContext) BadRequest() error {
-	ctx.ResponseData.WriteHeader(400)
+// Accepted sends a HTTP response with status code 202.
+func (ctx *RawResultsContext) Accepted() error {
+	ctx.ResponseData.WriteHeader(202)
 	return nil
 }
 
+// BadRequest sends a HTTP response with status code 400.
+func (ctx *RawResultsContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *RawResultsContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *RawResultsContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *RawResultsContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *RawResultsContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
 // ReportResultsContext provides the Results report action context.
 type ReportResultsContext struct {
 	context.Context
@@ -180,12 +521,166 @@ func (ctx *ReportResultsContext) Created() error {
 	return nil
 }
 
-// BadRequest sends a HTTP response with status code 400.
-func (ctx *ReportResultsContext) BadRequest() error {
-	ctx.ResponseData.WriteHeader(400)
+// Accepted sends a HTTP response with status code 202.
+func (ctx *ReportResultsContext) Accepted() error {
+	ctx.ResponseData.WriteHeader(202)
 	return nil
 }
 
+// BadRequest sends a HTTP response with status code 400.
+func (ctx *ReportResultsContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *ReportResultsContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *ReportResultsContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// Conflict sends a HTTP response with status code 409.
+func (ctx *ReportResultsContext) Conflict(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 409, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *ReportResultsContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *ReportResultsContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
+// ListChecksContext provides the checks list action context.
+type ListChecksContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Date  string
+	Limit int
+	Next  *string
+	Scan  string
+}
+
+// NewListChecksContext parses the incoming request URL and body, performs validations and creates the
+// context used by the checks controller list action.
+func NewListChecksContext(ctx context.Context, r *http.Request, service *goa.Service) (*ListChecksContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	req.Request = r
+	rctx := ListChecksContext{Context: ctx, ResponseData: resp, RequestData: req}
+	paramDate := req.Params["date"]
+	if len(paramDate) > 0 {
+		rawDate := paramDate[0]
+		rctx.Date = rawDate
+		if ok := goa.ValidatePattern(`^\d{4}-\d{2}-\d{2}$`, rctx.Date); !ok {
+			err = goa.MergeErrors(err, goa.InvalidPatternError(`date`, rctx.Date, `^\d{4}-\d{2}-\d{2}$`))
+		}
+	}
+	paramLimit := req.Params["limit"]
+	if len(paramLimit) == 0 {
+		rctx.Limit = 100
+	} else {
+		rawLimit := paramLimit[0]
+		if limit, err2 := strconv.Atoi(rawLimit); err2 == nil {
+			rctx.Limit = limit
+		} else {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("limit", rawLimit, "integer"))
+		}
+		if rctx.Limit < 1 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError(`limit`, rctx.Limit, 1, true))
+		}
+		if rctx.Limit > 1000 {
+			err = goa.MergeErrors(err, goa.InvalidRangeError(`limit`, rctx.Limit, 1000, false))
+		}
+	}
+	paramNext := req.Params["next"]
+	if len(paramNext) > 0 {
+		rawNext := paramNext[0]
+		rctx.Next = &rawNext
+	}
+	paramScan := req.Params["scan"]
+	if len(paramScan) > 0 {
+		rawScan := paramScan[0]
+		rctx.Scan = rawScan
+	}
+	return &rctx, err
+}
+
+// OK sends a HTTP response with status code 200.
+func (ctx *ListChecksContext) OK(r *CheckList) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.vulcan.check-list+json")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+
+// BadRequest sends a HTTP response with status code 400.
+func (ctx *ListChecksContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *ListChecksContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *ListChecksContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *ListChecksContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *ListChecksContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
 // ShowHealthcheckContext provides the healthcheck show action context.
 type ShowHealthcheckContext struct {
 	context.Context
@@ -214,3 +709,338 @@ func (ctx *ShowHealthcheckContext) OK(resp []byte) error {
 	_, err := ctx.ResponseData.Write(resp)
 	return err
 }
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *ShowHealthcheckContext) ServiceUnavailable() error {
+	ctx.ResponseData.WriteHeader(503)
+	return nil
+}
+
+// LogLinksContext provides the links log action context.
+type LogLinksContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Check string
+	Date  string
+	Scan  string
+}
+
+// NewLogLinksContext parses the incoming request URL and body, performs validations and creates the
+// context used by the links controller log action.
+func NewLogLinksContext(ctx context.Context, r *http.Request, service *goa.Service) (*LogLinksContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	req.Request = r
+	rctx := LogLinksContext{Context: ctx, ResponseData: resp, RequestData: req}
+	paramCheck := req.Params["check"]
+	if len(paramCheck) > 0 {
+		rawCheck := paramCheck[0]
+		rctx.Check = rawCheck
+	}
+	paramDate := req.Params["date"]
+	if len(paramDate) > 0 {
+		rawDate := paramDate[0]
+		rctx.Date = rawDate
+	}
+	paramScan := req.Params["scan"]
+	if len(paramScan) > 0 {
+		rawScan := paramScan[0]
+		rctx.Scan = rawScan
+	}
+	return &rctx, err
+}
+
+// OK sends a HTTP response with status code 200.
+func (ctx *LogLinksContext) OK(r *PresignedLink) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.vulcan.presigned-link+json")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+
+// BadRequest sends a HTTP response with status code 400.
+func (ctx *LogLinksContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *LogLinksContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *LogLinksContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// NotFound sends a HTTP response with status code 404.
+func (ctx *LogLinksContext) NotFound(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 404, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *LogLinksContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// NotImplemented sends a HTTP response with status code 501.
+func (ctx *LogLinksContext) NotImplemented(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 501, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *LogLinksContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
+// ReportLinksContext provides the links report action context.
+type ReportLinksContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	Check string
+	Date  string
+	Scan  string
+}
+
+// NewReportLinksContext parses the incoming request URL and body, performs validations and creates the
+// context used by the links controller report action.
+func NewReportLinksContext(ctx context.Context, r *http.Request, service *goa.Service) (*ReportLinksContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	req.Request = r
+	rctx := ReportLinksContext{Context: ctx, ResponseData: resp, RequestData: req}
+	paramCheck := req.Params["check"]
+	if len(paramCheck) > 0 {
+		rawCheck := paramCheck[0]
+		rctx.Check = rawCheck
+	}
+	paramDate := req.Params["date"]
+	if len(paramDate) > 0 {
+		rawDate := paramDate[0]
+		rctx.Date = rawDate
+	}
+	paramScan := req.Params["scan"]
+	if len(paramScan) > 0 {
+		rawScan := paramScan[0]
+		rctx.Scan = rawScan
+	}
+	return &rctx, err
+}
+
+// OK sends a HTTP response with status code 200.
+func (ctx *ReportLinksContext) OK(r *PresignedLink) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.vulcan.presigned-link+json")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+
+// BadRequest sends a HTTP response with status code 400.
+func (ctx *ReportLinksContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *ReportLinksContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *ReportLinksContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// NotFound sends a HTTP response with status code 404.
+func (ctx *ReportLinksContext) NotFound(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 404, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *ReportLinksContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// NotImplemented sends a HTTP response with status code 501.
+func (ctx *ReportLinksContext) NotImplemented(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 501, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *ReportLinksContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
+// ShowReadinessContext provides the readiness show action context.
+type ShowReadinessContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+}
+
+// NewShowReadinessContext parses the incoming request URL and body, performs validations and creates the
+// context used by the readiness controller show action.
+func NewShowReadinessContext(ctx context.Context, r *http.Request, service *goa.Service) (*ShowReadinessContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	req.Request = r
+	rctx := ShowReadinessContext{Context: ctx, ResponseData: resp, RequestData: req}
+	return &rctx, err
+}
+
+// OK sends a HTTP response with status code 200.
+func (ctx *ShowReadinessContext) OK(r *Readiness) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.vulcan.readiness+json")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *ShowReadinessContext) ServiceUnavailable(r *Readiness) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.vulcan.readiness+json")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
+
+// ListScansContext provides the scans list action context.
+type ListScansContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	From string
+	To   *string
+}
+
+// NewListScansContext parses the incoming request URL and body, performs validations and creates the
+// context used by the scans controller list action.
+func NewListScansContext(ctx context.Context, r *http.Request, service *goa.Service) (*ListScansContext, error) {
+	var err error
+	resp := goa.ContextResponse(ctx)
+	resp.Service = service
+	req := goa.ContextRequest(ctx)
+	req.Request = r
+	rctx := ListScansContext{Context: ctx, ResponseData: resp, RequestData: req}
+	paramFrom := req.Params["from"]
+	if len(paramFrom) == 0 {
+		err = goa.MergeErrors(err, goa.MissingParamError("from"))
+	} else {
+		rawFrom := paramFrom[0]
+		rctx.From = rawFrom
+		if ok := goa.ValidatePattern(`^\d{4}-\d{2}-\d{2}$`, rctx.From); !ok {
+			err = goa.MergeErrors(err, goa.InvalidPatternError(`from`, rctx.From, `^\d{4}-\d{2}-\d{2}$`))
+		}
+	}
+	paramTo := req.Params["to"]
+	if len(paramTo) > 0 {
+		rawTo := paramTo[0]
+		rctx.To = &rawTo
+		if rctx.To != nil {
+			if ok := goa.ValidatePattern(`^\d{4}-\d{2}-\d{2}$`, *rctx.To); !ok {
+				err = goa.MergeErrors(err, goa.InvalidPatternError(`to`, *rctx.To, `^\d{4}-\d{2}-\d{2}$`))
+			}
+		}
+	}
+	return &rctx, err
+}
+
+// OK sends a HTTP response with status code 200.
+func (ctx *ListScansContext) OK(r ScanSummaryCollection) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.vulcan.scan-summary+json; type=collection")
+	}
+	if r == nil {
+		r = ScanSummaryCollection{}
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+
+// BadRequest sends a HTTP response with status code 400.
+func (ctx *ListScansContext) BadRequest(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 400, r)
+}
+
+// Unauthorized sends a HTTP response with status code 401.
+func (ctx *ListScansContext) Unauthorized(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 401, r)
+}
+
+// Forbidden sends a HTTP response with status code 403.
+func (ctx *ListScansContext) Forbidden(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 403, r)
+}
+
+// InternalServerError sends a HTTP response with status code 500.
+func (ctx *ListScansContext) InternalServerError(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 500, r)
+}
+
+// ServiceUnavailable sends a HTTP response with status code 503.
+func (ctx *ListScansContext) ServiceUnavailable(r error) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.error")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 503, r)
+}
@@ -34,6 +34,7 @@ type ResultsController interface {
 	goa.Muxer
 	GetLog(*GetLogResultsContext) error
 	GetReport(*GetReportResultsContext) error
+	PutLog(*PutLogResultsContext) error
 	Raw(*RawResultsContext) error
 	Report(*ReportResultsContext) error
 }
@@ -55,8 +56,9 @@ func MountResultsController(service *goa.Service, ctrl ResultsController) {
 		}
 		return ctrl.GetLog(rctx)
 	}
+	h = handleSecurity("results", h, "results:read")
 	service.Mux.Handle("GET", "/v1/logs/:date/:scan/:check", ctrl.MuxHandler("getLog", h, nil))
-	service.LogInfo("mount", "ctrl", "Results", "action", "GetLog", "route", "GET /v1/logs/:date/:scan/:check")
+	service.LogInfo("mount", "ctrl", "Results", "action", "GetLog", "route", "GET /v1/logs/:date/:scan/:check", "security", "results")
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
@@ -70,8 +72,25 @@ func MountResultsController(service *goa.Service, ctrl ResultsController) {
 		}
 		return ctrl.GetReport(rctx)
 	}
+	h = handleSecurity("results", h, "results:read")
 	service.Mux.Handle("GET", "/v1/reports/:date/:scan/:check", ctrl.MuxHandler("getReport", h, nil))
-	service.LogInfo("mount", "ctrl", "Results", "action", "GetReport", "route", "GET /v1/reports/:date/:scan/:check")
+	service.LogInfo("mount", "ctrl", "Results", "action", "GetReport", "route", "GET /v1/reports/:date/:scan/:check", "security", "results")
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Build the context
+		rctx, err := NewPutLogResultsContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		return ctrl.PutLog(rctx)
+	}
+	h = handleSecurity("results", h, "results:write")
+	service.Mux.Handle("PUT", "/v1/logs/:date/:scan/:check", ctrl.MuxHandler("putLog", h, nil))
+	service.LogInfo("mount", "ctrl", "Results", "action", "PutLog", "route", "PUT /v1/logs/:date/:scan/:check", "security", "results")
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
@@ -91,8 +110,9 @@ func MountResultsController(service *goa.Service, ctrl ResultsController) {
 		}
 		return ctrl.Raw(rctx)
 	}
+	h = handleSecurity("results", h, "results:write")
 	service.Mux.Handle("POST", "/v1/raw", ctrl.MuxHandler("raw", h, unmarshalRawResultsPayload))
-	service.LogInfo("mount", "ctrl", "Results", "action", "Raw", "route", "POST /v1/raw")
+	service.LogInfo("mount", "ctrl", "Results", "action", "Raw", "route", "POST /v1/raw", "security", "results")
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Check if there was an error loading the request
@@ -112,8 +132,9 @@ func MountResultsController(service *goa.Service, ctrl ResultsController) {
 		}
 		return ctrl.Report(rctx)
 	}
+	h = handleSecurity("results", h, "results:write")
 	service.Mux.Handle("POST", "/v1/report", ctrl.MuxHandler("report", h, unmarshalReportResultsPayload))
-	service.LogInfo("mount", "ctrl", "Results", "action", "Report", "route", "POST /v1/report")
+	service.LogInfo("mount", "ctrl", "Results", "action", "Report", "route", "POST /v1/report", "security", "results")
 }
 
 // unmarshalRawResultsPayload unmarshals the request body into the context request data Payload field.
@@ -132,6 +153,7 @@ func unmarshalReportResultsPayload(ctx context.Context, service *goa.Service, re
 	if err := service.DecodeRequest(req, payload); err != nil {
 		return err
 	}
+	payload.Finalize()
 	if err := payload.Validate(); err != nil {
 		// Initialize payload with private data structure so it can be logged
 		goa.ContextRequest(ctx).Payload = payload
@@ -141,6 +163,34 @@ func unmarshalReportResultsPayload(ctx context.Context, service *goa.Service, re
 	return nil
 }
 
+// ChecksController is the controller interface for the Checks actions.
+type ChecksController interface {
+	goa.Muxer
+	List(*ListChecksContext) error
+}
+
+// MountChecksController "mounts" a Checks resource controller on the given service.
+func MountChecksController(service *goa.Service, ctrl ChecksController) {
+	initService(service)
+	var h goa.Handler
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Build the context
+		rctx, err := NewListChecksContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		return ctrl.List(rctx)
+	}
+	h = handleSecurity("results", h, "results:read")
+	service.Mux.Handle("GET", "/v1/scans/:date/:scan/checks", ctrl.MuxHandler("list", h, nil))
+	service.LogInfo("mount", "ctrl", "Checks", "action", "List", "route", "GET /v1/scans/:date/:scan/checks", "security", "results")
+}
+
 // HealthcheckController is the controller interface for the Healthcheck actions.
 type HealthcheckController interface {
 	goa.Muxer
@@ -167,3 +217,103 @@ func MountHealthcheckController(service *goa.Service, ctrl HealthcheckController
 	service.Mux.Handle("GET", "/healthcheck", ctrl.MuxHandler("show", h, nil))
 	service.LogInfo("mount", "ctrl", "Healthcheck", "action", "Show", "route", "GET /healthcheck")
 }
+
+// LinksController is the controller interface for the Links actions.
+type LinksController interface {
+	goa.Muxer
+	Log(*LogLinksContext) error
+	Report(*ReportLinksContext) error
+}
+
+// MountLinksController "mounts" a Links resource controller on the given service.
+func MountLinksController(service *goa.Service, ctrl LinksController) {
+	initService(service)
+	var h goa.Handler
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Build the context
+		rctx, err := NewLogLinksContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		return ctrl.Log(rctx)
+	}
+	h = handleSecurity("results", h, "results:read")
+	service.Mux.Handle("GET", "/v1/links/logs/:date/:scan/:check", ctrl.MuxHandler("log", h, nil))
+	service.LogInfo("mount", "ctrl", "Links", "action", "Log", "route", "GET /v1/links/logs/:date/:scan/:check", "security", "results")
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Build the context
+		rctx, err := NewReportLinksContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		return ctrl.Report(rctx)
+	}
+	h = handleSecurity("results", h, "results:read")
+	service.Mux.Handle("GET", "/v1/links/reports/:date/:scan/:check", ctrl.MuxHandler("report", h, nil))
+	service.LogInfo("mount", "ctrl", "Links", "action", "Report", "route", "GET /v1/links/reports/:date/:scan/:check", "security", "results")
+}
+
+// ReadinessController is the controller interface for the Readiness actions.
+type ReadinessController interface {
+	goa.Muxer
+	Show(*ShowReadinessContext) error
+}
+
+// MountReadinessController "mounts" a Readiness resource controller on the given service.
+func MountReadinessController(service *goa.Service, ctrl ReadinessController) {
+	initService(service)
+	var h goa.Handler
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Build the context
+		rctx, err := NewShowReadinessContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		return ctrl.Show(rctx)
+	}
+	service.Mux.Handle("GET", "/readiness", ctrl.MuxHandler("show", h, nil))
+	service.LogInfo("mount", "ctrl", "Readiness", "action", "Show", "route", "GET /readiness")
+}
+
+// ScansController is the controller interface for the Scans actions.
+type ScansController interface {
+	goa.Muxer
+	List(*ListScansContext) error
+}
+
+// MountScansController "mounts" a Scans resource controller on the given service.
+func MountScansController(service *goa.Service, ctrl ScansController) {
+	initService(service)
+	var h goa.Handler
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Build the context
+		rctx, err := NewListScansContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		return ctrl.List(rctx)
+	}
+	h = handleSecurity("results", h, "results:read")
+	service.Mux.Handle("GET", "/v1/scans", ctrl.MuxHandler("list", h, nil))
+	service.LogInfo("mount", "ctrl", "Scans", "action", "List", "route", "GET /v1/scans", "security", "results")
+}
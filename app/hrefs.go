@@ -14,3 +14,8 @@ package app
 func HealthcheckHref() string {
 	return "/healthcheck"
 }
+
+// ReadinessHref returns the resource href.
+func ReadinessHref() string {
+	return "/readiness"
+}
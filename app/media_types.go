@@ -9,3 +9,165 @@
 // --version=v1.4.3
 
 package app
+
+import (
+	"github.com/goadesign/goa"
+	"time"
+)
+
+// A page of the reports and logs stored for the checks of a scan (default view)
+//
+// Identifier: application/vnd.vulcan.check-list+json; view=default
+type CheckList struct {
+	// Reports and logs of the checks
+	Checks CheckObjectCollection `form:"checks" json:"checks" yaml:"checks" xml:"checks"`
+	// Token to get the next page, empty if this is the last one
+	Next *string `form:"next,omitempty" json:"next,omitempty" yaml:"next,omitempty" xml:"next,omitempty"`
+}
+
+// Validate validates the CheckList media type instance.
+func (mt *CheckList) Validate() (err error) {
+	if mt.Checks == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`response`, "checks"))
+	}
+	if err2 := mt.Checks.Validate(); err2 != nil {
+		err = goa.MergeErrors(err, err2)
+	}
+	return
+}
+
+// A report or log stored for a check (default view)
+//
+// Identifier: application/vnd.vulcan.check-object+json; view=default
+type CheckObject struct {
+	// Check ID
+	CheckID string `form:"check_id" json:"check_id" yaml:"check_id" xml:"check_id"`
+	// Kind of the object
+	Kind string `form:"kind" json:"kind" yaml:"kind" xml:"kind"`
+	// Last time the object was modified
+	LastModified time.Time `form:"last_modified" json:"last_modified" yaml:"last_modified" xml:"last_modified"`
+	// Size of the object in bytes
+	Size int `form:"size" json:"size" yaml:"size" xml:"size"`
+}
+
+// Validate validates the CheckObject media type instance.
+func (mt *CheckObject) Validate() (err error) {
+	if mt.CheckID == "" {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`response`, "check_id"))
+	}
+	if mt.Kind == "" {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`response`, "kind"))
+	}
+
+	if !(mt.Kind == "report" || mt.Kind == "log") {
+		err = goa.MergeErrors(err, goa.InvalidEnumValueError(`response.kind`, mt.Kind, []interface{}{"report", "log"}))
+	}
+	return
+}
+
+// CheckObjectCollection is the media type for an array of CheckObject (default view)
+//
+// Identifier: application/vnd.vulcan.check-object+json; type=collection; view=default
+type CheckObjectCollection []*CheckObject
+
+// Validate validates the CheckObjectCollection media type instance.
+func (mt CheckObjectCollection) Validate() (err error) {
+	for _, e := range mt {
+		if e != nil {
+			if err2 := e.Validate(); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	return
+}
+
+// A time-limited URL to download a report or a log (default view)
+//
+// Identifier: application/vnd.vulcan.presigned-link+json; view=default
+type PresignedLink struct {
+	// Time the URL expires
+	ExpiresAt time.Time `form:"expires_at" json:"expires_at" yaml:"expires_at" xml:"expires_at"`
+	// Presigned URL
+	URL string `form:"url" json:"url" yaml:"url" xml:"url"`
+}
+
+// Validate validates the PresignedLink media type instance.
+func (mt *PresignedLink) Validate() (err error) {
+	if mt.URL == "" {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`response`, "url"))
+	}
+
+	return
+}
+
+// The readiness of the service and the status of its dependencies (default view)
+//
+// Identifier: application/vnd.vulcan.readiness+json; view=default
+type Readiness struct {
+	// Time the dependencies were checked
+	CheckedAt time.Time `form:"checked_at" json:"checked_at" yaml:"checked_at" xml:"checked_at"`
+	// Status of the dependencies
+	Dependencies []*ReadinessDependency `form:"dependencies" json:"dependencies" yaml:"dependencies" xml:"dependencies"`
+	// Whether every dependency is ready
+	Ready bool `form:"ready" json:"ready" yaml:"ready" xml:"ready"`
+}
+
+// Validate validates the Readiness media type instance.
+func (mt *Readiness) Validate() (err error) {
+
+	if mt.Dependencies == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`response`, "dependencies"))
+	}
+	for _, e := range mt.Dependencies {
+		if e != nil {
+			if err2 := e.Validate(); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	return
+}
+
+// Summary of the reports stored for a scan (default view)
+//
+// Identifier: application/vnd.vulcan.scan-summary+json; view=default
+type ScanSummary struct {
+	// Number of checks with a report
+	Checks int `form:"checks" json:"checks" yaml:"checks" xml:"checks"`
+	// Date the scan started (YYYY-MM-DD)
+	Date string `form:"date" json:"date" yaml:"date" xml:"date"`
+	// Scan ID
+	ScanID string `form:"scan_id" json:"scan_id" yaml:"scan_id" xml:"scan_id"`
+	// Whether any of the reports has vulnerabilities
+	Vulnerable bool `form:"vulnerable" json:"vulnerable" yaml:"vulnerable" xml:"vulnerable"`
+}
+
+// Validate validates the ScanSummary media type instance.
+func (mt *ScanSummary) Validate() (err error) {
+	if mt.ScanID == "" {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`response`, "scan_id"))
+	}
+	if mt.Date == "" {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`response`, "date"))
+	}
+
+	return
+}
+
+// ScanSummaryCollection is the media type for an array of ScanSummary (default view)
+//
+// Identifier: application/vnd.vulcan.scan-summary+json; type=collection; view=default
+type ScanSummaryCollection []*ScanSummary
+
+// Validate validates the ScanSummaryCollection media type instance.
+func (mt ScanSummaryCollection) Validate() (err error) {
+	for _, e := range mt {
+		if e != nil {
+			if err2 := e.Validate(); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+		}
+	}
+	return
+}
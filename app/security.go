@@ -0,0 +1,55 @@
+// Code generated by goagen v1.4.3, DO NOT EDIT.
+//
+// API "vulcan-results": Application Security
+//
+// Command:
+// $ goagen
+// --design=github.com/adevinta/vulcan-results/design
+// --out=/Users/manel.montilla/develop/vulcan-results
+// --version=v1.4.3
+
+package app
+
+import (
+	"context"
+	"github.com/goadesign/goa"
+	"net/http"
+)
+
+type (
+	// Private type used to store auth handler info in request context
+	authMiddlewareKey string
+)
+
+// UseResultsMiddleware mounts the results auth middleware onto the service.
+func UseResultsMiddleware(service *goa.Service, middleware goa.Middleware) {
+	service.Context = context.WithValue(service.Context, authMiddlewareKey("results"), middleware)
+}
+
+// NewResultsSecurity creates a results security definition.
+func NewResultsSecurity() *goa.JWTSecurity {
+	def := goa.JWTSecurity{
+		In:       goa.LocHeader,
+		Name:     "Authorization",
+		TokenURL: "",
+		Scopes: map[string]string{
+			"results:read":  "Download reports and logs",
+			"results:write": "Upload reports and logs",
+		},
+	}
+	def.Description = "The Authorization header carries one of:\n- a static token: \"Bearer <token>\"\n- a JWT signed with a key of the configured JWKS: \"Bearer <jwt>\"\n- an HMAC-SHA256 signature: \"HMAC-SHA256 KeyId=<id>, Signature=<hex signature>\"\n\nHMAC signatures are computed over the method, the path and query, the X-Vulcan-Timestamp header (Unix seconds) and the X-Content-SHA256 header (hex SHA-256 of the body), joined by new lines."
+	return &def
+}
+
+// handleSecurity creates a handler that runs the auth middleware for the security scheme.
+func handleSecurity(schemeName string, h goa.Handler, scopes ...string) goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		scheme := ctx.Value(authMiddlewareKey(schemeName))
+		am, ok := scheme.(goa.Middleware)
+		if !ok {
+			return goa.NoAuthMiddleware(schemeName)
+		}
+		ctx = goa.WithRequiredScopes(ctx, scopes)
+		return am(h)(ctx, rw, req)
+	}
+}
@@ -25,15 +25,16 @@ import (
 )
 
 // GetLogResultsBadRequest runs the method GetLog of the given controller with the given parameters.
-// It returns the response writer so it's possible to inspect the response headers.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func GetLogResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) http.ResponseWriter {
+func GetLogResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
 	// Setup service
 	var (
 		logBuf bytes.Buffer
+		resp   interface{}
 
-		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
 	)
 	if service == nil {
 		service = goatest.Service(&logBuf, respSetter)
@@ -47,17 +48,39 @@ func GetLogResultsBadRequest(t goatest.TInterface, ctx context.Context, service
 
 	// Setup request context
 	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
 	u := &url.URL{
-		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
 	}
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
 		panic("invalid test " + err.Error()) // bug
 	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
 	prms := url.Values{}
 	prms["date"] = []string{fmt.Sprintf("%v", date)}
 	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
 	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -68,8 +91,7 @@ func GetLogResultsBadRequest(t goatest.TInterface, ctx context.Context, service
 		if !ok {
 			panic("invalid test data " + _err.Error()) // bug
 		}
-		t.Errorf("unexpected parameter validation error: %+v", e)
-		return nil
+		return nil, e
 	}
 
 	// Perform action
@@ -82,21 +104,30 @@ func GetLogResultsBadRequest(t goatest.TInterface, ctx context.Context, service
 	if rw.Code != 400 {
 		t.Errorf("invalid response status code: got %+v, expected 400", rw.Code)
 	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
 
 	// Return results
-	return rw
+	return rw, mt
 }
 
-// GetLogResultsOK runs the method GetLog of the given controller with the given parameters.
-// It returns the response writer so it's possible to inspect the response headers.
+// GetLogResultsForbidden runs the method GetLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func GetLogResultsOK(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) http.ResponseWriter {
+func GetLogResultsForbidden(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
 	// Setup service
 	var (
 		logBuf bytes.Buffer
+		resp   interface{}
 
-		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
 	)
 	if service == nil {
 		service = goatest.Service(&logBuf, respSetter)
@@ -110,17 +141,39 @@ func GetLogResultsOK(t goatest.TInterface, ctx context.Context, service *goa.Ser
 
 	// Setup request context
 	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
 	u := &url.URL{
-		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
 	}
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
 		panic("invalid test " + err.Error()) // bug
 	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
 	prms := url.Values{}
 	prms["date"] = []string{fmt.Sprintf("%v", date)}
 	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
 	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -131,8 +184,7 @@ func GetLogResultsOK(t goatest.TInterface, ctx context.Context, service *goa.Ser
 		if !ok {
 			panic("invalid test data " + _err.Error()) // bug
 		}
-		t.Errorf("unexpected parameter validation error: %+v", e)
-		return nil
+		return nil, e
 	}
 
 	// Perform action
@@ -142,19 +194,27 @@ func GetLogResultsOK(t goatest.TInterface, ctx context.Context, service *goa.Ser
 	if _err != nil {
 		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
 	}
-	if rw.Code != 200 {
-		t.Errorf("invalid response status code: got %+v, expected 200", rw.Code)
+	if rw.Code != 403 {
+		t.Errorf("invalid response status code: got %+v, expected 403", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
 	}
 
 	// Return results
-	return rw
+	return rw, mt
 }
 
-// GetReportResultsBadRequest runs the method GetReport of the given controller with the given parameters.
+// GetLogResultsFound runs the method GetLog of the given controller with the given parameters.
 // It returns the response writer so it's possible to inspect the response headers.
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func GetReportResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) http.ResponseWriter {
+func GetLogResultsFound(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) http.ResponseWriter {
 	// Setup service
 	var (
 		logBuf bytes.Buffer
@@ -173,22 +233,44 @@ func GetReportResultsBadRequest(t goatest.TInterface, ctx context.Context, servi
 
 	// Setup request context
 	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
 	u := &url.URL{
-		Path: fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
 	}
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
 		panic("invalid test " + err.Error()) // bug
 	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
 	prms := url.Values{}
 	prms["date"] = []string{fmt.Sprintf("%v", date)}
 	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
 	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
-	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
 	if _err != nil {
 		e, ok := _err.(goa.ServiceError)
 		if !ok {
@@ -199,30 +281,31 @@ func GetReportResultsBadRequest(t goatest.TInterface, ctx context.Context, servi
 	}
 
 	// Perform action
-	_err = ctrl.GetReport(getReportCtx)
+	_err = ctrl.GetLog(getLogCtx)
 
 	// Validate response
 	if _err != nil {
 		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
 	}
-	if rw.Code != 400 {
-		t.Errorf("invalid response status code: got %+v, expected 400", rw.Code)
+	if rw.Code != 302 {
+		t.Errorf("invalid response status code: got %+v, expected 302", rw.Code)
 	}
 
 	// Return results
 	return rw
 }
 
-// GetReportResultsOK runs the method GetReport of the given controller with the given parameters.
-// It returns the response writer so it's possible to inspect the response headers.
+// GetLogResultsInternalServerError runs the method GetLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func GetReportResultsOK(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) http.ResponseWriter {
+func GetLogResultsInternalServerError(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
 	// Setup service
 	var (
 		logBuf bytes.Buffer
+		resp   interface{}
 
-		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
 	)
 	if service == nil {
 		service = goatest.Service(&logBuf, respSetter)
@@ -236,56 +319,86 @@ func GetReportResultsOK(t goatest.TInterface, ctx context.Context, service *goa.
 
 	// Setup request context
 	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
 	u := &url.URL{
-		Path: fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
 	}
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
 		panic("invalid test " + err.Error()) // bug
 	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
 	prms := url.Values{}
 	prms["date"] = []string{fmt.Sprintf("%v", date)}
 	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
 	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
-	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
 	if _err != nil {
 		e, ok := _err.(goa.ServiceError)
 		if !ok {
 			panic("invalid test data " + _err.Error()) // bug
 		}
-		t.Errorf("unexpected parameter validation error: %+v", e)
-		return nil
+		return nil, e
 	}
 
 	// Perform action
-	_err = ctrl.GetReport(getReportCtx)
+	_err = ctrl.GetLog(getLogCtx)
 
 	// Validate response
 	if _err != nil {
 		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
 	}
-	if rw.Code != 200 {
-		t.Errorf("invalid response status code: got %+v, expected 200", rw.Code)
+	if rw.Code != 500 {
+		t.Errorf("invalid response status code: got %+v, expected 500", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
 	}
 
 	// Return results
-	return rw
+	return rw, mt
 }
 
-// RawResultsBadRequest runs the method Raw of the given controller with the given parameters and payload.
-// It returns the response writer so it's possible to inspect the response headers.
+// GetLogResultsNotFound runs the method GetLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func RawResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) http.ResponseWriter {
+func GetLogResultsNotFound(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
 	// Setup service
 	var (
 		logBuf bytes.Buffer
+		resp   interface{}
 
-		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
 	)
 	if service == nil {
 		service = goatest.Service(&logBuf, respSetter)
@@ -299,49 +412,80 @@ func RawResultsBadRequest(t goatest.TInterface, ctx context.Context, service *go
 
 	// Setup request context
 	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
 	u := &url.URL{
-		Path: fmt.Sprintf("/v1/raw"),
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
 	}
-	req, err := http.NewRequest("POST", u.String(), nil)
+	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
 		panic("invalid test " + err.Error()) // bug
 	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
 	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
-	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
 	if _err != nil {
 		e, ok := _err.(goa.ServiceError)
 		if !ok {
 			panic("invalid test data " + _err.Error()) // bug
 		}
-		t.Errorf("unexpected parameter validation error: %+v", e)
-		return nil
+		return nil, e
 	}
-	rawCtx.Payload = payload
 
 	// Perform action
-	_err = ctrl.Raw(rawCtx)
+	_err = ctrl.GetLog(getLogCtx)
 
 	// Validate response
 	if _err != nil {
 		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
 	}
-	if rw.Code != 400 {
-		t.Errorf("invalid response status code: got %+v, expected 400", rw.Code)
+	if rw.Code != 404 {
+		t.Errorf("invalid response status code: got %+v, expected 404", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
 	}
 
 	// Return results
-	return rw
+	return rw, mt
 }
 
-// RawResultsCreated runs the method Raw of the given controller with the given parameters and payload.
+// GetLogResultsNotModified runs the method GetLog of the given controller with the given parameters.
 // It returns the response writer so it's possible to inspect the response headers.
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func RawResultsCreated(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) http.ResponseWriter {
+func GetLogResultsNotModified(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) http.ResponseWriter {
 	// Setup service
 	var (
 		logBuf bytes.Buffer
@@ -360,19 +504,44 @@ func RawResultsCreated(t goatest.TInterface, ctx context.Context, service *goa.S
 
 	// Setup request context
 	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
 	u := &url.URL{
-		Path: fmt.Sprintf("/v1/raw"),
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
 	}
-	req, err := http.NewRequest("POST", u.String(), nil)
+	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
 		panic("invalid test " + err.Error()) // bug
 	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
 	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
-	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
 	if _err != nil {
 		e, ok := _err.(goa.ServiceError)
 		if !ok {
@@ -381,28 +550,27 @@ func RawResultsCreated(t goatest.TInterface, ctx context.Context, service *goa.S
 		t.Errorf("unexpected parameter validation error: %+v", e)
 		return nil
 	}
-	rawCtx.Payload = payload
 
 	// Perform action
-	_err = ctrl.Raw(rawCtx)
+	_err = ctrl.GetLog(getLogCtx)
 
 	// Validate response
 	if _err != nil {
 		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
 	}
-	if rw.Code != 201 {
-		t.Errorf("invalid response status code: got %+v, expected 201", rw.Code)
+	if rw.Code != 304 {
+		t.Errorf("invalid response status code: got %+v, expected 304", rw.Code)
 	}
 
 	// Return results
 	return rw
 }
 
-// ReportResultsBadRequest runs the method Report of the given controller with the given parameters and payload.
+// GetLogResultsOK runs the method GetLog of the given controller with the given parameters.
 // It returns the response writer so it's possible to inspect the response headers.
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func ReportResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) http.ResponseWriter {
+func GetLogResultsOK(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) http.ResponseWriter {
 	// Setup service
 	var (
 		logBuf bytes.Buffer
@@ -419,62 +587,75 @@ func ReportResultsBadRequest(t goatest.TInterface, ctx context.Context, service
 		service.Encoder.Register(newEncoder, "*/*")
 	}
 
-	// Validate payload
-	err := payload.Validate()
-	if err != nil {
-		e, ok := err.(goa.ServiceError)
-		if !ok {
-			panic(err) // bug
-		}
-		t.Errorf("unexpected payload validation error: %+v", e)
-		return nil
-	}
-
 	// Setup request context
 	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
 	u := &url.URL{
-		Path: fmt.Sprintf("/v1/report"),
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
 	}
-	req, _err := http.NewRequest("POST", u.String(), nil)
-	if _err != nil {
-		panic("invalid test " + _err.Error()) // bug
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
 	}
 	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
-	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
-	if __err != nil {
-		_e, _ok := __err.(goa.ServiceError)
-		if !_ok {
-			panic("invalid test data " + __err.Error()) // bug
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
 		}
-		t.Errorf("unexpected parameter validation error: %+v", _e)
+		t.Errorf("unexpected parameter validation error: %+v", e)
 		return nil
 	}
-	reportCtx.Payload = payload
 
 	// Perform action
-	__err = ctrl.Report(reportCtx)
+	_err = ctrl.GetLog(getLogCtx)
 
 	// Validate response
-	if __err != nil {
-		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
 	}
-	if rw.Code != 400 {
-		t.Errorf("invalid response status code: got %+v, expected 400", rw.Code)
+	if rw.Code != 200 {
+		t.Errorf("invalid response status code: got %+v, expected 200", rw.Code)
 	}
 
 	// Return results
 	return rw
 }
 
-// ReportResultsCreated runs the method Report of the given controller with the given parameters and payload.
+// GetLogResultsPartialContent runs the method GetLog of the given controller with the given parameters.
 // It returns the response writer so it's possible to inspect the response headers.
 // If ctx is nil then context.Background() is used.
 // If service is nil then a default service is created.
-func ReportResultsCreated(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) http.ResponseWriter {
+func GetLogResultsPartialContent(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) http.ResponseWriter {
 	// Setup service
 	var (
 		logBuf bytes.Buffer
@@ -491,53 +672,2910 @@ func ReportResultsCreated(t goatest.TInterface, ctx context.Context, service *go
 		service.Encoder.Register(newEncoder, "*/*")
 	}
 
-	// Validate payload
-	err := payload.Validate()
-	if err != nil {
-		e, ok := err.(goa.ServiceError)
-		if !ok {
-			panic(err) // bug
-		}
-		t.Errorf("unexpected payload validation error: %+v", e)
-		return nil
-	}
-
 	// Setup request context
 	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
 	u := &url.URL{
-		Path: fmt.Sprintf("/v1/report"),
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
 	}
-	req, _err := http.NewRequest("POST", u.String(), nil)
-	if _err != nil {
-		panic("invalid test " + _err.Error()) // bug
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
 	}
 	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
-	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
-	if __err != nil {
-		_e, _ok := __err.(goa.ServiceError)
-		if !_ok {
-			panic("invalid test data " + __err.Error()) // bug
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
 		}
-		t.Errorf("unexpected parameter validation error: %+v", _e)
+		t.Errorf("unexpected parameter validation error: %+v", e)
 		return nil
 	}
-	reportCtx.Payload = payload
 
 	// Perform action
-	__err = ctrl.Report(reportCtx)
+	_err = ctrl.GetLog(getLogCtx)
 
 	// Validate response
-	if __err != nil {
-		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
 	}
-	if rw.Code != 201 {
-		t.Errorf("invalid response status code: got %+v, expected 201", rw.Code)
+	if rw.Code != 206 {
+		t.Errorf("invalid response status code: got %+v, expected 206", rw.Code)
 	}
 
 	// Return results
 	return rw
 }
+
+// GetLogResultsRequestedRangeNotSatisfiable runs the method GetLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetLogResultsRequestedRangeNotSatisfiable(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetLog(getLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 416 {
+		t.Errorf("invalid response status code: got %+v, expected 416", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetLogResultsServiceUnavailable runs the method GetLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetLogResultsServiceUnavailable(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetLog(getLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 503 {
+		t.Errorf("invalid response status code: got %+v, expected 503", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetLogResultsUnauthorized runs the method GetLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetLogResultsUnauthorized(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getLogCtx, _err := app.NewGetLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetLog(getLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 401 {
+		t.Errorf("invalid response status code: got %+v, expected 401", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetReportResultsBadRequest runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 400 {
+		t.Errorf("invalid response status code: got %+v, expected 400", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetReportResultsForbidden runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsForbidden(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 403 {
+		t.Errorf("invalid response status code: got %+v, expected 403", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetReportResultsFound runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsFound(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", e)
+		return nil
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 302 {
+		t.Errorf("invalid response status code: got %+v, expected 302", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// GetReportResultsInternalServerError runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsInternalServerError(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 500 {
+		t.Errorf("invalid response status code: got %+v, expected 500", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetReportResultsNotFound runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsNotFound(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 404 {
+		t.Errorf("invalid response status code: got %+v, expected 404", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetReportResultsNotModified runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsNotModified(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", e)
+		return nil
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 304 {
+		t.Errorf("invalid response status code: got %+v, expected 304", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// GetReportResultsOK runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsOK(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", e)
+		return nil
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 200 {
+		t.Errorf("invalid response status code: got %+v, expected 200", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// GetReportResultsPartialContent runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsPartialContent(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", e)
+		return nil
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 206 {
+		t.Errorf("invalid response status code: got %+v, expected 206", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// GetReportResultsRequestedRangeNotSatisfiable runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsRequestedRangeNotSatisfiable(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 416 {
+		t.Errorf("invalid response status code: got %+v, expected 416", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetReportResultsServiceUnavailable runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsServiceUnavailable(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 503 {
+		t.Errorf("invalid response status code: got %+v, expected 503", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// GetReportResultsUnauthorized runs the method GetReport of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func GetReportResultsUnauthorized(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	query := url.Values{}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		query["redirect"] = sliceVal
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/v1/reports/%v/%v/%v", date, scan, check),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	if ifModifiedSince != nil {
+		sliceVal := []string{*ifModifiedSince}
+		req.Header["If-Modified-Since"] = sliceVal
+	}
+	if ifNoneMatch != nil {
+		sliceVal := []string{*ifNoneMatch}
+		req.Header["If-None-Match"] = sliceVal
+	}
+	if range_ != nil {
+		sliceVal := []string{*range_}
+		req.Header["Range"] = sliceVal
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if redirect != nil {
+		sliceVal := []string{fmt.Sprintf("%v", *redirect)}
+		prms["redirect"] = sliceVal
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	getReportCtx, _err := app.NewGetReportResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.GetReport(getReportCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 401 {
+		t.Errorf("invalid response status code: got %+v, expected 401", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// PutLogResultsBadRequest runs the method PutLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func PutLogResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+	}
+	req, err := http.NewRequest("PUT", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	putLogCtx, _err := app.NewPutLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.PutLog(putLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 400 {
+		t.Errorf("invalid response status code: got %+v, expected 400", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// PutLogResultsCreated runs the method PutLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func PutLogResultsCreated(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+	}
+	req, err := http.NewRequest("PUT", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	putLogCtx, _err := app.NewPutLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", e)
+		return nil
+	}
+
+	// Perform action
+	_err = ctrl.PutLog(putLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 201 {
+		t.Errorf("invalid response status code: got %+v, expected 201", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// PutLogResultsForbidden runs the method PutLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func PutLogResultsForbidden(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+	}
+	req, err := http.NewRequest("PUT", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	putLogCtx, _err := app.NewPutLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.PutLog(putLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 403 {
+		t.Errorf("invalid response status code: got %+v, expected 403", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// PutLogResultsInternalServerError runs the method PutLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func PutLogResultsInternalServerError(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+	}
+	req, err := http.NewRequest("PUT", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	putLogCtx, _err := app.NewPutLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.PutLog(putLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 500 {
+		t.Errorf("invalid response status code: got %+v, expected 500", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// PutLogResultsRequestEntityTooLarge runs the method PutLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func PutLogResultsRequestEntityTooLarge(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+	}
+	req, err := http.NewRequest("PUT", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	putLogCtx, _err := app.NewPutLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.PutLog(putLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 413 {
+		t.Errorf("invalid response status code: got %+v, expected 413", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// PutLogResultsServiceUnavailable runs the method PutLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func PutLogResultsServiceUnavailable(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+	}
+	req, err := http.NewRequest("PUT", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	putLogCtx, _err := app.NewPutLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.PutLog(putLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 503 {
+		t.Errorf("invalid response status code: got %+v, expected 503", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// PutLogResultsUnauthorized runs the method PutLog of the given controller with the given parameters.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func PutLogResultsUnauthorized(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date string, scan string, check string) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/logs/%v/%v/%v", date, scan, check),
+	}
+	req, err := http.NewRequest("PUT", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	prms["date"] = []string{fmt.Sprintf("%v", date)}
+	prms["scan"] = []string{fmt.Sprintf("%v", scan)}
+	prms["check"] = []string{fmt.Sprintf("%v", check)}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	putLogCtx, _err := app.NewPutLogResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+
+	// Perform action
+	_err = ctrl.PutLog(putLogCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 401 {
+		t.Errorf("invalid response status code: got %+v, expected 401", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// RawResultsAccepted runs the method Raw of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func RawResultsAccepted(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/raw"),
+	}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", e)
+		return nil
+	}
+	rawCtx.Payload = payload
+
+	// Perform action
+	_err = ctrl.Raw(rawCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 202 {
+		t.Errorf("invalid response status code: got %+v, expected 202", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// RawResultsBadRequest runs the method Raw of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func RawResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/raw"),
+	}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+	rawCtx.Payload = payload
+
+	// Perform action
+	_err = ctrl.Raw(rawCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 400 {
+		t.Errorf("invalid response status code: got %+v, expected 400", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// RawResultsCreated runs the method Raw of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func RawResultsCreated(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/raw"),
+	}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", e)
+		return nil
+	}
+	rawCtx.Payload = payload
+
+	// Perform action
+	_err = ctrl.Raw(rawCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 201 {
+		t.Errorf("invalid response status code: got %+v, expected 201", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// RawResultsForbidden runs the method Raw of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func RawResultsForbidden(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/raw"),
+	}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+	rawCtx.Payload = payload
+
+	// Perform action
+	_err = ctrl.Raw(rawCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 403 {
+		t.Errorf("invalid response status code: got %+v, expected 403", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// RawResultsInternalServerError runs the method Raw of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func RawResultsInternalServerError(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/raw"),
+	}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+	rawCtx.Payload = payload
+
+	// Perform action
+	_err = ctrl.Raw(rawCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 500 {
+		t.Errorf("invalid response status code: got %+v, expected 500", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// RawResultsServiceUnavailable runs the method Raw of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func RawResultsServiceUnavailable(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/raw"),
+	}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+	rawCtx.Payload = payload
+
+	// Perform action
+	_err = ctrl.Raw(rawCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 503 {
+		t.Errorf("invalid response status code: got %+v, expected 503", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// RawResultsUnauthorized runs the method Raw of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func RawResultsUnauthorized(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.RawPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/raw"),
+	}
+	req, err := http.NewRequest("POST", u.String(), nil)
+	if err != nil {
+		panic("invalid test " + err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	rawCtx, _err := app.NewRawResultsContext(goaCtx, req, service)
+	if _err != nil {
+		e, ok := _err.(goa.ServiceError)
+		if !ok {
+			panic("invalid test data " + _err.Error()) // bug
+		}
+		return nil, e
+	}
+	rawCtx.Payload = payload
+
+	// Perform action
+	_err = ctrl.Raw(rawCtx)
+
+	// Validate response
+	if _err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", _err, logBuf.String())
+	}
+	if rw.Code != 401 {
+		t.Errorf("invalid response status code: got %+v, expected 401", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var _ok bool
+		mt, _ok = resp.(error)
+		if !_ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// ReportResultsAccepted runs the method Report of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func ReportResultsAccepted(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Validate payload
+	err := payload.Validate()
+	if err != nil {
+		e, ok := err.(goa.ServiceError)
+		if !ok {
+			panic(err) // bug
+		}
+		t.Errorf("unexpected payload validation error: %+v", e)
+		return nil
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/report"),
+	}
+	req, _err := http.NewRequest("POST", u.String(), nil)
+	if _err != nil {
+		panic("invalid test " + _err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
+	if __err != nil {
+		_e, _ok := __err.(goa.ServiceError)
+		if !_ok {
+			panic("invalid test data " + __err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", _e)
+		return nil
+	}
+	reportCtx.Payload = payload
+
+	// Perform action
+	__err = ctrl.Report(reportCtx)
+
+	// Validate response
+	if __err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	}
+	if rw.Code != 202 {
+		t.Errorf("invalid response status code: got %+v, expected 202", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// ReportResultsBadRequest runs the method Report of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func ReportResultsBadRequest(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Validate payload
+	err := payload.Validate()
+	if err != nil {
+		e, ok := err.(goa.ServiceError)
+		if !ok {
+			panic(err) // bug
+		}
+		return nil, e
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/report"),
+	}
+	req, _err := http.NewRequest("POST", u.String(), nil)
+	if _err != nil {
+		panic("invalid test " + _err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
+	if __err != nil {
+		_e, _ok := __err.(goa.ServiceError)
+		if !_ok {
+			panic("invalid test data " + __err.Error()) // bug
+		}
+		return nil, _e
+	}
+	reportCtx.Payload = payload
+
+	// Perform action
+	__err = ctrl.Report(reportCtx)
+
+	// Validate response
+	if __err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	}
+	if rw.Code != 400 {
+		t.Errorf("invalid response status code: got %+v, expected 400", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var __ok bool
+		mt, __ok = resp.(error)
+		if !__ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// ReportResultsConflict runs the method Report of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func ReportResultsConflict(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Validate payload
+	err := payload.Validate()
+	if err != nil {
+		e, ok := err.(goa.ServiceError)
+		if !ok {
+			panic(err) // bug
+		}
+		return nil, e
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/report"),
+	}
+	req, _err := http.NewRequest("POST", u.String(), nil)
+	if _err != nil {
+		panic("invalid test " + _err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
+	if __err != nil {
+		_e, _ok := __err.(goa.ServiceError)
+		if !_ok {
+			panic("invalid test data " + __err.Error()) // bug
+		}
+		return nil, _e
+	}
+	reportCtx.Payload = payload
+
+	// Perform action
+	__err = ctrl.Report(reportCtx)
+
+	// Validate response
+	if __err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	}
+	if rw.Code != 409 {
+		t.Errorf("invalid response status code: got %+v, expected 409", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var __ok bool
+		mt, __ok = resp.(error)
+		if !__ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// ReportResultsCreated runs the method Report of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func ReportResultsCreated(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) http.ResponseWriter {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) {}
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Validate payload
+	err := payload.Validate()
+	if err != nil {
+		e, ok := err.(goa.ServiceError)
+		if !ok {
+			panic(err) // bug
+		}
+		t.Errorf("unexpected payload validation error: %+v", e)
+		return nil
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/report"),
+	}
+	req, _err := http.NewRequest("POST", u.String(), nil)
+	if _err != nil {
+		panic("invalid test " + _err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
+	if __err != nil {
+		_e, _ok := __err.(goa.ServiceError)
+		if !_ok {
+			panic("invalid test data " + __err.Error()) // bug
+		}
+		t.Errorf("unexpected parameter validation error: %+v", _e)
+		return nil
+	}
+	reportCtx.Payload = payload
+
+	// Perform action
+	__err = ctrl.Report(reportCtx)
+
+	// Validate response
+	if __err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	}
+	if rw.Code != 201 {
+		t.Errorf("invalid response status code: got %+v, expected 201", rw.Code)
+	}
+
+	// Return results
+	return rw
+}
+
+// ReportResultsForbidden runs the method Report of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func ReportResultsForbidden(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Validate payload
+	err := payload.Validate()
+	if err != nil {
+		e, ok := err.(goa.ServiceError)
+		if !ok {
+			panic(err) // bug
+		}
+		return nil, e
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/report"),
+	}
+	req, _err := http.NewRequest("POST", u.String(), nil)
+	if _err != nil {
+		panic("invalid test " + _err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
+	if __err != nil {
+		_e, _ok := __err.(goa.ServiceError)
+		if !_ok {
+			panic("invalid test data " + __err.Error()) // bug
+		}
+		return nil, _e
+	}
+	reportCtx.Payload = payload
+
+	// Perform action
+	__err = ctrl.Report(reportCtx)
+
+	// Validate response
+	if __err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	}
+	if rw.Code != 403 {
+		t.Errorf("invalid response status code: got %+v, expected 403", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var __ok bool
+		mt, __ok = resp.(error)
+		if !__ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// ReportResultsInternalServerError runs the method Report of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func ReportResultsInternalServerError(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Validate payload
+	err := payload.Validate()
+	if err != nil {
+		e, ok := err.(goa.ServiceError)
+		if !ok {
+			panic(err) // bug
+		}
+		return nil, e
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/report"),
+	}
+	req, _err := http.NewRequest("POST", u.String(), nil)
+	if _err != nil {
+		panic("invalid test " + _err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
+	if __err != nil {
+		_e, _ok := __err.(goa.ServiceError)
+		if !_ok {
+			panic("invalid test data " + __err.Error()) // bug
+		}
+		return nil, _e
+	}
+	reportCtx.Payload = payload
+
+	// Perform action
+	__err = ctrl.Report(reportCtx)
+
+	// Validate response
+	if __err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	}
+	if rw.Code != 500 {
+		t.Errorf("invalid response status code: got %+v, expected 500", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var __ok bool
+		mt, __ok = resp.(error)
+		if !__ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// ReportResultsServiceUnavailable runs the method Report of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func ReportResultsServiceUnavailable(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Validate payload
+	err := payload.Validate()
+	if err != nil {
+		e, ok := err.(goa.ServiceError)
+		if !ok {
+			panic(err) // bug
+		}
+		return nil, e
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/report"),
+	}
+	req, _err := http.NewRequest("POST", u.String(), nil)
+	if _err != nil {
+		panic("invalid test " + _err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
+	if __err != nil {
+		_e, _ok := __err.(goa.ServiceError)
+		if !_ok {
+			panic("invalid test data " + __err.Error()) // bug
+		}
+		return nil, _e
+	}
+	reportCtx.Payload = payload
+
+	// Perform action
+	__err = ctrl.Report(reportCtx)
+
+	// Validate response
+	if __err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	}
+	if rw.Code != 503 {
+		t.Errorf("invalid response status code: got %+v, expected 503", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var __ok bool
+		mt, __ok = resp.(error)
+		if !__ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
+
+// ReportResultsUnauthorized runs the method Report of the given controller with the given parameters and payload.
+// It returns the response writer so it's possible to inspect the response headers and the media type struct written to the response.
+// If ctx is nil then context.Background() is used.
+// If service is nil then a default service is created.
+func ReportResultsUnauthorized(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, payload *app.ReportPayload) (http.ResponseWriter, error) {
+	// Setup service
+	var (
+		logBuf bytes.Buffer
+		resp   interface{}
+
+		respSetter goatest.ResponseSetterFunc = func(r interface{}) { resp = r }
+	)
+	if service == nil {
+		service = goatest.Service(&logBuf, respSetter)
+	} else {
+		logger := log.New(&logBuf, "", log.Ltime)
+		service.WithLogger(goa.NewLogger(logger))
+		newEncoder := func(io.Writer) goa.Encoder { return respSetter }
+		service.Encoder = goa.NewHTTPEncoder() // Make sure the code ends up using this decoder
+		service.Encoder.Register(newEncoder, "*/*")
+	}
+
+	// Validate payload
+	err := payload.Validate()
+	if err != nil {
+		e, ok := err.(goa.ServiceError)
+		if !ok {
+			panic(err) // bug
+		}
+		return nil, e
+	}
+
+	// Setup request context
+	rw := httptest.NewRecorder()
+	u := &url.URL{
+		Path: fmt.Sprintf("/v1/report"),
+	}
+	req, _err := http.NewRequest("POST", u.String(), nil)
+	if _err != nil {
+		panic("invalid test " + _err.Error()) // bug
+	}
+	prms := url.Values{}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	goaCtx := goa.NewContext(goa.WithAction(ctx, "ResultsTest"), rw, req, prms)
+	reportCtx, __err := app.NewReportResultsContext(goaCtx, req, service)
+	if __err != nil {
+		_e, _ok := __err.(goa.ServiceError)
+		if !_ok {
+			panic("invalid test data " + __err.Error()) // bug
+		}
+		return nil, _e
+	}
+	reportCtx.Payload = payload
+
+	// Perform action
+	__err = ctrl.Report(reportCtx)
+
+	// Validate response
+	if __err != nil {
+		t.Fatalf("controller returned %+v, logs:\n%s", __err, logBuf.String())
+	}
+	if rw.Code != 401 {
+		t.Errorf("invalid response status code: got %+v, expected 401", rw.Code)
+	}
+	var mt error
+	if resp != nil {
+		var __ok bool
+		mt, __ok = resp.(error)
+		if !__ok {
+			t.Fatalf("invalid response media: got variable of type %T, value %+v, expected instance of error", resp, resp)
+		}
+	}
+
+	// Return results
+	return rw, mt
+}
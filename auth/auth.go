@@ -0,0 +1,220 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+// Package auth authenticates the requests to the results endpoints with
+// static tokens, HMAC signed requests or JWTs, checks the scopes
+// required by the actions and authorizes the principals to access the
+// results of the scans.
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Scopes of the results endpoints.
+const (
+	ScopeWrite = "results:write"
+	ScopeRead  = "results:read"
+)
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request
+	// does not carry credentials it can verify.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned by an Authenticator when the
+	// credentials of the request are not valid.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Config represents the authentication configuration. Authentication
+// is disabled if no file is configured.
+type Config struct {
+	// TokensFile holds the static bearer tokens, one per line with the
+	// format "<token> <subject> <scope>[,<scope>...]".
+	TokensFile string
+	// HMACKeysFile holds the keys agents sign the requests with, one
+	// per line with the format
+	// "<key id> <base64 secret> <subject> <scope>[,<scope>...]".
+	HMACKeysFile string
+	// MaxClockSkew is the maximum difference between the timestamp of
+	// a signed request and the time it is received,
+	// DefaultMaxClockSkew if zero.
+	MaxClockSkew time.Duration
+	// JWKSFile holds the JSON Web Key Set JWTs are verified with.
+	JWKSFile string
+	// Issuer and Audience are the iss and aud claims JWTs must have,
+	// if not empty.
+	Issuer   string
+	Audience string
+
+	// PolicyFile holds the policy that authorizes the principals to
+	// access the results of the scans, see Policy. Every authenticated
+	// principal can access all the results if it is empty.
+	PolicyFile string
+	// PolicyReloadInterval is the interval the policy file is checked
+	// for changes at, DefaultPolicyReloadInterval if zero.
+	PolicyReloadInterval time.Duration
+	// AuditFile is the file the denied accesses are logged to, as JSON
+	// lines. They are logged to the service log if it is empty.
+	AuditFile string
+}
+
+// Enabled tells whether any authentication method is configured.
+func (c Config) Enabled() bool {
+	return c.TokensFile != "" || c.HMACKeysFile != "" || c.JWKSFile != ""
+}
+
+// Principal is the identity a request is authenticated as.
+type Principal struct {
+	// Subject identifies the owner of the credentials.
+	Subject string
+	// Method is the authentication method, "token", "hmac" or "jwt".
+	Method string
+	// Scopes are the scopes granted to the principal.
+	Scopes []string
+}
+
+// HasScope tells whether the principal was granted the given scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx holding p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// ContextPrincipal returns the principal the request of ctx is
+// authenticated as, or nil if it is not authenticated.
+func ContextPrincipal(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Authenticator verifies the credentials of a request.
+type Authenticator interface {
+	// Authenticate returns the principal the request is authenticated
+	// as. It fails with ErrNoCredentials if the request does not carry
+	// credentials the Authenticator can verify, and with
+	// ErrInvalidCredentials if they are not valid.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain is an Authenticator that tries its Authenticators in order
+// until one of them finds credentials in the request.
+type Chain []Authenticator
+
+// Authenticate authenticates the request with the first Authenticator
+// of the chain that finds credentials in it.
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c {
+		p, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// HMAC returns the HMACAuthenticator of the chain, or nil if it has
+// none.
+func (c Chain) HMAC() *HMACAuthenticator {
+	for _, a := range c {
+		if h, ok := a.(*HMACAuthenticator); ok {
+			return h
+		}
+	}
+	return nil
+}
+
+// New returns a Chain with an Authenticator for every method
+// configured in c.
+func New(c Config) (Chain, error) {
+	var chain Chain
+	if c.TokensFile != "" {
+		tokens, err := LoadTokens(c.TokensFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, NewTokenAuthenticator(tokens))
+	}
+	if c.JWKSFile != "" {
+		keys, err := LoadJWKS(c.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, NewJWTAuthenticator(keys, c.Issuer, c.Audience))
+	}
+	if c.HMACKeysFile != "" {
+		keys, err := LoadHMACKeys(c.HMACKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, NewHMACAuthenticator(keys, c.MaxClockSkew))
+	}
+	return chain, nil
+}
+
+// bearerToken returns the token of a request with a bearer token
+// Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) < len("Bearer ") || !strings.EqualFold(h[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(h[len("Bearer "):]), true
+}
+
+// isJWT tells whether a bearer token has the shape of a JWT.
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// parseScopes parses a comma separated list of scopes.
+func parseScopes(s string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// readLines calls f with the fields of every line of the file at path,
+// skipping empty lines and comments starting with #.
+func readLines(path string, f func(fields []string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := f(strings.Fields(line)); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	return sc.Err()
+}
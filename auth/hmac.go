@@ -0,0 +1,364 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"bytes"
+	"container/heap"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxClockSkew is the maximum difference between the timestamp
+// of a signed request and the time it is received, unless another one
+// is configured.
+const DefaultMaxClockSkew = 5 * time.Minute
+
+// Headers of the HMAC signed requests.
+const (
+	HeaderTimestamp     = "X-Vulcan-Timestamp"
+	HeaderContentSHA256 = "X-Content-SHA256"
+)
+
+// hmacScheme is the scheme of the Authorization header of the HMAC
+// signed requests.
+const hmacScheme = "HMAC-SHA256"
+
+// ErrContentHash is the error of the requests whose body does not match
+// their X-Content-SHA256 header.
+var ErrContentHash = errors.New("body does not match " + HeaderContentSHA256)
+
+// HMACKey is a key agents sign the requests with.
+type HMACKey struct {
+	Secret    []byte
+	Principal Principal
+}
+
+// HMACAuthenticator authenticates the requests signed with HMAC-SHA256.
+// The signature covers the method, the path and query, the timestamp
+// and the hash of the body of the request, joined by new lines. The
+// hash is required, and VerifyContentHash checks the body against it. A
+// signature is only accepted once, and only while its timestamp is
+// within the maximum clock skew, so requests can not be replayed.
+type HMACAuthenticator struct {
+	keys    map[string]HMACKey
+	maxSkew time.Duration
+	now     func() time.Time
+
+	mu sync.Mutex
+	// seen holds the signatures accepted, until they expire, and
+	// expiries orders them by when they do.
+	seen     map[string]struct{}
+	expiries expiryHeap
+}
+
+// NewHMACAuthenticator returns an HMACAuthenticator that accepts the
+// requests signed with the given keys, indexed by their ID. If maxSkew
+// is not positive DefaultMaxClockSkew is used.
+func NewHMACAuthenticator(keys map[string]HMACKey, maxSkew time.Duration) *HMACAuthenticator {
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxClockSkew
+	}
+	return &HMACAuthenticator{
+		keys:    keys,
+		maxSkew: maxSkew,
+		now:     time.Now,
+		seen:    map[string]struct{}{},
+	}
+}
+
+// Authenticate authenticates a request signed with HMAC-SHA256.
+func (a *HMACAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key, sig, signedAt, err := a.verify(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The hex encoding of a signature is not unique, so the decoded
+	// signature identifies it.
+	if !a.accept(string(sig), signedAt.Add(a.maxSkew), a.now()) {
+		return nil, fmt.Errorf("%w: replayed request", ErrInvalidCredentials)
+	}
+
+	p := key.Principal
+	p.Method = "hmac"
+	return &p, nil
+}
+
+// verify checks the signature of a request signed with HMAC-SHA256,
+// which only depends on its headers, and returns the key and the time it
+// was signed with, and the signature. It does not check whether the
+// signature was already accepted.
+func (a *HMACAuthenticator) verify(r *http.Request) (key HMACKey, sig []byte, signedAt time.Time, err error) {
+	keyID, hexSig, ok := parseHMACAuthorization(r.Header.Get("Authorization"))
+	if !ok {
+		return HMACKey{}, nil, time.Time{}, ErrNoCredentials
+	}
+	key, ok = a.keys[keyID]
+	if !ok {
+		return HMACKey{}, nil, time.Time{}, fmt.Errorf("%w: unknown key %q", ErrInvalidCredentials, keyID)
+	}
+
+	ts := r.Header.Get(HeaderTimestamp)
+	secs, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return HMACKey{}, nil, time.Time{}, fmt.Errorf("%w: invalid %s", ErrInvalidCredentials, HeaderTimestamp)
+	}
+	signedAt = time.Unix(secs, 0)
+	now := a.now()
+	if signedAt.Before(now.Add(-a.maxSkew)) || signedAt.After(now.Add(a.maxSkew)) {
+		return HMACKey{}, nil, time.Time{}, fmt.Errorf("%w: request signed at %v", ErrInvalidCredentials, signedAt)
+	}
+
+	// The hash of the body is required, so the signature covers it.
+	contentSHA256 := r.Header.Get(HeaderContentSHA256)
+	if sum, err := hex.DecodeString(contentSHA256); err != nil || len(sum) != sha256.Size {
+		return HMACKey{}, nil, time.Time{}, fmt.Errorf("%w: missing or invalid %s", ErrInvalidCredentials, HeaderContentSHA256)
+	}
+
+	sig, err = hex.DecodeString(hexSig)
+	if err != nil {
+		return HMACKey{}, nil, time.Time{}, fmt.Errorf("%w: invalid signature", ErrInvalidCredentials)
+	}
+	want := signature(key.Secret, r.Method, r.URL.RequestURI(), ts, contentSHA256)
+	if !hmac.Equal(sig, want) {
+		return HMACKey{}, nil, time.Time{}, fmt.Errorf("%w: signature mismatch", ErrInvalidCredentials)
+	}
+	return key, sig, signedAt, nil
+}
+
+// accept records a signature valid until expires. It returns false if
+// the signature was already accepted.
+func (a *HMACAuthenticator) accept(sig string, expires, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for len(a.expiries) > 0 && a.expiries[0].expires.Before(now) {
+		e := heap.Pop(&a.expiries).(expiry)
+		delete(a.seen, e.sig)
+	}
+	if _, ok := a.seen[sig]; ok {
+		return false
+	}
+	a.seen[sig] = struct{}{}
+	heap.Push(&a.expiries, expiry{sig: sig, expires: expires})
+	return true
+}
+
+// expiry is the time an accepted signature expires at.
+type expiry struct {
+	sig     string
+	expires time.Time
+}
+
+// expiryHeap is a min-heap of expiries, implementing heap.Interface, so
+// the expired signatures are found without walking all of them.
+type expiryHeap []expiry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *expiryHeap) Push(x interface{}) {
+	*h = append(*h, x.(expiry))
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// parseHMACAuthorization returns the key ID and the signature of an
+// Authorization header with the format
+// "HMAC-SHA256 KeyId=<id>, Signature=<hex signature>".
+func parseHMACAuthorization(h string) (keyID, sig string, ok bool) {
+	if !strings.HasPrefix(h, hmacScheme+" ") {
+		return "", "", false
+	}
+	for _, param := range strings.Split(h[len(hmacScheme)+1:], ",") {
+		k, v, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			continue
+		}
+		switch k {
+		case "KeyId":
+			keyID = v
+		case "Signature":
+			sig = v
+		}
+	}
+	return keyID, sig, keyID != "" && sig != ""
+}
+
+// signature returns the HMAC-SHA256 signature of a request.
+func signature(secret []byte, method, uri, timestamp, contentSHA256 string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	io.WriteString(mac, strings.Join([]string{method, uri, timestamp, contentSHA256}, "\n"))
+	return mac.Sum(nil)
+}
+
+// SignRequest signs a request with the body content using the given
+// key, as agents do.
+func SignRequest(r *http.Request, keyID string, secret, content []byte, t time.Time) {
+	sum := sha256.Sum256(content)
+	contentSHA256 := hex.EncodeToString(sum[:])
+	ts := strconv.FormatInt(t.Unix(), 10)
+	sig := signature(secret, r.Method, r.URL.RequestURI(), ts, contentSHA256)
+
+	r.Header.Set(HeaderTimestamp, ts)
+	r.Header.Set(HeaderContentSHA256, contentSHA256)
+	r.Header.Set("Authorization", fmt.Sprintf("%s KeyId=%s, Signature=%x", hmacScheme, keyID, sig))
+}
+
+// LoadHMACKeys reads the keys in the file at path. Every line has the
+// format "<key id> <base64 secret> <subject> <scope>[,<scope>...]".
+func LoadHMACKeys(path string) (map[string]HMACKey, error) {
+	keys := map[string]HMACKey{}
+	err := readLines(path, func(fields []string) error {
+		if len(fields) != 4 {
+			return fmt.Errorf("expected 4 fields, got %d", len(fields))
+		}
+		secret, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return fmt.Errorf("invalid secret of key %s: %w", fields[0], err)
+		}
+		keys[fields[0]] = HMACKey{
+			Secret:    secret,
+			Principal: Principal{Subject: fields[2], Scopes: parseScopes(fields[3])},
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading HMAC keys: %w", err)
+	}
+	return keys, nil
+}
+
+// maxMemoryBody is the size of the bodies buffered in memory by
+// VerifyContentHash. Larger bodies are buffered in a temporary file.
+const maxMemoryBody = 8 << 20
+
+// VerifyContentHash returns a handler that checks the body of the HMAC
+// signed requests against their X-Content-SHA256 header before calling
+// h, so the signature of a request covers its body. The signature is
+// checked first, so the bodies of the requests not signed with a known
+// key are never read. The body is then buffered, up to the size
+// returned by maxSize for the request, and the requests whose body does
+// not match are rejected without calling h. The requests that are not
+// signed with HMAC-SHA256 are passed to h untouched.
+func (a *HMACAuthenticator) VerifyContentHash(h http.Handler, maxSize func(*http.Request) int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, _, err := a.verify(r)
+		if errors.Is(err, ErrNoCredentials) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", hmacScheme)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// The hash was validated with the signature.
+		want, _ := hex.DecodeString(r.Header.Get(HeaderContentSHA256))
+
+		limit := maxSize(r)
+		if r.ContentLength > limit {
+			http.Error(w, errBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		body, sum, err := bufferBody(r.Body, limit)
+		if errors.Is(err, errBodyTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, "reading body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer body.Close()
+		if !hmac.Equal(sum, want) {
+			w.Header().Set("WWW-Authenticate", hmacScheme)
+			http.Error(w, ErrContentHash.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = body
+		h.ServeHTTP(w, r)
+	})
+}
+
+// errBodyTooLarge is returned by bufferBody when the body exceeds the
+// maximum size.
+var errBodyTooLarge = errors.New("body too large")
+
+// bufferBody reads up to maxSize bytes of r and returns a reader of
+// them and their SHA-256. The content is held in memory up to
+// maxMemoryBody bytes, and in a temporary file removed when the
+// returned reader is closed otherwise.
+func bufferBody(r io.Reader, maxSize int64) (io.ReadCloser, []byte, error) {
+	h := sha256.New()
+	r = io.TeeReader(io.LimitReader(r, maxSize+1), h)
+
+	var mem bytes.Buffer
+	n, err := io.CopyN(&mem, r, maxMemoryBody+1)
+	if err == io.EOF {
+		if n > maxSize {
+			return nil, nil, errBodyTooLarge
+		}
+		return ioutil.NopCloser(&mem), h.Sum(nil), nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := ioutil.TempFile("", "vulcan-results-body-")
+	if err != nil {
+		return nil, nil, err
+	}
+	body := &tempFile{File: f}
+	if _, err := mem.WriteTo(f); err != nil {
+		body.Close()
+		return nil, nil, err
+	}
+	n, err = io.Copy(f, r)
+	if err == nil && n+maxMemoryBody+1 > maxSize {
+		err = errBodyTooLarge
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		body.Close()
+		return nil, nil, err
+	}
+	return body, h.Sum(nil), nil
+}
+
+// tempFile is a temporary file that is removed when it is closed.
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	if rerr := os.Remove(f.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
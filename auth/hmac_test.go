@@ -0,0 +1,330 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testHMACKeys = map[string]HMACKey{
+	"agents": {
+		Secret:    []byte("secret"),
+		Principal: Principal{Subject: "agent-fleet", Scopes: []string{ScopeWrite}},
+	},
+}
+
+func TestHMACAuthenticator(t *testing.T) {
+	now := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	body := []byte(`{"report":"{}"}`)
+
+	testCases := []struct {
+		name    string
+		req     func() *http.Request
+		wantErr error
+	}{
+		{
+			name: "signed request",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+				SignRequest(r, "agents", []byte("secret"), body, now)
+				return r
+			},
+		},
+		{
+			name: "no signature",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+			},
+			wantErr: ErrNoCredentials,
+		},
+		{
+			name: "unknown key",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+				SignRequest(r, "other", []byte("secret"), body, now)
+				return r
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "wrong secret",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+				SignRequest(r, "agents", []byte("other"), body, now)
+				return r
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "tampered path",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+				SignRequest(r, "agents", []byte("secret"), body, now)
+				r.URL.Path = "/v1/raw"
+				return r
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "tampered content hash",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+				SignRequest(r, "agents", []byte("secret"), body, now)
+				r.Header.Set(HeaderContentSHA256, strings.Repeat("0", 64))
+				return r
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "missing content hash",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+				SignRequest(r, "agents", []byte("secret"), body, now)
+				r.Header.Del(HeaderContentSHA256)
+				return r
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "expired timestamp",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+				SignRequest(r, "agents", []byte("secret"), body, now.Add(-10*time.Minute))
+				return r
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := NewHMACAuthenticator(testHMACKeys, 0)
+			a.now = func() time.Time { return now }
+
+			p, err := a.Authenticate(tc.req())
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected error %v, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if p.Subject != "agent-fleet" || p.Method != "hmac" || !p.HasScope(ScopeWrite) {
+				t.Fatalf("unexpected principal: %+v", p)
+			}
+		})
+	}
+}
+
+func TestHMACAuthenticatorReplay(t *testing.T) {
+	now := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	a := NewHMACAuthenticator(testHMACKeys, time.Minute)
+	a.now = func() time.Time { return now }
+
+	r := httptest.NewRequest(http.MethodPut, "/v1/logs/dt=2019-11-01/scan=id/check.log", nil)
+	SignRequest(r, "agents", []byte("secret"), []byte("log"), now)
+
+	if _, err := a.Authenticate(r); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := a.Authenticate(r); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected replayed request to be rejected, got: %v", err)
+	}
+
+	// The same signature encoded in upper case is the same request.
+	upper := r.Clone(r.Context())
+	keyID, sig, _ := parseHMACAuthorization(r.Header.Get("Authorization"))
+	upper.Header.Set("Authorization", fmt.Sprintf("%s KeyId=%s, Signature=%s", hmacScheme, keyID, strings.ToUpper(sig)))
+	if _, err := a.Authenticate(upper); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected replayed request with the signature in upper case to be rejected, got: %v", err)
+	}
+
+	// Expired signatures are forgotten.
+	later := now.Add(2 * time.Minute)
+	a.now = func() time.Time { return later }
+	r = httptest.NewRequest(http.MethodPut, "/v1/logs/dt=2019-11-01/scan=id/check.log", nil)
+	SignRequest(r, "agents", []byte("secret"), []byte("log"), later)
+	if _, err := a.Authenticate(r); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(a.seen) != 1 || len(a.expiries) != 1 {
+		t.Fatalf("expected expired signatures to be forgotten, got: %v", a.seen)
+	}
+}
+
+// unreadBody is a request body that records whether it was read.
+type unreadBody struct {
+	io.Reader
+	read bool
+}
+
+func (b *unreadBody) Read(p []byte) (int, error) {
+	b.read = true
+	return b.Reader.Read(p)
+}
+
+func TestVerifyContentHash(t *testing.T) {
+	var body string
+	var called bool
+	a := NewHMACAuthenticator(testHMACKeys, 0)
+	h := a.VerifyContentHash(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		body = string(b)
+	}), func(r *http.Request) int64 {
+		if r.URL.Path == "/v1/report" {
+			return 1 << 10
+		}
+		return 1 << 20
+	})
+
+	testCases := []struct {
+		name       string
+		req        func() *http.Request
+		wantStatus int
+	}{
+		{
+			name: "signed body",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", strings.NewReader("report"))
+				SignRequest(r, "agents", []byte("secret"), []byte("report"), time.Now())
+				return r
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "tampered body",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", strings.NewReader("tampered"))
+				SignRequest(r, "agents", []byte("secret"), []byte("report"), time.Now())
+				return r
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "signed request without content hash",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", strings.NewReader("report"))
+				SignRequest(r, "agents", []byte("secret"), []byte("report"), time.Now())
+				r.Header.Del(HeaderContentSHA256)
+				return r
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "invalid content hash",
+			req: func() *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", strings.NewReader("report"))
+				SignRequest(r, "agents", []byte("secret"), []byte("report"), time.Now())
+				r.Header.Set(HeaderContentSHA256, "not hex")
+				return r
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "report too large",
+			req: func() *http.Request {
+				content := strings.Repeat("a", 1<<10+1)
+				r := httptest.NewRequest(http.MethodPost, "/v1/report", strings.NewReader(content))
+				SignRequest(r, "agents", []byte("secret"), []byte(content), time.Now())
+				return r
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "log too large",
+			req: func() *http.Request {
+				content := strings.Repeat("a", 1<<20+1)
+				r := httptest.NewRequest(http.MethodPut, "/v1/logs/dt=2019-11-01/scan=id/check.log", strings.NewReader(content))
+				SignRequest(r, "agents", []byte("secret"), []byte(content), time.Now())
+				r.ContentLength = -1
+				return r
+			},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "not signed",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/v1/report", strings.NewReader("report"))
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	// The bodies of the requests not signed with a known key are not
+	// read.
+	for _, k := range []struct{ id, secret string }{{"unknown", "secret"}, {"agents", "other"}} {
+		b := &unreadBody{Reader: strings.NewReader("report")}
+		r := httptest.NewRequest(http.MethodPost, "/v1/report", b)
+		SignRequest(r, k.id, []byte(k.secret), []byte("report"), time.Now())
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, r)
+		if rw.Code != http.StatusUnauthorized || b.read {
+			t.Fatalf("expected the request to be rejected without reading its body, got: %d, read: %v", rw.Code, b.read)
+		}
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			body, called = "", false
+			rw := httptest.NewRecorder()
+			h.ServeHTTP(rw, tc.req())
+			if rw.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got: %d", tc.wantStatus, rw.Code)
+			}
+			if called != (tc.wantStatus == http.StatusOK) {
+				t.Fatalf("expected the handler to be called only for valid bodies, called: %v", called)
+			}
+			if called && body != "report" {
+				t.Fatalf("expected the body to be passed to the handler, got: %q", body)
+			}
+		})
+	}
+}
+
+func TestBufferBody(t *testing.T) {
+	content := strings.Repeat("a", maxMemoryBody+10)
+	body, sum, err := bufferBody(strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	f := body.(*tempFile)
+	got, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := sha256.Sum256([]byte(content))
+	if string(got) != content || !bytes.Equal(sum, want[:]) {
+		t.Fatalf("unexpected body or hash")
+	}
+	if err := body.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary file to be removed, got: %v", err)
+	}
+
+	if _, _, err := bufferBody(strings.NewReader(content), int64(len(content)-1)); !errors.Is(err, errBodyTooLarge) {
+		t.Fatalf("expected error %v, got: %v", errBodyTooLarge, err)
+	}
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTAuthenticator authenticates the requests with a bearer JWT signed
+// with one of the keys of a JSON Web Key Set. The RS256, RS384, RS512,
+// ES256, ES384 and ES512 algorithms are supported, the latter with keys
+// of the P-256, P-384 and P-521 curves respectively. The scopes are
+// taken from the scope claim, a space separated list, or the scp claim,
+// a list.
+type JWTAuthenticator struct {
+	keys     map[string]crypto.PublicKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTAuthenticator returns a JWTAuthenticator that verifies the JWTs
+// with the given keys, indexed by their key ID. If issuer or audience
+// are not empty the JWTs must have them in their iss and aud claims.
+func NewJWTAuthenticator(keys map[string]crypto.PublicKey, issuer, audience string) *JWTAuthenticator {
+	return &JWTAuthenticator{keys: keys, issuer: issuer, audience: audience, now: time.Now}
+}
+
+// jwtHeader is the header of a JWT.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtClaims are the claims of a JWT used to authenticate a request.
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scp       []string        `json:"scp"`
+}
+
+// Authenticate authenticates a request with a bearer JWT.
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := bearerToken(r)
+	if !ok || !isJWT(token) {
+		return nil, ErrNoCredentials
+	}
+	claims, err := a.verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	scopes := claims.Scp
+	if claims.Scope != "" {
+		scopes = strings.Fields(claims.Scope)
+	}
+	return &Principal{Subject: claims.Subject, Method: "jwt", Scopes: scopes}, nil
+}
+
+// verify checks the signature and the claims of a JWT and returns its
+// claims.
+func (a *JWTAuthenticator) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return jwtClaims{}, fmt.Errorf("invalid header: %w", err)
+	}
+	key, err := a.key(header.Kid)
+	if err != nil {
+		return jwtClaims{}, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return jwtClaims{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return jwtClaims{}, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return jwtClaims{}, fmt.Errorf("invalid claims: %w", err)
+	}
+	now := a.now().Unix()
+	if claims.ExpiresAt == nil || now >= *claims.ExpiresAt {
+		return jwtClaims{}, fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != nil && now < *claims.NotBefore {
+		return jwtClaims{}, fmt.Errorf("token not valid yet")
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return jwtClaims{}, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if a.audience != "" && !hasAudience(claims.Audience, a.audience) {
+		return jwtClaims{}, fmt.Errorf("token not issued for %q", a.audience)
+	}
+	return claims, nil
+}
+
+// key returns the key with the given ID. A token without key ID can
+// only be verified if the key set has a single key.
+func (a *JWTAuthenticator) key(kid string) (crypto.PublicKey, error) {
+	if kid == "" && len(a.keys) == 1 {
+		for _, k := range a.keys {
+			return k, nil
+		}
+	}
+	k, ok := a.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return k, nil
+}
+
+// ecdsaCurves are the curves of the keys of the ECDSA algorithms.
+var ecdsaCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verifySignature verifies the signature of a JWT signed with the
+// given algorithm, which must match the type of the key and, for ECDSA,
+// its curve.
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	var h crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		h = crypto.SHA256
+	case "RS384", "ES384":
+		h = crypto.SHA384
+	case "RS512", "ES512":
+		h = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	hasher := h.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg[0] != 'R' {
+			return fmt.Errorf("algorithm %s does not match the key", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(k, h, digest, sig); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+	case *ecdsa.PublicKey:
+		curve, ok := ecdsaCurves[alg]
+		if !ok || curve.Params().Name != k.Curve.Params().Name {
+			return fmt.Errorf("algorithm %s does not match the key curve %s", alg, k.Curve.Params().Name)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid signature size %d", len(sig))
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return nil
+}
+
+// hasAudience tells whether the aud claim, a string or a list of
+// strings, contains audience.
+func hasAudience(aud json.RawMessage, audience string) bool {
+	var one string
+	if err := json.Unmarshal(aud, &one); err == nil {
+		return one == audience
+	}
+	var many []string
+	if err := json.Unmarshal(aud, &many); err != nil {
+		return false
+	}
+	for _, a := range many {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a JWT.
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// jwk is a JSON Web Key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadJWKS reads the RSA and EC public keys of the JSON Web Key Set in
+// the file at path, indexed by their key ID. Keys not meant for
+// signatures are skipped.
+func LoadJWKS(path string) (map[string]crypto.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading JWKS: %w", err)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("loading JWKS: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("loading JWKS: key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+// publicKey returns the public key of a JWK.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signJWT returns a JWT with the given claims signed with key.
+func signJWT(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	h := crypto.SHA256
+	switch alg[2:] {
+	case "384":
+		h = crypto.SHA384
+	case "512":
+		h = crypto.SHA512
+	}
+	hasher := h.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, h, digest)
+		if err != nil {
+			t.Fatalf("signing JWT: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			t.Fatalf("signing JWT: %v", err)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// writeJWKS writes a JWKS with the public keys of rsaKey and ecKey to a
+// temporary file and returns its path.
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	enc := base64.RawURLEncoding.EncodeToString
+	set := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa", "use": "sig",
+				"n": enc(rsaKey.N.Bytes()),
+				"e": enc(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec", "crv": "P-256",
+				"x": enc(ecKey.X.Bytes()),
+				"y": enc(ecKey.Y.Bytes()),
+			},
+			{"kty": "RSA", "kid": "enc", "use": "enc"},
+		},
+	}
+	b, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshaling JWKS: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		t.Fatalf("writing JWKS: %v", err)
+	}
+	return path
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating EC key: %v", err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating EC key: %v", err)
+	}
+	keys, err := LoadJWKS(writeJWKS(t, rsaKey, ecKey))
+	if err != nil {
+		t.Fatalf("expected no error loading JWKS, got: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected the 2 signing keys, got: %v", keys)
+	}
+
+	now := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	claims := func(extra map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":   "team-a",
+			"iss":   "https://issuer",
+			"aud":   []string{"vulcan-results"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "results:read results:write",
+		}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name    string
+		token   string
+		wantErr error
+		scopes  []string
+	}{
+		{
+			name:   "RS256",
+			token:  signJWT(t, "RS256", "rsa", rsaKey, claims(nil)),
+			scopes: []string{ScopeRead, ScopeWrite},
+		},
+		{
+			name:   "ES256 with scp claim",
+			token:  signJWT(t, "ES256", "ec", ecKey, claims(map[string]interface{}{"scope": "", "scp": []string{ScopeRead}})),
+			scopes: []string{ScopeRead},
+		},
+		{
+			name:    "unknown signer",
+			token:   signJWT(t, "ES256", "ec", otherKey, claims(nil)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "unknown key",
+			token:   signJWT(t, "RS256", "other", rsaKey, claims(nil)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "algorithm not matching the key",
+			token:   signJWT(t, "ES256", "rsa", rsaKey, claims(nil)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "algorithm not matching the key curve",
+			token:   signJWT(t, "ES384", "ec", ecKey, claims(nil)),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "expired",
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-time.Minute).Unix()})),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "not valid yet",
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"nbf": now.Add(time.Minute).Unix()})),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "wrong issuer",
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"iss": "https://other"})),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "wrong audience",
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"aud": "other"})),
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "not a JWT",
+			token:   "static-token",
+			wantErr: ErrNoCredentials,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := NewJWTAuthenticator(keys, "https://issuer", "vulcan-results")
+			a.now = func() time.Time { return now }
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/reports/dt=2019-11-01/scan=id/check.json", nil)
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+			p, err := a.Authenticate(r)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected error %v, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if p.Subject != "team-a" || p.Method != "jwt" {
+				t.Fatalf("unexpected principal: %+v", p)
+			}
+			for _, scope := range tc.scopes {
+				if !p.HasScope(scope) {
+					t.Fatalf("expected scope %s, got: %v", scope, p.Scopes)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifySignatureCurve(t *testing.T) {
+	curves := map[string]elliptic.Curve{
+		"ES256": elliptic.P256(),
+		"ES384": elliptic.P384(),
+		"ES512": elliptic.P521(),
+	}
+	keys := map[string]*ecdsa.PrivateKey{}
+	for alg, curve := range curves {
+		k, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		keys[alg] = k
+	}
+
+	for alg := range curves {
+		for keyAlg, key := range keys {
+			token := signJWT(t, alg, "ec", key, map[string]interface{}{"sub": "team-a"})
+			i := strings.LastIndexByte(token, '.')
+			sig, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			err = verifySignature(alg, &key.PublicKey, token[:i], sig)
+			if keyAlg == alg && err != nil {
+				t.Fatalf("%s with a %s key: expected no error, got: %v", alg, key.Curve.Params().Name, err)
+			}
+			if keyAlg != alg && err == nil {
+				t.Fatalf("%s with a %s key: expected an error, got none", alg, key.Curve.Params().Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// errForbidden is the class of the error documents returned when the
+// principal lacks a scope required by the action.
+var errForbidden = goa.NewErrorClass("forbidden", http.StatusForbidden)
+
+// Middleware returns the goa security middleware that authenticates
+// the requests with a and checks the principal was granted the scopes
+// required by the action. The principal is stored in the context
+// passed to the action, see ContextPrincipal.
+func Middleware(a Authenticator) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			p, err := a.Authenticate(req)
+			if err != nil {
+				goa.LogInfo(ctx, "authentication failed", "err", err)
+				rw.Header().Set("WWW-Authenticate", `Bearer, `+hmacScheme)
+				detail := "invalid credentials"
+				if errors.Is(err, ErrNoCredentials) {
+					detail = "missing credentials"
+				}
+				return goa.ErrUnauthorized(detail)
+			}
+
+			for _, scope := range goa.ContextRequiredScopes(ctx) {
+				if !p.HasScope(scope) {
+					goa.LogInfo(ctx, "missing scope", "principal", p.Subject, "scope", scope)
+					return errForbidden("missing scope "+scope, "scope", scope)
+				}
+			}
+
+			ctx = goa.WithLogContext(ctx, "principal", p.Subject)
+			return h(WithPrincipal(ctx, p), rw, req)
+		}
+	}
+}
+
+// Anonymous returns the goa security middleware used when the
+// authentication is disabled. It lets every request through without a
+// principal.
+func Anonymous() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return h
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goadesign/goa"
+)
+
+func TestMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	tokens := `# token subject scopes
+reader-token team-a results:read
+writer-token agents results:write
+`
+	if err := os.WriteFile(path, []byte(tokens), 0600); err != nil {
+		t.Fatalf("writing tokens: %v", err)
+	}
+	chain, err := New(Config{TokensFile: path})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	testCases := []struct {
+		name          string
+		authorization string
+		scope         string
+		status        int
+		subject       string
+	}{
+		{
+			name:          "read with read scope",
+			authorization: "Bearer reader-token",
+			scope:         ScopeRead,
+			subject:       "team-a",
+		},
+		{
+			name:          "write with write scope",
+			authorization: "bearer writer-token",
+			scope:         ScopeWrite,
+			subject:       "agents",
+		},
+		{
+			name:          "write with read scope",
+			authorization: "Bearer reader-token",
+			scope:         ScopeWrite,
+			status:        http.StatusForbidden,
+		},
+		{
+			name:          "unknown token",
+			authorization: "Bearer other-token",
+			scope:         ScopeRead,
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:   "no credentials",
+			scope:  ScopeRead,
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var principal *Principal
+			h := Middleware(chain)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				principal = ContextPrincipal(ctx)
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/reports/dt=2019-11-01/scan=id/check.json", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			ctx := goa.WithRequiredScopes(context.Background(), []string{tc.scope})
+			rw := httptest.NewRecorder()
+			err := h(ctx, rw, req)
+
+			if tc.status != 0 {
+				serr, ok := err.(goa.ServiceError)
+				if !ok || serr.ResponseStatus() != tc.status {
+					t.Fatalf("expected error with status %d, got: %v", tc.status, err)
+				}
+				if tc.status == http.StatusUnauthorized && rw.Header().Get("WWW-Authenticate") == "" {
+					t.Fatalf("expected WWW-Authenticate header")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if principal == nil || principal.Subject != tc.subject || principal.Method != "token" {
+				t.Fatalf("unexpected principal: %+v", principal)
+			}
+		})
+	}
+}
+
+func TestAnonymous(t *testing.T) {
+	called := false
+	h := Anonymous()(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		if p := ContextPrincipal(ctx); p != nil {
+			t.Fatalf("expected no principal, got: %+v", p)
+		}
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+	ctx := goa.WithRequiredScopes(context.Background(), []string{ScopeWrite})
+	if err := h(ctx, httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !called {
+		t.Fatalf("expected the request to be let through")
+	}
+}
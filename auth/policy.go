@@ -0,0 +1,287 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/mtls"
+)
+
+// DefaultPolicyReloadInterval is the interval the policy file is checked
+// for changes at unless another one is configured.
+const DefaultPolicyReloadInterval = 30 * time.Second
+
+// anyScan is the scan that matches all of them in a rule.
+const anyScan = "*"
+
+// ErrForbidden is returned when a principal is not allowed to access
+// the results of a scan.
+var ErrForbidden = errors.New("access denied")
+
+// Access is an access to the results of a check of a scan.
+type Access struct {
+	ScanID  string
+	CheckID string
+
+	// tag is the tag of the stored report of the check, if it was
+	// looked up to authorize the access.
+	tag string
+}
+
+// Policy authorizes the principals to access the results of the scans
+// granted to them. A policy file is a TOML document with a list of
+// rules, every one granting a list of principals, identified by their
+// subject, the scans with the given IDs and the reads of the checks
+// with the given tags. A "*" scan grants all the scans. Principals not
+// listed in any rule are denied everything.
+//
+//	[[Rule]]
+//	Principals = ["team-a", "agent-fleet-a"]
+//	Scans = ["9126034c-7caf-4acd-93f3-bee1941aa140"]
+//	Tags = ["team-a"]
+//
+// The tag of a check is the tag of its stored report, so the tags only
+// grant reading the results already stored. The uploads are authorized
+// by the scan ID only, as the tag of an uploaded report is set by the
+// client being authorized.
+type Policy struct {
+	path  string
+	audit *logrus.Entry
+
+	mu      sync.RWMutex
+	modTime time.Time
+	grants  map[string]grant
+}
+
+// grant are the scans, and the tags of the checks, a principal can
+// access.
+type grant struct {
+	all   bool
+	scans map[string]bool
+	tags  map[string]bool
+}
+
+// policyFile is the format of the policy files.
+type policyFile struct {
+	Rule []struct {
+		Principals []string
+		Scans      []string
+		Tags       []string
+	} `toml:"Rule"`
+}
+
+// LoadPolicy loads the policy in the file at path. The accesses denied
+// are logged to audit.
+func LoadPolicy(path string, audit *logrus.Entry) (*Policy, error) {
+	p := &Policy{path: path, audit: audit}
+	if _, err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload loads the policy file again if it was modified since it was
+// loaded, and tells whether it did. The current policy is kept if the
+// file can not be loaded.
+func (p *Policy) Reload() (bool, error) {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return false, fmt.Errorf("loading policy: %w", err)
+	}
+	p.mu.RLock()
+	unchanged := info.ModTime().Equal(p.modTime)
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	var f policyFile
+	md, err := toml.DecodeFile(p.path, &f)
+	if err != nil {
+		return false, fmt.Errorf("loading policy: %w", err)
+	}
+	// Reject the fields that are not supported instead of ignoring the
+	// grants they meant.
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return false, fmt.Errorf("loading policy: unknown field %s", undecoded[0])
+	}
+	grants := map[string]grant{}
+	for _, r := range f.Rule {
+		for _, principal := range r.Principals {
+			g, ok := grants[principal]
+			if !ok {
+				g = grant{scans: map[string]bool{}, tags: map[string]bool{}}
+			}
+			for _, s := range r.Scans {
+				g.all = g.all || s == anyScan
+				g.scans[s] = true
+			}
+			for _, t := range r.Tags {
+				g.tags[t] = true
+			}
+			grants[principal] = g
+		}
+	}
+
+	p.mu.Lock()
+	p.grants = grants
+	p.modTime = info.ModTime()
+	p.mu.Unlock()
+	return true, nil
+}
+
+// Watch reloads the policy file every interval when it changes, until
+// ctx is done. If interval is not positive
+// DefaultPolicyReloadInterval is used.
+func (p *Policy) Watch(ctx context.Context, interval time.Duration, logger *logrus.Entry) {
+	if interval <= 0 {
+		interval = DefaultPolicyReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		reloaded, err := p.Reload()
+		if err != nil {
+			logger.WithError(err).Error("policy not reloaded")
+			continue
+		}
+		if reloaded {
+			logger.WithField("path", p.path).Info("policy reloaded")
+		}
+	}
+}
+
+// Authorize checks that the principal the request of ctx is
+// authenticated as can access the results of a scan. It fails with
+// ErrForbidden otherwise, logging the denied access to the audit
+// stream. A nil policy allows every access.
+func (p *Policy) Authorize(ctx context.Context, a Access) error {
+	return p.authorize(ctx, a, nil)
+}
+
+// AuthorizeRead checks, like Authorize, that the principal the request
+// of ctx is authenticated as can read the results of a check, which is
+// also granted by the tag of its stored report. The tag is looked up
+// with the given func only if the scan is not granted, and it is empty
+// if the check has no report. The errors looking it up are returned as
+// they are.
+func (p *Policy) AuthorizeRead(ctx context.Context, a Access, tag func() (string, error)) error {
+	return p.authorize(ctx, a, tag)
+}
+
+// authorize checks the principal of the request of ctx can access the
+// results of a scan, or the check with the tag returned by tag if it is
+// not nil.
+func (p *Policy) authorize(ctx context.Context, a Access, tag func() (string, error)) error {
+	if p == nil {
+		return nil
+	}
+
+	principal := ContextPrincipal(ctx)
+	if principal == nil {
+		return p.deny(ctx, a, nil, "unauthenticated request")
+	}
+
+	p.mu.RLock()
+	g, ok := p.grants[principal.Subject]
+	p.mu.RUnlock()
+	switch {
+	case !ok:
+		return p.deny(ctx, a, principal, "no rule for the principal")
+	case g.all || g.scans[a.ScanID]:
+		return nil
+	case tag == nil || len(g.tags) == 0:
+		return p.deny(ctx, a, principal, "scan not granted")
+	}
+
+	t, err := tag()
+	if err != nil {
+		return err
+	}
+	if t != "" && g.tags[t] {
+		return nil
+	}
+	a.tag = t
+	return p.deny(ctx, a, principal, "scan and tag not granted")
+}
+
+// AuthorizeList checks that the principal the request of ctx is
+// authenticated as can list scans, that is it is listed in any rule of
+// the policy. It returns a func that tells whether a scan is granted to
+// the principal, to filter the listed scans. It fails with ErrForbidden
+// otherwise, logging the denied access to the audit stream. A nil
+// policy allows listing every scan. The tags do not grant listing the
+// scans of their checks.
+func (p *Policy) AuthorizeList(ctx context.Context) (func(scanID string) bool, error) {
+	if p == nil {
+		return func(string) bool { return true }, nil
+	}
+
+	principal := ContextPrincipal(ctx)
+	if principal == nil {
+		return nil, p.deny(ctx, Access{}, nil, "unauthenticated request")
+	}
+
+	p.mu.RLock()
+	g, ok := p.grants[principal.Subject]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, p.deny(ctx, Access{}, principal, "no rule for the principal")
+	}
+	return func(scanID string) bool {
+		return g.all || g.scans[scanID]
+	}, nil
+}
+
+// deny logs a denied access to the audit stream and returns the error
+// to report it.
+func (p *Policy) deny(ctx context.Context, a Access, principal *Principal, reason string) error {
+	fields := logrus.Fields{
+		"event":    "access_denied",
+		"action":   goa.ContextAction(ctx),
+		"scan_id":  a.ScanID,
+		"check_id": a.CheckID,
+		"reason":   reason,
+	}
+	if a.tag != "" {
+		fields["tag"] = a.tag
+	}
+	if principal != nil {
+		fields["principal"] = principal.Subject
+		fields["auth_method"] = principal.Method
+	}
+	if id := middleware.ContextRequestID(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if subject := mtls.ContextClientSubject(ctx); subject != "" {
+		fields["client_cert"] = subject
+	}
+	if req := goa.ContextRequest(ctx); req != nil && req.Request != nil {
+		fields["remote_addr"] = req.RemoteAddr
+	}
+	p.audit.WithFields(fields).Warn("access denied")
+
+	if a.ScanID == "" {
+		return fmt.Errorf("%w: %s to list the scans", ErrForbidden, reason)
+	}
+	return fmt.Errorf("%w: %s to the results of scan %s", ErrForbidden, reason, a.ScanID)
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/mtls"
+)
+
+const testPolicy = `
+[[Rule]]
+Principals = ["team-a", "agents-a"]
+Scans = ["scan-a"]
+Tags = ["tag-a"]
+
+[[Rule]]
+Principals = ["security"]
+Scans = ["*"]
+`
+
+// writePolicy writes a policy file modified at the given time.
+func writePolicy(t *testing.T, path, policy string, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(policy), 0600); err != nil {
+		t.Fatalf("writing policy: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("setting policy modification time: %v", err)
+	}
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.toml")
+	writePolicy(t, path, testPolicy, time.Now())
+
+	var audit bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &audit
+	logger.Formatter = &logrus.JSONFormatter{}
+	p, err := LoadPolicy(path, logrus.NewEntry(logger))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		principal  *Principal
+		clientCert string
+		access     Access
+		denied     bool
+	}{
+		{
+			name:      "granted scan",
+			principal: &Principal{Subject: "team-a"},
+			access:    Access{ScanID: "scan-a"},
+		},
+		{
+			name:      "granted scan to another principal",
+			principal: &Principal{Subject: "agents-a"},
+			access:    Access{ScanID: "scan-a"},
+		},
+		{
+			name:      "all scans",
+			principal: &Principal{Subject: "security"},
+			access:    Access{ScanID: "scan-b"},
+		},
+		{
+			name:      "scan not granted",
+			principal: &Principal{Subject: "team-a", Method: "token"},
+			access:    Access{ScanID: "scan-b"},
+			denied:    true,
+		},
+		{
+			name:      "unknown principal",
+			principal: &Principal{Subject: "team-b"},
+			access:    Access{ScanID: "scan-a"},
+			denied:    true,
+		},
+		{
+			name:       "unknown principal with a client certificate",
+			principal:  &Principal{Subject: "team-b"},
+			clientCert: "CN=agent-1,OU=agents",
+			access:     Access{ScanID: "scan-a"},
+			denied:     true,
+		},
+		{
+			name:   "unauthenticated",
+			access: Access{ScanID: "scan-a"},
+			denied: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			audit.Reset()
+			ctx := context.Background()
+			if tc.principal != nil {
+				ctx = WithPrincipal(ctx, tc.principal)
+			}
+			if tc.clientCert != "" {
+				ctx = mtls.WithClientSubject(ctx, tc.clientCert)
+			}
+
+			err := p.Authorize(ctx, tc.access)
+			if !tc.denied {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				if audit.Len() != 0 {
+					t.Fatalf("expected no audit entry, got: %s", audit.String())
+				}
+				return
+			}
+			if !errors.Is(err, ErrForbidden) {
+				t.Fatalf("expected error %v, got: %v", ErrForbidden, err)
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(audit.Bytes(), &entry); err != nil {
+				t.Fatalf("expected a JSON audit entry, got: %q", audit.String())
+			}
+			if entry["event"] != "access_denied" || entry["scan_id"] != tc.access.ScanID {
+				t.Fatalf("unexpected audit entry: %v", entry)
+			}
+			if tc.principal != nil && entry["principal"] != tc.principal.Subject {
+				t.Fatalf("expected principal %s in audit entry, got: %v", tc.principal.Subject, entry)
+			}
+			if tc.clientCert != "" && entry["client_cert"] != tc.clientCert {
+				t.Fatalf("expected client certificate %s in audit entry, got: %v", tc.clientCert, entry)
+			}
+		})
+	}
+}
+
+func TestPolicyReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.toml")
+	modTime := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	writePolicy(t, path, testPolicy, modTime)
+
+	p, err := LoadPolicy(path, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ctx := WithPrincipal(context.Background(), &Principal{Subject: "team-b"})
+	if err := p.Authorize(ctx, Access{ScanID: "scan-b"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected error %v, got: %v", ErrForbidden, err)
+	}
+
+	if reloaded, err := p.Reload(); reloaded || err != nil {
+		t.Fatalf("expected unchanged policy not to be reloaded, got: %v, %v", reloaded, err)
+	}
+
+	writePolicy(t, path, "[[Rule]]\nPrincipals = [\"team-b\"]\nScans = [\"scan-b\"]\n", modTime.Add(time.Minute))
+	if reloaded, err := p.Reload(); !reloaded || err != nil {
+		t.Fatalf("expected modified policy to be reloaded, got: %v, %v", reloaded, err)
+	}
+	if err := p.Authorize(ctx, Access{ScanID: "scan-b"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// An invalid policy keeps the current one.
+	writePolicy(t, path, "[[Rule]\n", modTime.Add(2*time.Minute))
+	if _, err := p.Reload(); err == nil {
+		t.Fatalf("expected error reloading invalid policy, got none")
+	}
+	if err := p.Authorize(ctx, Access{ScanID: "scan-b"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestPolicyAuthorizeList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.toml")
+	writePolicy(t, path, testPolicy, time.Now())
+	p, err := LoadPolicy(path, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	granted, err := p.AuthorizeList(WithPrincipal(context.Background(), &Principal{Subject: "team-a"}))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !granted("scan-a") || granted("scan-b") {
+		t.Fatalf("expected only scan-a to be granted")
+	}
+	granted, err = p.AuthorizeList(WithPrincipal(context.Background(), &Principal{Subject: "security"}))
+	if err != nil || !granted("scan-b") {
+		t.Fatalf("expected all the scans to be granted, got: %v", err)
+	}
+	if _, err := p.AuthorizeList(WithPrincipal(context.Background(), &Principal{Subject: "team-b"})); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected error %v, got: %v", ErrForbidden, err)
+	}
+}
+
+func TestPolicyAuthorizeRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.toml")
+	writePolicy(t, path, testPolicy, time.Now())
+
+	var audit bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &audit
+	logger.Formatter = &logrus.JSONFormatter{}
+	p, err := LoadPolicy(path, logrus.NewEntry(logger))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	errLookup := errors.New("lookup failed")
+
+	testCases := []struct {
+		name      string
+		principal string
+		scanID    string
+		tag       string
+		tagErr    error
+		// lookup tells whether the tag must be looked up.
+		lookup bool
+		err    error
+	}{
+		{name: "granted scan", principal: "team-a", scanID: "scan-a"},
+		{name: "all scans", principal: "security", scanID: "scan-b"},
+		{name: "granted tag", principal: "team-a", scanID: "scan-b", tag: "tag-a", lookup: true},
+		{name: "tag not granted", principal: "team-a", scanID: "scan-b", tag: "tag-b", lookup: true, err: ErrForbidden},
+		{name: "no tag", principal: "team-a", scanID: "scan-b", lookup: true, err: ErrForbidden},
+		{name: "lookup error", principal: "team-a", scanID: "scan-b", tagErr: errLookup, lookup: true, err: errLookup},
+		{name: "unknown principal", principal: "team-b", scanID: "scan-b", tag: "tag-a", err: ErrForbidden},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			audit.Reset()
+			ctx := WithPrincipal(context.Background(), &Principal{Subject: tc.principal})
+			looked := false
+			err := p.AuthorizeRead(ctx, Access{ScanID: tc.scanID}, func() (string, error) {
+				looked = true
+				return tc.tag, tc.tagErr
+			})
+			if !errors.Is(err, tc.err) || (err == nil) != (tc.err == nil) {
+				t.Fatalf("expected error %v, got: %v", tc.err, err)
+			}
+			if looked != tc.lookup {
+				t.Fatalf("expected the tag to be looked up to be %v", tc.lookup)
+			}
+			if errors.Is(tc.err, ErrForbidden) && tc.tag != "" && tc.lookup {
+				var entry map[string]interface{}
+				if err := json.Unmarshal(audit.Bytes(), &entry); err != nil {
+					t.Fatalf("expected a JSON audit entry, got: %q", audit.String())
+				}
+				if entry["tag"] != tc.tag {
+					t.Fatalf("expected tag %s in audit entry, got: %v", tc.tag, entry)
+				}
+			}
+		})
+	}
+
+	// The tags do not grant the uploads.
+	ctx := WithPrincipal(context.Background(), &Principal{Subject: "team-a"})
+	if err := p.Authorize(ctx, Access{ScanID: "scan-b"}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected error %v, got: %v", ErrForbidden, err)
+	}
+}
+
+func TestPolicyUnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.toml")
+	writePolicy(t, path, "[[Rule]]\nPrincipals = [\"team-a\"]\nChecks = [\"check-a\"]\n", time.Now())
+
+	if _, err := LoadPolicy(path, logrus.NewEntry(logrus.New())); err == nil {
+		t.Fatalf("expected error loading a policy with unknown fields, got none")
+	}
+}
+
+func TestNilPolicy(t *testing.T) {
+	var p *Policy
+	if err := p.Authorize(context.Background(), Access{ScanID: "scan-a"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+)
+
+// TokenAuthenticator authenticates the requests with a static bearer
+// token. JWTs are left to the JWTAuthenticator.
+type TokenAuthenticator struct {
+	// The tokens are indexed by their hash, so they are not compared
+	// byte by byte.
+	tokens map[[sha256.Size]byte]Principal
+}
+
+// NewTokenAuthenticator returns a TokenAuthenticator that accepts the
+// given tokens, mapped to the principal they authenticate.
+func NewTokenAuthenticator(tokens map[string]Principal) *TokenAuthenticator {
+	a := &TokenAuthenticator{tokens: make(map[[sha256.Size]byte]Principal, len(tokens))}
+	for token, p := range tokens {
+		p.Method = "token"
+		a.tokens[sha256.Sum256([]byte(token))] = p
+	}
+	return a
+}
+
+// Authenticate authenticates a request with a static bearer token.
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := bearerToken(r)
+	if !ok || isJWT(token) {
+		return nil, ErrNoCredentials
+	}
+	p, ok := a.tokens[sha256.Sum256([]byte(token))]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown token", ErrInvalidCredentials)
+	}
+	return &p, nil
+}
+
+// LoadTokens reads the tokens in the file at path. Every line has the
+// format "<token> <subject> <scope>[,<scope>...]".
+func LoadTokens(path string) (map[string]Principal, error) {
+	tokens := map[string]Principal{}
+	err := readLines(path, func(fields []string) error {
+		if len(fields) != 3 {
+			return fmt.Errorf("expected 3 fields, got %d", len(fields))
+		}
+		tokens[fields[0]] = Principal{Subject: fields[1], Scopes: parseScopes(fields[2])}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading tokens: %w", err)
+	}
+	return tokens, nil
+}
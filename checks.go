@@ -0,0 +1,67 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app"
+	"github.com/adevinta/vulcan-results/auth"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// ChecksController implements the checks resource.
+type ChecksController struct {
+	*goa.Controller
+	storage storage.Storage
+
+	// Policy, if not nil, authorizes the principals to list the checks
+	// of the scans granted to them.
+	Policy *auth.Policy
+}
+
+// NewChecksController creates a checks controller.
+func NewChecksController(service *goa.Service, s storage.Storage) *ChecksController {
+	return &ChecksController{Controller: service.NewController("ChecksController"), storage: s}
+}
+
+// List runs the list action.
+func (c *ChecksController) List(ctx *app.ListChecksContext) error {
+	goa.LogInfo(ctx, "Listing checks", "date", ctx.Date, "scan", ctx.Scan)
+
+	if err := c.Policy.Authorize(ctx, auth.Access{ScanID: ctx.Scan}); err != nil {
+		goa.LogError(ctx, err.Error())
+		return listError(ctx, err)
+	}
+
+	var next string
+	if ctx.Next != nil {
+		next = *ctx.Next
+	}
+
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
+	page, err := c.storage.ListChecks(sctx, ctx.Date, ctx.Scan, next, ctx.Limit)
+	if err != nil {
+		goa.LogError(ctx, err.Error())
+		return listError(ctx, err)
+	}
+
+	res := &app.CheckList{Checks: app.CheckObjectCollection{}}
+	for _, obj := range page.Checks {
+		res.Checks = append(res.Checks, &app.CheckObject{
+			CheckID:      obj.CheckID,
+			Kind:         obj.Kind,
+			Size:         int(obj.Size),
+			LastModified: obj.LastModified,
+		})
+	}
+	if page.Next != "" {
+		res.Next = &page.Next
+	}
+
+	return ctx.OK(res)
+}
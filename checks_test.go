@@ -0,0 +1,112 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app/test"
+	"github.com/adevinta/vulcan-results/auth"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+func TestListChecks(t *testing.T) {
+	st := storage.NewMemoryStorage(storage.Config{
+		BucketReports:           "reports",
+		BucketVulnerableReports: "vulnerable-reports",
+		BucketLogs:              "logs",
+		LinkBase:                "http://results/v1",
+	})
+	startedAt := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := st.SaveReports(context.Background(), scanID.String(), checkID.String(), startedAt, []byte("report"), false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := st.SaveLogs(context.Background(), scanID.String(), checkID.String(), startedAt, []byte("log")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	service := goa.New("vulcan-results")
+	ctrl := NewChecksController(service, st)
+
+	_, page := test.ListChecksOK(t, nil, service, ctrl, "2019-11-01", scanID.String(), 1, nil)
+	if len(page.Checks) != 1 || page.Checks[0].Kind != storage.KindReport || page.Checks[0].CheckID != checkID.String() {
+		t.Fatalf("expected the report of the check, got: %+v", page.Checks)
+	}
+	if page.Next == nil {
+		t.Fatalf("expected a next page, got none")
+	}
+
+	_, page = test.ListChecksOK(t, nil, service, ctrl, "2019-11-01", scanID.String(), 1, page.Next)
+	if len(page.Checks) != 1 || page.Checks[0].Kind != storage.KindLog || page.Checks[0].Size != 3 {
+		t.Fatalf("expected the log of the check, got: %+v", page.Checks)
+	}
+	if page.Next != nil {
+		t.Fatalf("expected no next page, got: %s", *page.Next)
+	}
+}
+
+func TestListChecksPolicy(t *testing.T) {
+	service := goa.New("vulcan-results")
+	ctrl := NewChecksController(service, storageMock{checks: &storage.CheckPage{Checks: []storage.CheckObject{}}})
+	ctrl.Policy = loadTestPolicy(t)
+
+	teamA := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "team-a"})
+	teamB := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "team-b"})
+
+	test.ListChecksOK(t, teamA, service, ctrl, "2019-11-01", scanID.String(), 100, nil)
+	_, err := test.ListChecksForbidden(t, teamB, service, ctrl, "2019-11-01", scanID.String(), 100, nil)
+	checkErrorCode(t, err, "forbidden")
+	_, err = test.ListChecksForbidden(t, teamA, service, ctrl, "2019-11-01", "other-scan", 100, nil)
+	checkErrorCode(t, err, "forbidden")
+}
+
+func TestListChecksErrors(t *testing.T) {
+	invalidToken := "not a token"
+
+	testCases := []struct {
+		name string
+		next *string
+		err  error
+		code string
+		fErr func(t *testing.T, ctrl *ChecksController, service *goa.Service, next *string) error
+	}{
+		{
+			name: "invalid token",
+			next: &invalidToken,
+			err:  fmt.Errorf("%w: illegal base64 data", storage.ErrInvalidToken),
+			code: "invalid_token",
+			fErr: func(t *testing.T, ctrl *ChecksController, service *goa.Service, next *string) error {
+				_, err := test.ListChecksBadRequest(t, nil, service, ctrl, "2019-11-01", scanID.String(), 10, next)
+				return err
+			},
+		},
+		{
+			name: "storage unavailable",
+			err:  fmt.Errorf("%w: SlowDown", storage.ErrUnavailable),
+			code: "storage_unavailable",
+			fErr: func(t *testing.T, ctrl *ChecksController, service *goa.Service, next *string) error {
+				_, err := test.ListChecksServiceUnavailable(t, nil, service, ctrl, "2019-11-01", scanID.String(), 10, next)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			service := goa.New("vulcan-results")
+			ctrl := NewChecksController(service, storageMock{err: tc.err})
+
+			err := tc.fErr(t, ctrl, service, tc.next)
+			checkErrorCode(t, err, tc.code)
+		})
+	}
+}
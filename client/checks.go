@@ -0,0 +1,64 @@
+// Code generated by goagen v1.4.3, DO NOT EDIT.
+//
+// API "vulcan-results": checks Resource Client
+//
+// Command:
+// $ goagen
+// --design=github.com/adevinta/vulcan-results/design
+// --out=/Users/manel.montilla/develop/vulcan-results
+// --version=v1.4.3
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListChecksPath computes a request path to the list action of checks.
+func ListChecksPath(date string, scan string) string {
+	param0 := date
+	param1 := scan
+
+	return fmt.Sprintf("/v1/scans/%s/%s/checks", param0, param1)
+}
+
+// List the reports and logs stored for the checks of a scan
+func (c *Client) ListChecks(ctx context.Context, path string, limit *int, next *string) (*http.Response, error) {
+	req, err := c.NewListChecksRequest(ctx, path, limit, next)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Do(ctx, req)
+}
+
+// NewListChecksRequest create the request corresponding to the list action endpoint of the checks resource.
+func (c *Client) NewListChecksRequest(ctx context.Context, path string, limit *int, next *string) (*http.Request, error) {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	values := u.Query()
+	if limit != nil {
+		tmp16 := strconv.Itoa(*limit)
+		values.Set("limit", tmp16)
+	}
+	if next != nil {
+		values.Set("next", *next)
+	}
+	u.RawQuery = values.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
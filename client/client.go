@@ -18,8 +18,9 @@ import (
 // Client is the vulcan-results service client.
 type Client struct {
 	*goaclient.Client
-	Encoder *goa.HTTPEncoder
-	Decoder *goa.HTTPDecoder
+	ResultsSigner goaclient.Signer
+	Encoder       *goa.HTTPEncoder
+	Decoder       *goa.HTTPDecoder
 }
 
 // New instantiates the client.
@@ -42,3 +43,8 @@ func New(c goaclient.Doer) *Client {
 
 	return client
 }
+
+// SetResultsSigner sets the request signer for the results security scheme.
+func (c *Client) SetResultsSigner(signer goaclient.Signer) {
+	c.ResultsSigner = signer
+}
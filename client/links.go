@@ -0,0 +1,92 @@
+// Code generated by goagen v1.4.3, DO NOT EDIT.
+//
+// API "vulcan-results": links Resource Client
+//
+// Command:
+// $ goagen
+// --design=github.com/adevinta/vulcan-results/design
+// --out=/Users/manel.montilla/develop/vulcan-results
+// --version=v1.4.3
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// LogLinksPath computes a request path to the log action of links.
+func LogLinksPath(date string, scan string, check string) string {
+	param0 := date
+	param1 := scan
+	param2 := check
+
+	return fmt.Sprintf("/v1/links/logs/%s/%s/%s", param0, param1, param2)
+}
+
+// Get a time-limited URL to download a log directly from the storage
+func (c *Client) LogLinks(ctx context.Context, path string) (*http.Response, error) {
+	req, err := c.NewLogLinksRequest(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Do(ctx, req)
+}
+
+// NewLogLinksRequest create the request corresponding to the log action endpoint of the links resource.
+func (c *Client) NewLogLinksRequest(ctx context.Context, path string) (*http.Request, error) {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// ReportLinksPath computes a request path to the report action of links.
+func ReportLinksPath(date string, scan string, check string) string {
+	param0 := date
+	param1 := scan
+	param2 := check
+
+	return fmt.Sprintf("/v1/links/reports/%s/%s/%s", param0, param1, param2)
+}
+
+// Get a time-limited URL to download a report directly from the storage
+func (c *Client) ReportLinks(ctx context.Context, path string) (*http.Response, error) {
+	req, err := c.NewReportLinksRequest(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Do(ctx, req)
+}
+
+// NewReportLinksRequest create the request corresponding to the report action endpoint of the links resource.
+func (c *Client) NewReportLinksRequest(ctx context.Context, path string) (*http.Request, error) {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,47 @@
+// Code generated by goagen v1.4.3, DO NOT EDIT.
+//
+// API "vulcan-results": readiness Resource Client
+//
+// Command:
+// $ goagen
+// --design=github.com/adevinta/vulcan-results/design
+// --out=/Users/manel.montilla/develop/vulcan-results
+// --version=v1.4.3
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ShowReadinessPath computes a request path to the show action of readiness.
+func ShowReadinessPath() string {
+
+	return fmt.Sprintf("/readiness")
+}
+
+// Get whether the service can access the storage the results are stored in
+func (c *Client) ShowReadiness(ctx context.Context, path string) (*http.Response, error) {
+	req, err := c.NewShowReadinessRequest(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Do(ctx, req)
+}
+
+// NewShowReadinessRequest create the request corresponding to the show action endpoint of the readiness resource.
+func (c *Client) NewShowReadinessRequest(ctx context.Context, path string) (*http.Request, error) {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 )
 
 // GetLogResultsPath computes a request path to the getLog action of Results.
@@ -27,9 +28,10 @@ func GetLogResultsPath(date string, scan string, check string) string {
 	return fmt.Sprintf("/v1/logs/%s/%s/%s", param0, param1, param2)
 }
 
-// Download a log
-func (c *Client) GetLogResults(ctx context.Context, path string) (*http.Response, error) {
-	req, err := c.NewGetLogResultsRequest(ctx, path)
+// Download a log, or a range of it.
+// With redirect, or if the service is configured to redirect by default, answers 302 Found with a time-limited URL to download it directly from the storage, if the storage supports it.
+func (c *Client) GetLogResults(ctx context.Context, path string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (*http.Response, error) {
+	req, err := c.NewGetLogResultsRequest(ctx, path, redirect, ifModifiedSince, ifNoneMatch, range_)
 	if err != nil {
 		return nil, err
 	}
@@ -37,16 +39,40 @@ func (c *Client) GetLogResults(ctx context.Context, path string) (*http.Response
 }
 
 // NewGetLogResultsRequest create the request corresponding to the getLog action endpoint of the Results resource.
-func (c *Client) NewGetLogResultsRequest(ctx context.Context, path string) (*http.Request, error) {
+func (c *Client) NewGetLogResultsRequest(ctx context.Context, path string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (*http.Request, error) {
 	scheme := c.Scheme
 	if scheme == "" {
 		scheme = "http"
 	}
 	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	values := u.Query()
+	if redirect != nil {
+		tmp14 := strconv.FormatBool(*redirect)
+		values.Set("redirect", tmp14)
+	}
+	u.RawQuery = values.Encode()
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
+	header := req.Header
+	if ifModifiedSince != nil {
+
+		header.Set("If-Modified-Since", *ifModifiedSince)
+	}
+	if ifNoneMatch != nil {
+
+		header.Set("If-None-Match", *ifNoneMatch)
+	}
+	if range_ != nil {
+
+		header.Set("Range", *range_)
+	}
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
 	return req, nil
 }
 
@@ -59,9 +85,10 @@ func GetReportResultsPath(date string, scan string, check string) string {
 	return fmt.Sprintf("/v1/reports/%s/%s/%s", param0, param1, param2)
 }
 
-// Download a report
-func (c *Client) GetReportResults(ctx context.Context, path string) (*http.Response, error) {
-	req, err := c.NewGetReportResultsRequest(ctx, path)
+// Download a report, or a range of it.
+// With redirect, or if the service is configured to redirect by default, answers 302 Found with a time-limited URL to download it directly from the storage, if the storage supports it.
+func (c *Client) GetReportResults(ctx context.Context, path string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (*http.Response, error) {
+	req, err := c.NewGetReportResultsRequest(ctx, path, redirect, ifModifiedSince, ifNoneMatch, range_)
 	if err != nil {
 		return nil, err
 	}
@@ -69,16 +96,78 @@ func (c *Client) GetReportResults(ctx context.Context, path string) (*http.Respo
 }
 
 // NewGetReportResultsRequest create the request corresponding to the getReport action endpoint of the Results resource.
-func (c *Client) NewGetReportResultsRequest(ctx context.Context, path string) (*http.Request, error) {
+func (c *Client) NewGetReportResultsRequest(ctx context.Context, path string, redirect *bool, ifModifiedSince *string, ifNoneMatch *string, range_ *string) (*http.Request, error) {
 	scheme := c.Scheme
 	if scheme == "" {
 		scheme = "http"
 	}
 	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	values := u.Query()
+	if redirect != nil {
+		tmp15 := strconv.FormatBool(*redirect)
+		values.Set("redirect", tmp15)
+	}
+	u.RawQuery = values.Encode()
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
+	header := req.Header
+	if ifModifiedSince != nil {
+
+		header.Set("If-Modified-Since", *ifModifiedSince)
+	}
+	if ifNoneMatch != nil {
+
+		header.Set("If-None-Match", *ifNoneMatch)
+	}
+	if range_ != nil {
+
+		header.Set("Range", *range_)
+	}
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// PutLogResultsPath computes a request path to the putLog action of Results.
+func PutLogResultsPath(date string, scan string, check string) string {
+	param0 := date
+	param1 := scan
+	param2 := check
+
+	return fmt.Sprintf("/v1/logs/%s/%s/%s", param0, param1, param2)
+}
+
+// Upload the log of a check streaming the request body to the storage.
+// The body is either the raw log or a multipart/form-data form with the log in the "log" field.
+func (c *Client) PutLogResults(ctx context.Context, path string) (*http.Response, error) {
+	req, err := c.NewPutLogResultsRequest(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Do(ctx, req)
+}
+
+// NewPutLogResultsRequest create the request corresponding to the putLog action endpoint of the Results resource.
+func (c *Client) NewPutLogResultsRequest(ctx context.Context, path string) (*http.Request, error) {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	req, err := http.NewRequestWithContext(ctx, "PUT", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
 	return req, nil
 }
 
@@ -88,7 +177,8 @@ func RawResultsPath() string {
 	return fmt.Sprintf("/v1/raw")
 }
 
-// Update the Raw of a Check
+// Update the Raw of a Check.
+// Answers 202 Accepted if the storage is unavailable and the logs were spooled to be stored later.
 func (c *Client) RawResults(ctx context.Context, path string, payload *RawPayload) (*http.Response, error) {
 	req, err := c.NewRawResultsRequest(ctx, path, payload)
 	if err != nil {
@@ -115,6 +205,11 @@ func (c *Client) NewRawResultsRequest(ctx context.Context, path string, payload
 	}
 	header := req.Header
 	header.Set("Content-Type", "application/json")
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
 	return req, nil
 }
 
@@ -124,7 +219,9 @@ func ReportResultsPath() string {
 	return fmt.Sprintf("/v1/report")
 }
 
-// Update the Report of a Check
+// Update the Report of a Check.
+// Storing the same report again does not write it, and storing a report that ended before the stored one answers 409 Conflict unless force is true.
+// Answers 202 Accepted if the storage is unavailable and the report was spooled to be stored later.
 func (c *Client) ReportResults(ctx context.Context, path string, payload *ReportPayload) (*http.Response, error) {
 	req, err := c.NewReportResultsRequest(ctx, path, payload)
 	if err != nil {
@@ -151,5 +248,10 @@ func (c *Client) NewReportResultsRequest(ctx context.Context, path string, paylo
 	}
 	header := req.Header
 	header.Set("Content-Type", "application/json")
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
 	return req, nil
 }
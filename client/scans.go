@@ -0,0 +1,58 @@
+// Code generated by goagen v1.4.3, DO NOT EDIT.
+//
+// API "vulcan-results": scans Resource Client
+//
+// Command:
+// $ goagen
+// --design=github.com/adevinta/vulcan-results/design
+// --out=/Users/manel.montilla/develop/vulcan-results
+// --version=v1.4.3
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ListScansPath computes a request path to the list action of scans.
+func ListScansPath() string {
+
+	return fmt.Sprintf("/v1/scans")
+}
+
+// List the scans that stored reports in a range of dates
+func (c *Client) ListScans(ctx context.Context, path string, from string, to *string) (*http.Response, error) {
+	req, err := c.NewListScansRequest(ctx, path, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Do(ctx, req)
+}
+
+// NewListScansRequest create the request corresponding to the list action endpoint of the scans resource.
+func (c *Client) NewListScansRequest(ctx context.Context, path string, from string, to *string) (*http.Request, error) {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	values := u.Query()
+	values.Set("from", from)
+	if to != nil {
+		values.Set("to", *to)
+	}
+	u.RawQuery = values.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.ResultsSigner != nil {
+		if err := c.ResultsSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
@@ -59,10 +59,77 @@ type RawPayload struct {
 	ScanStartTime *time.Time `form:"scan_start_time,omitempty" json:"scan_start_time,omitempty" yaml:"scan_start_time,omitempty" xml:"scan_start_time,omitempty"`
 }
 
+// The status of a dependency of the service
+type readinessDependency struct {
+	// Whether a canary object was written, read and deleted
+	Canary *bool `form:"canary,omitempty" json:"canary,omitempty" yaml:"canary,omitempty" xml:"canary,omitempty"`
+	// Why the dependency is not ready
+	Error *string `form:"error,omitempty" json:"error,omitempty" yaml:"error,omitempty" xml:"error,omitempty"`
+	// Name of the dependency, like the bucket it is
+	Name *string `form:"name,omitempty" json:"name,omitempty" yaml:"name,omitempty" xml:"name,omitempty"`
+	// Whether the dependency can be accessed
+	Ready *bool `form:"ready,omitempty" json:"ready,omitempty" yaml:"ready,omitempty" xml:"ready,omitempty"`
+}
+
+// Validate validates the readinessDependency type instance.
+func (ut *readinessDependency) Validate() (err error) {
+	if ut.Name == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`request`, "name"))
+	}
+	if ut.Ready == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`request`, "ready"))
+	}
+	if ut.Canary == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`request`, "canary"))
+	}
+	return
+}
+
+// Publicize creates ReadinessDependency from readinessDependency
+func (ut *readinessDependency) Publicize() *ReadinessDependency {
+	var pub ReadinessDependency
+	if ut.Canary != nil {
+		pub.Canary = *ut.Canary
+	}
+	if ut.Error != nil {
+		pub.Error = ut.Error
+	}
+	if ut.Name != nil {
+		pub.Name = *ut.Name
+	}
+	if ut.Ready != nil {
+		pub.Ready = *ut.Ready
+	}
+	return &pub
+}
+
+// The status of a dependency of the service
+type ReadinessDependency struct {
+	// Whether a canary object was written, read and deleted
+	Canary bool `form:"canary" json:"canary" yaml:"canary" xml:"canary"`
+	// Why the dependency is not ready
+	Error *string `form:"error,omitempty" json:"error,omitempty" yaml:"error,omitempty" xml:"error,omitempty"`
+	// Name of the dependency, like the bucket it is
+	Name string `form:"name" json:"name" yaml:"name" xml:"name"`
+	// Whether the dependency can be accessed
+	Ready bool `form:"ready" json:"ready" yaml:"ready" xml:"ready"`
+}
+
+// Validate validates the ReadinessDependency type instance.
+func (ut *ReadinessDependency) Validate() (err error) {
+	if ut.Name == "" {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(`type`, "name"))
+	}
+
+	return
+}
+
 // reportPayload user type.
 type reportPayload struct {
 	// Check UUID
 	CheckID *uuid.UUID `form:"check_id,omitempty" json:"check_id,omitempty" yaml:"check_id,omitempty" xml:"check_id,omitempty"`
+	// Store the report even if the stored one ended later
+	Force *bool `form:"force,omitempty" json:"force,omitempty" yaml:"force,omitempty" xml:"force,omitempty"`
 	// Report of a Check. It's a JSON containing the value of the report
 	Report *string `form:"report,omitempty" json:"report,omitempty" yaml:"report,omitempty" xml:"report,omitempty"`
 	// Scan UUID
@@ -71,6 +138,14 @@ type reportPayload struct {
 	ScanStartTime *time.Time `form:"scan_start_time,omitempty" json:"scan_start_time,omitempty" yaml:"scan_start_time,omitempty" xml:"scan_start_time,omitempty"`
 }
 
+// Finalize sets the default values for reportPayload type instance.
+func (ut *reportPayload) Finalize() {
+	var defaultForce = false
+	if ut.Force == nil {
+		ut.Force = &defaultForce
+	}
+}
+
 // Validate validates the reportPayload type instance.
 func (ut *reportPayload) Validate() (err error) {
 	if ut.Report != nil {
@@ -92,6 +167,9 @@ func (ut *reportPayload) Publicize() *ReportPayload {
 	if ut.CheckID != nil {
 		pub.CheckID = ut.CheckID
 	}
+	if ut.Force != nil {
+		pub.Force = *ut.Force
+	}
 	if ut.Report != nil {
 		pub.Report = ut.Report
 	}
@@ -108,6 +186,8 @@ func (ut *reportPayload) Publicize() *ReportPayload {
 type ReportPayload struct {
 	// Check UUID
 	CheckID *uuid.UUID `form:"check_id,omitempty" json:"check_id,omitempty" yaml:"check_id,omitempty" xml:"check_id,omitempty"`
+	// Store the report even if the stored one ended later
+	Force bool `form:"force" json:"force" yaml:"force" xml:"force"`
 	// Report of a Check. It's a JSON containing the value of the report
 	Report *string `form:"report,omitempty" json:"report,omitempty" yaml:"report,omitempty" xml:"report,omitempty"`
 	// Scan UUID
@@ -7,15 +7,23 @@ Copyright 2019 Adevinta
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/goadesign/goa"
 	goalogrus "github.com/goadesign/goa/logging/logrus"
@@ -25,22 +33,88 @@ import (
 	vmetrics "github.com/adevinta/vulcan-metrics-client"
 	api "github.com/adevinta/vulcan-results"
 	"github.com/adevinta/vulcan-results/app"
+	"github.com/adevinta/vulcan-results/auth"
 	"github.com/adevinta/vulcan-results/metrics"
+	"github.com/adevinta/vulcan-results/mtls"
+	"github.com/adevinta/vulcan-results/spool"
 	"github.com/adevinta/vulcan-results/storage"
 )
 
+// defaultShutdownTimeout is the maximum time to wait for the requests
+// in flight when the service is stopped unless another one is
+// configured.
+const defaultShutdownTimeout = 30 * time.Second
+
+// defaultShutdownDelay is the time the service keeps accepting requests
+// after its probes start failing when it is stopped, unless another one
+// is configured. It covers the default period of the Kubernetes probes.
+const defaultShutdownDelay = 10 * time.Second
+
+// encryptionCheckTimeout is the maximum time spent checking the KMS keys
+// of the storage on startup.
+const encryptionCheckTimeout = 30 * time.Second
+
 //Config represents the configuration for vulcan-results
 type Config struct {
 	LogFile string
 	Port    int
 	Debug   bool
+	// MaxLogSize is the maximum size, in bytes, of the logs streamed
+	// to the service. If it is not set api.DefaultMaxLogSize is used.
+	MaxLogSize int64
+	// MaxReportSize is the maximum size, in bytes, of the HMAC signed
+	// reports and raw logs, which are buffered to verify their hash. If
+	// it is not set api.DefaultMaxReportSize is used.
+	MaxReportSize int64
+	// RedirectDownloads makes the report and log downloads redirect to
+	// a presigned URL unless the request sets redirect=false.
+	RedirectDownloads bool
+	// PresignedLinks makes the Location of the stored results a
+	// presigned URL instead of a link to the service.
+	PresignedLinks bool
+	// ShutdownTimeout is the maximum time to wait for the requests and
+	// uploads in flight when the service is stopped.
+	ShutdownTimeout time.Duration
+	// ShutdownDelay is the time the service keeps accepting requests
+	// after its probes start failing when it is stopped, so the load
+	// balancers stop routing requests to it first. It must be at least
+	// the period of the probes.
+	ShutdownDelay time.Duration
 
-	Storage storage.Config `toml:"Storage"`
-	Metrics metrics.Config `toml:"metrics"`
+	Auth      auth.Config     `toml:"Auth"`
+	TLS       mtls.Config     `toml:"TLS"`
+	Storage   storage.Config  `toml:"Storage"`
+	Readiness ReadinessConfig `toml:"Readiness"`
+	Spool     spool.Config    `toml:"Spool"`
+	Metrics   metrics.Config  `toml:"metrics"`
+}
+
+// ReadinessConfig represents the configuration of the readiness probe.
+type ReadinessConfig struct {
+	// Canary makes the probe also write, read back and delete an object
+	// in every bucket.
+	Canary bool
+	// CacheTTL is the time the result of the checks is reused for,
+	// api.DefaultReadinessCacheTTL if zero.
+	CacheTTL time.Duration
+	// Timeout is the maximum time spent checking the storage,
+	// api.DefaultReadinessTimeout if zero.
+	Timeout time.Duration
 }
 
 func main() {
-	config := mustReadConfig()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reconcile":
+			os.Exit(reconcile(os.Args[2:]))
+		case "rotate-keys":
+			os.Exit(rotateKeys(os.Args[2:]))
+		}
+	}
+	if len(os.Args) != 2 {
+		log.Fatalf("Usage: vulcan-results config_file\n%s\n\n%s", reconcileUsage, rotateKeysUsage)
+	}
+	config := mustReadConfig(os.Args[1])
 
 	// Setup the logger with Logrus. If no LogFile specified, Stderr will be used.
 	var lw io.Writer
@@ -74,55 +148,333 @@ func main() {
 	service := goa.New("vulcan-results")
 	service.WithLogger(goalogrus.FromEntry(logger))
 
-	// Create metrics client
-	metricsClient, err := vmetrics.NewClient()
-	if err != nil {
-		service.LogError("metrics client", "err", err)
-		panic(err)
+	// Create metrics client. The metrics are pushed to DogStatsD
+	// and, or, exposed to Prometheus.
+	var clients []vmetrics.Client
+	if config.Metrics.Enabled {
+		dd, err := vmetrics.NewClient()
+		if err != nil {
+			service.LogError("metrics client", "err", err)
+			panic(err)
+		}
+		clients = append(clients, dd)
 	}
+	var prom *metrics.PrometheusClient
+	if config.Metrics.Prometheus {
+		prom = metrics.NewPrometheusClient(map[string][]float64{
+			storage.MetricObjectSize:       metrics.SizeBuckets,
+			storage.MetricCompressionRatio: metrics.RatioBuckets,
+		})
+		clients = append(clients, prom)
+	}
+	metricsClient := metrics.NewMultiClient(clients...)
 
 	// Mount middleware
 	service.Use(middleware.RequestID())
+	if config.TLS.MutualTLS() {
+		// Attribute the requests, and their log lines, to the agents by
+		// their client certificates.
+		service.Use(mtls.ClientSubject())
+	}
 	service.Use(middleware.LogRequest(config.Debug == true))
 	service.Use(middleware.ErrorHandler(service, true))
+	service.Use(api.ErrorRequestID())
 	service.Use(middleware.Recover())
-	if config.Metrics.Enabled {
+	if metricsClient != nil {
 		service.Use(metrics.NewMiddleware(metricsClient))
 	}
 
+	// Mount the security middleware of the results endpoints. It must
+	// be mounted before the controllers are created.
+	var hmacAuthn *auth.HMACAuthenticator
+	if config.Auth.Enabled() {
+		authn, err := auth.New(config.Auth)
+		if err != nil {
+			service.LogError("auth", "err", err)
+			panic(err)
+		}
+		app.UseResultsMiddleware(service, auth.Middleware(authn))
+		hmacAuthn = authn.HMAC()
+	} else {
+		service.LogInfo("authentication disabled")
+		app.UseResultsMiddleware(service, auth.Anonymous())
+	}
+
+	// Load the policy that authorizes the access to the results of
+	// the scans.
+	var policy *auth.Policy
+	if config.Auth.PolicyFile != "" {
+		audit, err := auditLogger(config.Auth.AuditFile, logger)
+		if err != nil {
+			service.LogError("audit", "err", err)
+			panic(err)
+		}
+		policy, err = auth.LoadPolicy(config.Auth.PolicyFile, audit)
+		if err != nil {
+			service.LogError("policy", "err", err)
+			panic(err)
+		}
+	}
+
+	// Track the uploads in flight so they are waited for on shutdown.
+	drainer := api.NewDrainer()
+
 	// Mount "Results" controller
-	sess, err := session.NewSession(&aws.Config{Region: &config.Storage.Region})
+	st, err := newStorage(config.Storage, logger, metricsClient)
 	if err != nil {
-		service.LogError("aws session", "err", err)
+		service.LogError("storage", "err", err)
 		panic(err)
 	}
-	svc := s3.New(sess)
+	// The vulnerable reports are repaired by the backend, as both of
+	// their copies are stored as they are, encrypted or not.
+	repairer, _ := st.(storage.Repairer)
+	vulnerable, err := storedReportVulnerable(config.Storage)
+	if err != nil {
+		service.LogError("storage envelope", "err", err)
+		panic(err)
+	}
+	results, err := envelopeStorage(config.Storage, st)
+	if err != nil {
+		service.LogError("storage envelope", "err", err)
+		panic(err)
+	}
+	if err := checkPresign(config, results); err != nil {
+		service.LogError("presigned URLs", "err", err)
+		panic(err)
+	}
+
+	c := api.NewResultsController(service, results)
+	if config.MaxLogSize > 0 {
+		c.MaxLogSize = config.MaxLogSize
+	}
+	if config.MaxReportSize > 0 {
+		c.MaxReportSize = config.MaxReportSize
+	}
+	c.Redirect = config.RedirectDownloads
+	c.PresignedLinks = config.PresignedLinks
+	c.Policy = policy
+	c.Drainer = drainer
+	app.MountResultsController(service, c)
+
+	// Setup the spool where the results are kept while the storage is
+	// unavailable.
+	var worker *spool.Worker
+	if config.Spool.Dir != "" {
+		sp, err := spool.New(config.Spool.Dir)
+		if err != nil {
+			service.LogError("spool", "err", err)
+			panic(err)
+		}
+		c.Spool = sp
 
-	if len(config.Storage.Endpoint) > 0 {
-		svc = s3.New(sess, aws.NewConfig().WithEndpoint(config.Storage.Endpoint).WithS3ForcePathStyle(config.Storage.PathStyle))
+		worker = spool.NewWorker(config.Spool, sp, results, logger, metricsClient)
 	}
 
-	st := storage.NewS3Storage(config.Storage, logger, svc)
+	// Mount "checks" controller
+	c3 := api.NewChecksController(service, results)
+	c3.Policy = policy
+	app.MountChecksController(service, c3)
 
-	c := api.NewResultsController(service, st)
-	app.MountResultsController(service, c)
+	// Mount "scans" controller
+	c4 := api.NewScansController(service, results)
+	c4.Policy = policy
+	app.MountScansController(service, c4)
+
+	// Mount "links" controller
+	c5 := api.NewLinksController(service, results)
+	c5.Policy = policy
+	app.MountLinksController(service, c5)
 
-	// Healthcheck controller
-	c2 := api.NewHealthcheckController(service)
+	// Healthcheck controller. It checks the storage the results are
+	// stored in, and fails while the service is draining.
+	c2 := api.NewHealthcheckController(service, st)
+	c2.Drainer = drainer
 	app.MountHealthcheckController(service, c2)
 
+	// Readiness controller. It checks the buckets the results are
+	// stored in can be accessed.
+	c6 := api.NewReadinessController(service, st)
+	c6.Canary = config.Readiness.Canary
+	if config.Readiness.CacheTTL > 0 {
+		c6.CacheTTL = config.Readiness.CacheTTL
+	}
+	if config.Readiness.Timeout > 0 {
+		c6.Timeout = config.Readiness.Timeout
+	}
+	c6.Drainer = drainer
+	app.MountReadinessController(service, c6)
+
+	// Start spool worker
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		if worker != nil {
+			worker.Run(workerCtx)
+		}
+	}()
+
 	// Start service
-	if err := service.ListenAndServe(fmt.Sprintf(":%v", config.Port)); err != nil {
-		service.LogError("startup", "err", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Reload the policy when it changes.
+	if policy != nil {
+		go policy.Watch(ctx, config.Auth.PolicyReloadInterval, logger)
 	}
+
+	// Complete the writes of the vulnerable reports left unfinished.
+	if repairer != nil {
+		go storage.WatchRepairs(ctx, repairer, vulnerable, config.Storage.RepairInterval, logger)
+	}
+
+	// Serve the Prometheus metrics, on the listener of the service
+	// unless they have their own.
+	var metricsSrv *http.Server
+	if prom != nil {
+		if config.Metrics.PrometheusAddr == "" {
+			h := prom.Handler()
+			service.Mux.Handle(http.MethodGet, "/metrics", func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+				h.ServeHTTP(rw, req)
+			})
+		} else {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", prom.Handler())
+			metricsSrv = &http.Server{Addr: config.Metrics.PrometheusAddr, Handler: mux}
+			go func() {
+				service.LogInfo("listen", "transport", "http", "addr", metricsSrv.Addr, "metrics", "prometheus")
+				if err := metricsSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+					service.LogError("metrics listener", "err", err)
+				}
+			}()
+		}
+	}
+
+	addr := fmt.Sprintf(":%v", config.Port)
+	var handler http.Handler = service.Mux
+	if hmacAuthn != nil {
+		// The HMAC signatures cover the bodies through their hash,
+		// checked before the requests reach the controllers. The
+		// bodies are bounded by the size of the uploads of each
+		// action.
+		handler = hmacAuthn.VerifyContentHash(handler, c.MaxBodySize)
+	}
+	srv := &http.Server{Addr: addr, Handler: handler}
+	if config.TLS.Enabled() {
+		srv.TLSConfig, err = mtls.NewTLSConfig(config.TLS, logger)
+		if err != nil {
+			service.LogError("tls", "err", err)
+			panic(err)
+		}
+	}
+	errc := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			service.LogInfo("listen", "transport", "https", "addr", addr, "mtls", config.TLS.MutualTLS())
+			// The certificates are provided by the TLS configuration.
+			errc <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		service.LogInfo("listen", "transport", "http", "addr", addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, http.ErrServerClosed) {
+			service.LogError("startup", "err", err)
+		}
+	case <-ctx.Done():
+		timeout := config.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = defaultShutdownTimeout
+		}
+		delay := config.ShutdownDelay
+		if delay <= 0 {
+			delay = defaultShutdownDelay
+		}
+		service.LogInfo("shutdown", "delay", delay, "timeout", timeout, "uploads_in_flight", drainer.InFlight())
+		shutdown(srv, drainer, delay, timeout, service)
+	}
+
+	// Drain spool
+	stopWorker()
+	<-workerDone
+	if worker != nil {
+		if err := worker.Drain(context.Background()); err != nil {
+			service.LogError("spool drain", "err", err)
+		}
+	}
+
+	// Stop serving the Prometheus metrics once nothing else is recorded
+	// and send the buffered metrics before exiting.
+	if metricsSrv != nil {
+		metricsSrv.Close()
+	}
+	if metricsClient != nil {
+		if err := metrics.Flush(metricsClient); err != nil {
+			service.LogError("metrics flush", "err", err)
+		}
+	}
+	service.LogInfo("stopped")
 }
 
-func mustReadConfig() Config {
-	if len(os.Args) != 2 {
-		log.Fatalf("Usage: vulcan-results config_file")
+// shutdown drains the server: it makes the probes fail, keeps serving
+// for delay so the load balancers stop routing requests to it, and then
+// stops accepting connections and waits for the requests in flight, and
+// the uploads they are storing, to finish for at most timeout.
+func shutdown(srv interface{ Shutdown(context.Context) error }, drainer *api.Drainer, delay, timeout time.Duration, service *goa.Service) {
+	drainer.Drain()
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		service.LogError("shutdown", "err", err)
+	}
+	if err := drainer.Wait(ctx); err != nil {
+		service.LogError("uploads not finished", "err", err)
+	}
+}
+
+// newStorage returns the storage of the configured backend. The calls to
+// S3 are measured with mc, if not nil.
+func newStorage(c storage.Config, logger *logrus.Entry, mc vmetrics.Client) (storage.Storage, error) {
+	switch c.Backend {
+	case "", storage.BackendS3:
+		sess, err := session.NewSession(&aws.Config{Region: &c.Region})
+		if err != nil {
+			return nil, fmt.Errorf("aws session: %w", err)
+		}
+		awsConfig := aws.NewConfig()
+		if len(c.Endpoint) > 0 {
+			awsConfig = awsConfig.WithEndpoint(c.Endpoint).WithS3ForcePathStyle(c.PathStyle)
+		}
+		if c.Resilience.MaxRetries > 0 {
+			// The storage retries the calls itself.
+			awsConfig = awsConfig.WithMaxRetries(0)
+		}
+		svc := s3.New(sess, awsConfig)
+
+		// Fail now instead of on every upload if the encryption keys
+		// can not be used.
+		ctx, cancel := context.WithTimeout(context.Background(), encryptionCheckTimeout)
+		defer cancel()
+		if err := storage.CheckEncryption(ctx, c, kms.New(sess)); err != nil {
+			return nil, fmt.Errorf("storage encryption: %w", err)
+		}
+
+		st := storage.NewS3Storage(c, logger, svc)
+		st.Metrics = mc
+		return st, nil
+	case storage.BackendFilesystem:
+		return storage.NewFilesystemStorage(c, logger)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", c.Backend)
 	}
-	configFile := os.Args[1]
+}
 
+func mustReadConfig(configFile string) Config {
 	configData, err := ioutil.ReadFile(configFile)
 	if err != nil {
 		log.Fatalf("error: cannot read configuration file (%v)", err)
@@ -135,3 +487,57 @@ func mustReadConfig() Config {
 
 	return config
 }
+
+// envelopeStorage returns st wrapped in an EnvelopeStorage if the
+// envelope encryption is configured, and st otherwise.
+func envelopeStorage(c storage.Config, st storage.Storage) (storage.Storage, error) {
+	if c.Envelope.KeyFile == "" {
+		return st, nil
+	}
+	keys, err := storage.LoadKeyring(c.Envelope.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	est := storage.NewEnvelopeStorage(st, c, keys)
+	est.AllowPlaintext = c.Envelope.AllowPlaintext
+	return est, nil
+}
+
+// checkPresign fails if the downloads are redirected to presigned URLs,
+// or the stored results are linked with them, but the storage can not
+// presign URLs, as it happens with the filesystem backend and with the
+// envelope encryption. It also fails if the presigned URLs would not be
+// accepted by S3 for the configured expiry, so it is not found out when
+// they are handed out.
+func checkPresign(c Config, st storage.Storage) error {
+	expiry := c.Storage.PresignExpiry
+	if expiry == 0 {
+		expiry = storage.DefaultPresignExpiry
+	}
+	if expiry < 0 || expiry > storage.MaxPresignExpiry {
+		return fmt.Errorf("PresignExpiry must be positive and at most %s, got: %s", storage.MaxPresignExpiry, expiry)
+	}
+	if !c.RedirectDownloads && !c.PresignedLinks {
+		return nil
+	}
+	if _, ok := st.(storage.Presigner); !ok {
+		return errors.New("RedirectDownloads and PresignedLinks require the s3 storage backend without envelope encryption")
+	}
+	return nil
+}
+
+// auditLogger returns the logger of the audit stream. It writes JSON
+// lines to the file at path, or to the service log if path is empty.
+func auditLogger(path string, logger *logrus.Entry) (*logrus.Entry, error) {
+	if path == "" {
+		return logger.WithField("audit", true), nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit file: %w", err)
+	}
+	audit := logrus.New()
+	audit.Out = f
+	audit.Formatter = &logrus.JSONFormatter{}
+	return logrus.NewEntry(audit), nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/sirupsen/logrus"
+
+	api "github.com/adevinta/vulcan-results"
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+func TestCheckPresign(t *testing.T) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	keys, err := storage.NewKeyring(key)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s3st := storage.NewS3Storage(storagetest.Config, logrus.NewEntry(logrus.New()), storagetest.NewFakeS3())
+
+	testCases := []struct {
+		name    string
+		config  Config
+		st      storage.Storage
+		wantErr bool
+	}{
+		{"s3 redirect", Config{RedirectDownloads: true}, s3st, false},
+		{"s3 links", Config{PresignedLinks: true}, s3st, false},
+		{"envelope redirect", Config{RedirectDownloads: true}, storage.NewEnvelopeStorage(s3st, storagetest.Config, keys), true},
+		{"envelope links", Config{PresignedLinks: true}, storage.NewEnvelopeStorage(s3st, storagetest.Config, keys), true},
+		{"memory redirect", Config{RedirectDownloads: true}, storage.NewMemoryStorage(storagetest.Config), true},
+		{"envelope proxied", Config{}, storage.NewEnvelopeStorage(s3st, storagetest.Config, keys), false},
+		{"default expiry", Config{RedirectDownloads: true, Storage: storage.Config{PresignExpiry: 0}}, s3st, false},
+		{"max expiry", Config{RedirectDownloads: true, Storage: storage.Config{PresignExpiry: 7 * 24 * time.Hour}}, s3st, false},
+		{"expiry too long", Config{RedirectDownloads: true, Storage: storage.Config{PresignExpiry: 7*24*time.Hour + time.Second}}, s3st, true},
+		{"negative expiry", Config{PresignedLinks: true, Storage: storage.Config{PresignExpiry: -time.Minute}}, s3st, true},
+		{"expiry too long proxied", Config{Storage: storage.Config{PresignExpiry: 30 * 24 * time.Hour}}, s3st, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPresign(tc.config, tc.st)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// shutdownRecorder is a server that records when it is shut down and
+// whether the service was draining then.
+type shutdownRecorder struct {
+	drainer  *api.Drainer
+	at       time.Time
+	draining bool
+}
+
+func (r *shutdownRecorder) Shutdown(ctx context.Context) error {
+	r.at = time.Now()
+	r.draining = r.drainer.Draining()
+	return nil
+}
+
+func TestShutdown(t *testing.T) {
+	drainer := api.NewDrainer()
+	srv := &shutdownRecorder{drainer: drainer}
+	done := drainer.Track()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		done()
+	}()
+
+	const delay = 50 * time.Millisecond
+	start := time.Now()
+	shutdown(srv, drainer, delay, time.Second, goa.New("test"))
+
+	// The probes fail for the whole delay before the server stops
+	// accepting connections, and the uploads in flight are waited for.
+	if !srv.draining {
+		t.Fatalf("expected the service to be draining when the server is shut down")
+	}
+	if d := srv.at.Sub(start); d < delay {
+		t.Fatalf("expected the server to be shut down after %v, got: %v", delay, d)
+	}
+	if n := drainer.InFlight(); n != 0 {
+		t.Fatalf("expected no uploads in flight, got: %d", n)
+	}
+}
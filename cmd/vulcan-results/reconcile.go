@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	report "github.com/adevinta/vulcan-report"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+const reconcileUsage = `Usage: vulcan-results reconcile --from YYYY-MM-DD [--to YYYY-MM-DD] [--dry-run] config_file
+
+Makes the vulnerable reports bucket hold a copy of every vulnerable report
+stored in the reports bucket between the given days, and only of them.
+The vulnerable reports left unfinished by failed writes are repaired first.`
+
+// reconcile runs the reconcile command with the given arguments and
+// returns the exit code of the process.
+func reconcile(args []string) int {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	from := fs.String("from", "", "first day to reconcile (YYYY-MM-DD)")
+	to := fs.String("to", "", "last day to reconcile (YYYY-MM-DD), defaults to --from")
+	dryRun := fs.Bool("dry-run", false, "only report the changes that would be made")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), reconcileUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *from == "" {
+		fs.Usage()
+		return 2
+	}
+	if *to == "" {
+		*to = *from
+	}
+	fromDay, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: invalid --from: %v\n", err)
+		return 2
+	}
+	toDay, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: invalid --to: %v\n", err)
+		return 2
+	}
+
+	config := mustReadConfig(fs.Arg(0))
+
+	log := logrus.New()
+	log.Formatter = &logrus.TextFormatter{FullTimestamp: true}
+	if config.Debug {
+		log.Level = logrus.DebugLevel
+	}
+	logger := log.WithFields(logrus.Fields{
+		"app": "VULCAN-RESULTS",
+		"cmd": "reconcile",
+	})
+
+	st, err := newStorage(config.Storage, logger, nil)
+	if err != nil {
+		logger.WithError(err).Error("storage")
+		return 1
+	}
+	s3st, ok := st.(*storage.S3Storage)
+	if !ok {
+		logger.Error("reconcile is only supported by the s3 storage backend")
+		return 1
+	}
+
+	vulnerable, err := storedReportVulnerable(config.Storage)
+	if err != nil {
+		logger.WithError(err).Error("storage envelope")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !*dryRun {
+		n, err := s3st.RepairReports(ctx, storage.DefaultRepairMinAge, vulnerable)
+		if err != nil {
+			logger.WithError(err).Error("repairing reports")
+			return 1
+		}
+		logger.WithField("reports", n).Info("reports repaired")
+	}
+
+	summary, err := s3st.Reconcile(ctx, fromDay, toDay, storage.ReconcileOptions{
+		Vulnerable: vulnerable,
+		DryRun:     *dryRun,
+	})
+	printSummary(os.Stdout, summary, *dryRun)
+	if err != nil {
+		logger.WithError(err).Error("reconciling reports")
+		return 1
+	}
+	return 0
+}
+
+// storedReportVulnerable returns the function telling whether a report,
+// as stored in the storage with the given config, is vulnerable. The
+// reports are decrypted first if the storage uses envelope encryption.
+func storedReportVulnerable(c storage.Config) (storage.VulnerableFunc, error) {
+	envelope := c.Envelope
+	if envelope.KeyFile == "" {
+		return func(_ string, content []byte, _ map[string]string) (bool, error) {
+			return vulnerableReport(content)
+		}, nil
+	}
+	keys, err := storage.LoadKeyring(envelope.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return func(key string, content []byte, md map[string]string) (bool, error) {
+		report, err := keys.Decrypt(c.BucketReports, key, content, md)
+		if errors.Is(err, storage.ErrNotEncrypted) && envelope.AllowPlaintext {
+			report, err = content, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return vulnerableReport(report)
+	}, nil
+}
+
+// vulnerableReport tells whether a report, as stored by the service,
+// has vulnerabilities.
+func vulnerableReport(content []byte) (bool, error) {
+	r, err := parseReport(content)
+	if err != nil {
+		return false, err
+	}
+	return len(r.Vulnerabilities) > 0, nil
+}
+
+// parseReport parses a report as stored by the service.
+func parseReport(content []byte) (report.Report, error) {
+	var r report.Report
+	if err := r.UnmarshalJSONTimeAsString(content); err != nil {
+		// Reports stored before the times were stored as strings.
+		if jerr := json.Unmarshal(content, &r); jerr != nil {
+			return report.Report{}, err
+		}
+	}
+	return r, nil
+}
+
+// printSummary writes the summary of a reconciliation to w.
+func printSummary(w io.Writer, s storage.ReconcileSummary, dryRun bool) {
+	fmt.Fprintf(w, "reports checked: %d\n", s.Reports)
+	fmt.Fprintf(w, "invalid reports: %d\n", s.Invalid)
+	fmt.Fprintf(w, "copies created:  %d\n", s.Created)
+	fmt.Fprintf(w, "copies updated:  %d\n", s.Updated)
+	fmt.Fprintf(w, "copies deleted:  %d\n", s.Deleted)
+	if dryRun && s.Changes() > 0 {
+		fmt.Fprintln(w, "dry run: no changes were made")
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+const rotateKeysUsage = `Usage: vulcan-results rotate-keys --from YYYY-MM-DD [--to YYYY-MM-DD] config_file
+
+Wraps the data keys of the reports and logs stored between the given days
+with the primary master key of the keyfile. The ones stored before the
+envelope encryption was enabled are encrypted if AllowPlaintext is set in
+the envelope configuration, otherwise they make the command fail. Once
+every day is rotated, the old master keys can be removed from the keyfile.`
+
+// rotateKeys runs the rotate-keys command with the given arguments and
+// returns the exit code of the process.
+func rotateKeys(args []string) int {
+	fs := flag.NewFlagSet("rotate-keys", flag.ContinueOnError)
+	from := fs.String("from", "", "first day to rotate (YYYY-MM-DD)")
+	to := fs.String("to", "", "last day to rotate (YYYY-MM-DD), defaults to --from")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), rotateKeysUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *from == "" {
+		fs.Usage()
+		return 2
+	}
+	if *to == "" {
+		*to = *from
+	}
+	fromDay, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: invalid --from: %v\n", err)
+		return 2
+	}
+	toDay, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: invalid --to: %v\n", err)
+		return 2
+	}
+
+	config := mustReadConfig(fs.Arg(0))
+
+	log := logrus.New()
+	log.Formatter = &logrus.TextFormatter{FullTimestamp: true}
+	if config.Debug {
+		log.Level = logrus.DebugLevel
+	}
+	logger := log.WithFields(logrus.Fields{
+		"app": "VULCAN-RESULTS",
+		"cmd": "rotate-keys",
+	})
+
+	if config.Storage.Envelope.KeyFile == "" {
+		logger.Error("rotate-keys requires the envelope encryption to be configured")
+		return 1
+	}
+	st, err := newStorage(config.Storage, logger, nil)
+	if err != nil {
+		logger.WithError(err).Error("storage")
+		return 1
+	}
+	keys, err := storage.LoadKeyring(config.Storage.Envelope.KeyFile)
+	if err != nil {
+		logger.WithError(err).Error("storage envelope")
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	est := storage.NewEnvelopeStorage(st, config.Storage, keys)
+	est.AllowPlaintext = config.Storage.Envelope.AllowPlaintext
+	summary, err := est.Rotate(ctx, fromDay, toDay, storage.RotateOptions{
+		Inspect: inspectReport,
+	})
+	printRotateSummary(os.Stdout, summary)
+	if err != nil {
+		logger.WithError(err).Error("rotating keys")
+		return 1
+	}
+	return 0
+}
+
+// inspectReport returns whether a report, as stored by the service, has
+// vulnerabilities, and the options the service stores it with.
+func inspectReport(content []byte) (bool, storage.SaveOptions, error) {
+	r, err := parseReport(content)
+	if err != nil {
+		return false, storage.SaveOptions{}, err
+	}
+	sum := sha256.Sum256(content)
+	opts := storage.SaveOptions{
+		Checksum: hex.EncodeToString(sum[:]),
+		EndTime:  r.EndTime,
+	}
+	return len(r.Vulnerabilities) > 0, opts, nil
+}
+
+// printRotateSummary writes the summary of a key rotation to w.
+func printRotateSummary(w io.Writer, s storage.RotateSummary) {
+	fmt.Fprintf(w, "objects rewrapped: %d\n", s.Rewrapped)
+	fmt.Fprintf(w, "objects encrypted: %d\n", s.Encrypted)
+	fmt.Fprintf(w, "objects current:   %d\n", s.Current)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package design
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = Resource("checks", func() {
+	BasePath("/v1/scans/:date/:scan/checks")
+
+	Action("list", func() {
+		Routing(GET(""))
+		Security(ResultsAuth, func() {
+			Scope("results:read")
+		})
+		Description("List the reports and logs stored for the checks of a scan")
+		Params(func() {
+			Param("date", String, "Scan date (YYYY-MM-DD)", func() {
+				Pattern(`^\d{4}-\d{2}-\d{2}$`)
+			})
+			Param("scan", String, "Scan ID")
+			Param("next", String, "Token returned by a previous request to get the next page")
+			Param("limit", Integer, "Maximum number of checks to return", func() {
+				Minimum(1)
+				Maximum(1000)
+				Default(100)
+			})
+		})
+		Response(OK, CheckList)
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
+	})
+})
+
+var CheckObject = MediaType("application/vnd.vulcan.check-object+json", func() {
+	TypeName("CheckObject")
+	Description("A report or log stored for a check")
+	Attributes(func() {
+		Attribute("check_id", String, "Check ID")
+		Attribute("kind", String, "Kind of the object", func() {
+			Enum("report", "log")
+		})
+		Attribute("size", Integer, "Size of the object in bytes")
+		Attribute("last_modified", DateTime, "Last time the object was modified")
+		Required("check_id", "kind", "size", "last_modified")
+	})
+	View("default", func() {
+		Attribute("check_id")
+		Attribute("kind")
+		Attribute("size")
+		Attribute("last_modified")
+	})
+})
+
+var CheckList = MediaType("application/vnd.vulcan.check-list+json", func() {
+	TypeName("CheckList")
+	Description("A page of the reports and logs stored for the checks of a scan")
+	Attributes(func() {
+		Attribute("checks", CollectionOf(CheckObject), "Reports and logs of the checks")
+		Attribute("next", String, "Token to get the next page, empty if this is the last one")
+		Required("checks")
+	})
+	View("default", func() {
+		Attribute("checks")
+		Attribute("next")
+	})
+})
@@ -16,5 +16,6 @@ var _ = Resource("healthcheck", func() {
 		Routing(GET(""))
 		Description("Get the health status for the application")
 		Response(OK)
+		Response(ServiceUnavailable)
 	})
 })
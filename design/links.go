@@ -0,0 +1,70 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package design
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = Resource("links", func() {
+	BasePath("/v1/links")
+
+	Action("report", func() {
+		Routing(GET("/reports/:date/:scan/:check"))
+		Security(ResultsAuth, func() {
+			Scope("results:read")
+		})
+		Description("Get a time-limited URL to download a report directly from the storage")
+		Params(func() {
+			Param("date", String, "Report date")
+			Param("scan", String, "Scan ID")
+			Param("check", String, "Check ID")
+		})
+		Response(OK, PresignedLink)
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(NotFound, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(NotImplemented, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
+	})
+
+	Action("log", func() {
+		Routing(GET("/logs/:date/:scan/:check"))
+		Security(ResultsAuth, func() {
+			Scope("results:read")
+		})
+		Description("Get a time-limited URL to download a log directly from the storage")
+		Params(func() {
+			Param("date", String, "Report date")
+			Param("scan", String, "Scan ID")
+			Param("check", String, "Check ID")
+		})
+		Response(OK, PresignedLink)
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(NotFound, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(NotImplemented, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
+	})
+})
+
+var PresignedLink = MediaType("application/vnd.vulcan.presigned-link+json", func() {
+	TypeName("PresignedLink")
+	Description("A time-limited URL to download a report or a log")
+	Attributes(func() {
+		Attribute("url", String, "Presigned URL")
+		Attribute("expires_at", DateTime, "Time the URL expires")
+		Required("url", "expires_at")
+	})
+	View("default", func() {
+		Attribute("url")
+		Attribute("expires_at")
+	})
+})
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package design
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = Resource("readiness", func() {
+	BasePath("/readiness")
+
+	Action("show", func() {
+		Routing(GET(""))
+		Description("Get whether the service can access the storage the results are stored in")
+		Response(OK, Readiness)
+		Response(ServiceUnavailable, Readiness)
+	})
+})
+
+var Readiness = MediaType("application/vnd.vulcan.readiness+json", func() {
+	TypeName("Readiness")
+	Description("The readiness of the service and the status of its dependencies")
+	Attributes(func() {
+		Attribute("ready", Boolean, "Whether every dependency is ready")
+		Attribute("checked_at", DateTime, "Time the dependencies were checked")
+		Attribute("dependencies", ArrayOf(ReadinessDependency), "Status of the dependencies")
+		Required("ready", "checked_at", "dependencies")
+	})
+	View("default", func() {
+		Attribute("ready")
+		Attribute("checked_at")
+		Attribute("dependencies")
+	})
+})
+
+var ReadinessDependency = Type("ReadinessDependency", func() {
+	Description("The status of a dependency of the service")
+	Attribute("name", String, "Name of the dependency, like the bucket it is", func() {
+		Example("s3://vulcan-reports")
+	})
+	Attribute("ready", Boolean, "Whether the dependency can be accessed")
+	Attribute("canary", Boolean, "Whether a canary object was written, read and deleted")
+	Attribute("error", String, "Why the dependency is not ready")
+	Required("name", "ready", "canary")
+})
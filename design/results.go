@@ -14,44 +14,163 @@ var _ = Resource("Results", func() {
 
 	Action("report", func() {
 		Routing(POST("/report"))
-		Description("Update the Report of a Check")
+		Security(ResultsAuth, func() {
+			Scope("results:write")
+		})
+		Description(`Update the Report of a Check.
+Storing the same report again does not write it, and storing a report that ended before the stored one answers 409 Conflict unless force is true.
+Answers 202 Accepted if the storage is unavailable and the report was spooled to be stored later.`)
 		Payload(ReportPayload)
 		Response(Created)
-		Response(BadRequest)
+		Response(Accepted)
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(Conflict, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
 	})
 
 	// TODO: we should modify this endpoint from 'raw' to 'logs'
 	// for now, lets just keep it for compability reasons
 	Action("raw", func() {
 		Routing(POST("/raw"))
-		Description("Update the Raw of a Check")
+		Security(ResultsAuth, func() {
+			Scope("results:write")
+		})
+		Description(`Update the Raw of a Check.
+Answers 202 Accepted if the storage is unavailable and the logs were spooled to be stored later.`)
 		Payload(RawPayload)
 		Response(Created)
-		Response(BadRequest)
+		Response(Accepted)
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
+	})
+
+	Action("putLog", func() {
+		Routing(PUT("/logs/:date/:scan/:check"))
+		Security(ResultsAuth, func() {
+			Scope("results:write")
+		})
+		Description(`Upload the log of a check streaming the request body to the storage.
+The body is either the raw log or a multipart/form-data form with the log in the "log" field.`)
+		Params(func() {
+			Param("date", String, "Scan date partition (dt=YYYY-MM-DD)", func() {
+				Pattern(`^dt=\d{4}-\d{2}-\d{2}$`)
+			})
+			Param("scan", String, "Scan partition (scan=<scan ID>)", func() {
+				Pattern(`^scan=.+$`)
+			})
+			Param("check", String, "Log file name (<check ID>.log)", func() {
+				Pattern(`^.+\.log$`)
+			})
+		})
+		Response(Created)
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(RequestEntityTooLarge, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
 	})
 
 	Action("getReport", func() {
 		Routing(GET("/reports/:date/:scan/:check"))
-		Description("Download a report")
+		Security(ResultsAuth, func() {
+			Scope("results:read")
+		})
+		Description(`Download a report, or a range of it.
+With redirect, or if the service is configured to redirect by default, answers 302 Found with a time-limited URL to download it directly from the storage, if the storage supports it.`)
 		Params(func() {
 			Param("date", String, "Report date")
 			Param("scan", String, "Scan ID")
 			Param("check", String, "Check ID")
+			Param("redirect", Boolean, "Redirect to a presigned URL instead of returning the content")
+		})
+		Headers(func() {
+			Header("Range", String, "Single byte range to download, e.g. bytes=-4096 for the last 4KB")
+			Header("If-None-Match", String, "ETags of the cached versions of the object")
+			Header("If-Modified-Since", String, "Date of the cached version of the object")
 		})
-		Response(OK)
-		Response(BadRequest)
+		Response(OK, func() {
+			Headers(func() {
+				Header("ETag")
+				Header("Last-Modified")
+				Header("Cache-Control")
+			})
+		})
+		Response(PartialContent, func() {
+			Headers(func() {
+				Header("Content-Range")
+				Header("ETag")
+				Header("Last-Modified")
+				Header("Cache-Control")
+			})
+		})
+		Response(Found, func() {
+			Headers(func() {
+				Header("Location")
+			})
+		})
+		Response(NotModified)
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(NotFound, ErrorMedia)
+		Response(RequestedRangeNotSatisfiable, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
 	})
 
 	Action("getLog", func() {
 		Routing(GET("/logs/:date/:scan/:check"))
-		Description("Download a log")
+		Security(ResultsAuth, func() {
+			Scope("results:read")
+		})
+		Description(`Download a log, or a range of it.
+With redirect, or if the service is configured to redirect by default, answers 302 Found with a time-limited URL to download it directly from the storage, if the storage supports it.`)
 		Params(func() {
 			Param("date", String, "Report date")
 			Param("scan", String, "Scan ID")
 			Param("check", String, "Check ID")
+			Param("redirect", Boolean, "Redirect to a presigned URL instead of returning the content")
+		})
+		Headers(func() {
+			Header("Range", String, "Single byte range to download, e.g. bytes=-4096 for the last 4KB")
+			Header("If-None-Match", String, "ETags of the cached versions of the object")
+			Header("If-Modified-Since", String, "Date of the cached version of the object")
 		})
-		Response(OK)
-		Response(BadRequest)
+		Response(OK, func() {
+			Headers(func() {
+				Header("ETag")
+				Header("Last-Modified")
+				Header("Cache-Control")
+			})
+		})
+		Response(PartialContent, func() {
+			Headers(func() {
+				Header("Content-Range")
+				Header("ETag")
+				Header("Last-Modified")
+				Header("Cache-Control")
+			})
+		})
+		Response(Found, func() {
+			Headers(func() {
+				Header("Location")
+			})
+		})
+		Response(NotModified)
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(NotFound, ErrorMedia)
+		Response(RequestedRangeNotSatisfiable, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
 	})
 })
 
@@ -65,6 +184,9 @@ var ReportPayload = Type("ReportPayload", func() {
 		Pattern("^[[:print:]]+")
 		Description("Report of a Check. It's a JSON containing the value of the report")
 	})
+	Attribute("force", Boolean, "Store the report even if the stored one ended later", func() {
+		Default(false)
+	})
 })
 
 var RawPayload = Type("RawPayload", func() {
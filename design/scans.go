@@ -0,0 +1,55 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package design
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = Resource("scans", func() {
+	BasePath("/v1/scans")
+
+	Action("list", func() {
+		Routing(GET(""))
+		Security(ResultsAuth, func() {
+			Scope("results:read")
+		})
+		Description("List the scans that stored reports in a range of dates")
+		Params(func() {
+			Param("from", String, "First date of the range (YYYY-MM-DD)", func() {
+				Pattern(`^\d{4}-\d{2}-\d{2}$`)
+			})
+			Param("to", String, "Last date of the range (YYYY-MM-DD), defaults to from", func() {
+				Pattern(`^\d{4}-\d{2}-\d{2}$`)
+			})
+			Required("from")
+		})
+		Response(OK, CollectionOf(ScanSummary))
+		Response(BadRequest, ErrorMedia)
+		Response(Unauthorized, ErrorMedia)
+		Response(Forbidden, ErrorMedia)
+		Response(InternalServerError, ErrorMedia)
+		Response(ServiceUnavailable, ErrorMedia)
+	})
+})
+
+var ScanSummary = MediaType("application/vnd.vulcan.scan-summary+json", func() {
+	TypeName("ScanSummary")
+	Description("Summary of the reports stored for a scan")
+	Attributes(func() {
+		Attribute("scan_id", String, "Scan ID")
+		Attribute("date", String, "Date the scan started (YYYY-MM-DD)")
+		Attribute("checks", Integer, "Number of checks with a report")
+		Attribute("vulnerable", Boolean, "Whether any of the reports has vulnerabilities")
+		Required("scan_id", "date", "checks", "vulnerable")
+	})
+	View("default", func() {
+		Attribute("scan_id")
+		Attribute("date")
+		Attribute("checks")
+		Attribute("vulnerable")
+	})
+})
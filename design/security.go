@@ -0,0 +1,22 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package design
+
+import (
+	. "github.com/goadesign/goa/design/apidsl"
+)
+
+// ResultsAuth secures the actions that upload and download results.
+var ResultsAuth = JWTSecurity("results", func() {
+	Description(`The Authorization header carries one of:
+- a static token: "Bearer <token>"
+- a JWT signed with a key of the configured JWKS: "Bearer <jwt>"
+- an HMAC-SHA256 signature: "HMAC-SHA256 KeyId=<id>, Signature=<hex signature>"
+
+HMAC signatures are computed over the method, the path and query, the X-Vulcan-Timestamp header (Unix seconds) and the X-Content-SHA256 header (hex SHA-256 of the body), joined by new lines.`)
+	Header("Authorization")
+	Scope("results:write", "Upload reports and logs")
+	Scope("results:read", "Download reports and logs")
+})
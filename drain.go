@@ -0,0 +1,98 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Drainer tracks the uploads in flight so the service can wait for them
+// to be stored before it stops. Once it starts draining the healthcheck
+// fails, so no more requests are routed to the service.
+type Drainer struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	idle     chan struct{}
+}
+
+// NewDrainer returns a Drainer.
+func NewDrainer() *Drainer {
+	return &Drainer{}
+}
+
+// Track registers an upload in flight and returns the function to call
+// when it finishes. It is safe to call on a nil Drainer.
+func (d *Drainer) Track() (done func()) {
+	if d == nil {
+		return func() {}
+	}
+	d.mu.Lock()
+	d.inFlight++
+	d.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.inFlight--
+			if d.inFlight == 0 && d.idle != nil {
+				close(d.idle)
+				d.idle = nil
+			}
+		})
+	}
+}
+
+// Drain makes the Drainer report the service is draining.
+func (d *Drainer) Drain() {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+}
+
+// Draining tells whether the service is draining. It is safe to call on
+// a nil Drainer.
+func (d *Drainer) Draining() bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// InFlight returns the number of uploads in flight.
+func (d *Drainer) InFlight() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.inFlight
+}
+
+// Wait waits until there are no uploads in flight or ctx is done. In
+// the latter case it returns an error with the number of uploads that
+// did not finish.
+func (d *Drainer) Wait(ctx context.Context) error {
+	d.mu.Lock()
+	if d.inFlight == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%d uploads in flight: %w", d.InFlight(), ctx.Err())
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrainer(t *testing.T) {
+	d := NewDrainer()
+	if err := d.Wait(context.Background()); err != nil {
+		t.Fatalf("expected no error without uploads in flight, got: %v", err)
+	}
+
+	first, second := d.Track(), d.Track()
+	d.Drain()
+	if !d.Draining() {
+		t.Fatalf("expected the drainer to be draining")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := d.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected error %v, got: %v", context.DeadlineExceeded, err)
+	}
+
+	first()
+	first() // Finishing twice is a no-op.
+	if n := d.InFlight(); n != 1 {
+		t.Fatalf("expected 1 upload in flight, got %d", n)
+	}
+	go second()
+	if err := d.Wait(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestNilDrainer(t *testing.T) {
+	var d *Drainer
+	d.Track()()
+	if d.Draining() {
+		t.Fatalf("expected a nil drainer not to be draining")
+	}
+}
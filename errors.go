@@ -0,0 +1,218 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+
+	"github.com/adevinta/vulcan-results/auth"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// Error classes of the error documents returned by the API.
+var (
+	errMissingFields = goa.NewErrorClass("missing_fields", http.StatusBadRequest)
+	errInvalidField  = goa.NewErrorClass("invalid_field", http.StatusBadRequest)
+	errInvalidKey    = goa.NewErrorClass("invalid_key", http.StatusBadRequest)
+	errInvalidToken  = goa.NewErrorClass("invalid_token", http.StatusBadRequest)
+	errNotFound      = goa.NewErrorClass("not_found", http.StatusNotFound)
+	errForbidden     = goa.NewErrorClass("forbidden", http.StatusForbidden)
+	errTooLarge      = goa.NewErrorClass("too_large", http.StatusRequestEntityTooLarge)
+	errConflict      = goa.NewErrorClass("conflict", http.StatusConflict)
+	errRange         = goa.NewErrorClass("range_not_satisfiable", http.StatusRequestedRangeNotSatisfiable)
+	errInternal      = goa.NewErrorClass("internal", http.StatusInternalServerError)
+	errUnsupported   = goa.NewErrorClass("not_implemented", http.StatusNotImplemented)
+	errUnavailable   = goa.NewErrorClass("storage_unavailable", http.StatusServiceUnavailable)
+)
+
+// payloadError is returned when the payload of a request is not valid.
+// It holds the fields of the payload that caused the error.
+type payloadError struct {
+	class  goa.ErrorClass
+	detail string
+	fields []string
+}
+
+func (e *payloadError) Error() string {
+	return e.detail
+}
+
+// missingFieldsError returns a payloadError enumerating all the required
+// fields missing from a payload.
+func missingFieldsError(fields ...string) *payloadError {
+	return &payloadError{
+		class:  errMissingFields,
+		detail: "missing required fields: " + strings.Join(fields, ", "),
+		fields: fields,
+	}
+}
+
+// invalidFieldError returns a payloadError for a field of a payload with
+// an invalid value.
+func invalidFieldError(field string, err error) *payloadError {
+	return &payloadError{
+		class:  errInvalidField,
+		detail: "invalid " + field + ": " + err.Error(),
+		fields: []string{field},
+	}
+}
+
+// newErrorResponse builds an error document of the given class annotated
+// with the fields that caused it, if any, and the ID of the request.
+func newErrorResponse(ctx context.Context, class goa.ErrorClass, detail string, fields ...string) error {
+	var keyvals []interface{}
+	if len(fields) > 0 {
+		keyvals = append(keyvals, "fields", fields)
+	}
+	if id := middleware.ContextRequestID(ctx); id != "" {
+		keyvals = append(keyvals, "request_id", id)
+	}
+	return class(detail, keyvals...)
+}
+
+// errorResponder is implemented by the contexts of all the actions that
+// access the storage.
+type errorResponder interface {
+	context.Context
+	BadRequest(error) error
+	InternalServerError(error) error
+	ServiceUnavailable(error) error
+}
+
+// accessResponder is implemented by the contexts of the actions that
+// access the results of a scan on behalf of a principal.
+type accessResponder interface {
+	errorResponder
+	Forbidden(error) error
+}
+
+// uploadError sends the response that corresponds to an error returned
+// while uploading a result.
+func uploadError(r accessResponder, err error) error {
+	var perr *payloadError
+	switch {
+	case errors.As(err, &perr):
+		return r.BadRequest(newErrorResponse(r, perr.class, perr.detail, perr.fields...))
+	case errors.Is(err, auth.ErrForbidden):
+		return r.Forbidden(newErrorResponse(r, errForbidden, err.Error()))
+	case errors.Is(err, storage.ErrUnavailable):
+		return r.ServiceUnavailable(newErrorResponse(r, errUnavailable, "storage is temporarily unavailable"))
+	default:
+		return r.InternalServerError(newErrorResponse(r, errInternal, "the result could not be stored"))
+	}
+}
+
+// downloadResponder is implemented by the contexts of the actions that
+// download results from the storage.
+type downloadResponder interface {
+	accessResponder
+	NotFound(error) error
+	NotModified() error
+	RequestedRangeNotSatisfiable(error) error
+}
+
+// downloadError sends the response that corresponds to an error returned
+// by the storage while downloading a result. Conditional downloads not
+// performed because the result was not modified are reported as errors
+// by the storage too.
+func downloadError(r downloadResponder, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrForbidden):
+		return r.Forbidden(newErrorResponse(r, errForbidden, err.Error()))
+	case errors.Is(err, storage.ErrNotModified):
+		return r.NotModified()
+	case errors.Is(err, storage.ErrNotFound):
+		return r.NotFound(newErrorResponse(r, errNotFound, "the requested result does not exist"))
+	case errors.Is(err, storage.ErrRangeNotSatisfiable):
+		return r.RequestedRangeNotSatisfiable(newErrorResponse(r, errRange, err.Error(), "range"))
+	case errors.Is(err, storage.ErrInvalidKey):
+		return r.BadRequest(newErrorResponse(r, errInvalidKey, err.Error(), "date", "scan", "check"))
+	case errors.Is(err, storage.ErrUnavailable):
+		return r.ServiceUnavailable(newErrorResponse(r, errUnavailable, "storage is temporarily unavailable"))
+	default:
+		return r.InternalServerError(newErrorResponse(r, errInternal, "the result could not be retrieved"))
+	}
+}
+
+// errPresignUnsupported is returned when a presigned URL is requested
+// but the storage can not presign URLs.
+var errPresignUnsupported = errors.New("the storage does not support presigned URLs")
+
+// linkResponder is implemented by the contexts of the actions that
+// return presigned URLs.
+type linkResponder interface {
+	accessResponder
+	NotFound(error) error
+	NotImplemented(error) error
+}
+
+// linkError sends the response that corresponds to an error returned
+// while presigning the URL of a result.
+func linkError(r linkResponder, err error) error {
+	switch {
+	case errors.Is(err, auth.ErrForbidden):
+		return r.Forbidden(newErrorResponse(r, errForbidden, err.Error()))
+	case errors.Is(err, errPresignUnsupported):
+		return r.NotImplemented(newErrorResponse(r, errUnsupported, err.Error()))
+	case errors.Is(err, storage.ErrNotFound):
+		return r.NotFound(newErrorResponse(r, errNotFound, "the requested result does not exist"))
+	case errors.Is(err, storage.ErrInvalidKey):
+		return r.BadRequest(newErrorResponse(r, errInvalidKey, err.Error(), "date", "scan", "check"))
+	case errors.Is(err, storage.ErrUnavailable):
+		return r.ServiceUnavailable(newErrorResponse(r, errUnavailable, "storage is temporarily unavailable"))
+	default:
+		return r.InternalServerError(newErrorResponse(r, errInternal, "the link could not be generated"))
+	}
+}
+
+// listError sends the response that corresponds to an error returned by
+// the storage while listing results.
+func listError(r accessResponder, err error) error {
+	var perr *payloadError
+	switch {
+	case errors.As(err, &perr):
+		return r.BadRequest(newErrorResponse(r, perr.class, perr.detail, perr.fields...))
+	case errors.Is(err, auth.ErrForbidden):
+		return r.Forbidden(newErrorResponse(r, errForbidden, err.Error()))
+	case errors.Is(err, storage.ErrInvalidKey):
+		return r.BadRequest(newErrorResponse(r, errInvalidKey, err.Error(), "date", "scan"))
+	case errors.Is(err, storage.ErrInvalidToken):
+		return r.BadRequest(newErrorResponse(r, errInvalidToken, err.Error(), "next"))
+	case errors.Is(err, storage.ErrInvalidRange):
+		return r.BadRequest(newErrorResponse(r, errInvalidField, err.Error(), "from", "to"))
+	case errors.Is(err, storage.ErrUnavailable):
+		return r.ServiceUnavailable(newErrorResponse(r, errUnavailable, "storage is temporarily unavailable"))
+	default:
+		return r.InternalServerError(newErrorResponse(r, errInternal, "the results could not be listed"))
+	}
+}
+
+// ErrorRequestID returns a middleware that adds the ID of the request to
+// the error documents built by goa, for instance when a payload can not
+// be decoded, so every error returned by the API can be correlated with
+// the service logs. It must be mounted after the ErrorHandler middleware.
+func ErrorRequestID() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			err := h(ctx, rw, req)
+			var eresp *goa.ErrorResponse
+			if errors.As(err, &eresp) {
+				if id := middleware.ContextRequestID(ctx); id != "" {
+					if eresp.Meta == nil {
+						eresp.Meta = map[string]interface{}{}
+					}
+					eresp.Meta["request_id"] = id
+				}
+			}
+			return err
+		}
+	}
+}
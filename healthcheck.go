@@ -5,21 +5,53 @@ Copyright 2019 Adevinta
 package api
 
 import (
+	"encoding/json"
+
 	"github.com/goadesign/goa"
+
 	"github.com/adevinta/vulcan-results/app"
+	"github.com/adevinta/vulcan-results/storage"
 )
 
 // HealthcheckController implements the healthcheck resource.
 type HealthcheckController struct {
 	*goa.Controller
+	storage storage.Storage
+
+	// Drainer, if not nil, makes the healthcheck fail while the service
+	// is draining before it stops.
+	Drainer *Drainer
 }
 
 // NewHealthcheckController creates a healthcheck controller.
-func NewHealthcheckController(service *goa.Service) *HealthcheckController {
-	return &HealthcheckController{Controller: service.NewController("HealthcheckController")}
+func NewHealthcheckController(service *goa.Service, s storage.Storage) *HealthcheckController {
+	return &HealthcheckController{
+		Controller: service.NewController("HealthcheckController"),
+		storage:    s,
+	}
+}
+
+// health is the document returned by the healthcheck.
+type health struct {
+	Storage storage.Health `json:"storage"`
 }
 
-// Show runs the show action.
+// Show runs the show action. The service is healthy as long as it
+// runs and is not draining, but if the storage tracks the health of its
+// backend it is reported, so the state of the circuit breaker around S3
+// can be checked.
 func (c *HealthcheckController) Show(ctx *app.ShowHealthcheckContext) error {
-	return ctx.OK([]byte{})
+	if c.Drainer.Draining() {
+		return ctx.ServiceUnavailable()
+	}
+	hr, ok := c.storage.(storage.HealthReporter)
+	if !ok {
+		return ctx.OK([]byte{})
+	}
+	resp, err := json.Marshal(health{Storage: hr.Health()})
+	if err != nil {
+		return err
+	}
+	ctx.ResponseData.Header().Set("Content-Type", "application/json")
+	return ctx.OK(resp)
 }
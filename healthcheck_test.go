@@ -0,0 +1,60 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app/test"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// healthStorage is a storage that reports the health of its backend.
+type healthStorage struct {
+	storageMock
+	health storage.Health
+}
+
+func (s healthStorage) Health() storage.Health {
+	return s.health
+}
+
+func TestHealthcheck(t *testing.T) {
+	service := goa.New("vulcan-results")
+
+	rw := test.ShowHealthcheckOK(t, nil, service, NewHealthcheckController(service, storageMock{}))
+	if body := rw.(*httptest.ResponseRecorder).Body.String(); body != "" {
+		t.Fatalf("expected an empty body, got: %q", body)
+	}
+
+	st := healthStorage{health: storage.Health{CircuitBreaker: "open", ConsecutiveFailures: 5}}
+	rw = test.ShowHealthcheckOK(t, nil, service, NewHealthcheckController(service, st))
+	if ct := rw.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected Content-Type: %s", ct)
+	}
+	var got struct {
+		Storage storage.Health `json:"storage"`
+	}
+	if err := json.Unmarshal(rw.(*httptest.ResponseRecorder).Body.Bytes(), &got); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got.Storage != st.health {
+		t.Fatalf("expected health %+v, got: %+v", st.health, got.Storage)
+	}
+}
+
+func TestHealthcheckDraining(t *testing.T) {
+	service := goa.New("vulcan-results")
+	c := NewHealthcheckController(service, storageMock{})
+	c.Drainer = NewDrainer()
+
+	test.ShowHealthcheckOK(t, nil, service, c)
+	c.Drainer.Drain()
+	test.ShowHealthcheckServiceUnavailable(t, nil, service, c)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app"
+	"github.com/adevinta/vulcan-results/auth"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// LinksController implements the links resource.
+type LinksController struct {
+	*goa.Controller
+	storage storage.Storage
+
+	// Policy, if not nil, authorizes the principals to download the
+	// results of the scans.
+	Policy *auth.Policy
+}
+
+// NewLinksController creates a links controller.
+func NewLinksController(service *goa.Service, s storage.Storage) *LinksController {
+	return &LinksController{Controller: service.NewController("LinksController"), storage: s}
+}
+
+// Report runs the report action.
+func (c *LinksController) Report(ctx *app.ReportLinksContext) error {
+	goa.LogInfo(ctx, "Presigning report", "date", ctx.Date, "scan", ctx.Scan, "check", ctx.Check)
+
+	tag := reportTag(ctx, c.storage, ctx.Date, ctx.Scan, ctx.Check)
+	if err := c.Policy.AuthorizeRead(ctx, scanAccess(ctx.Scan, ctx.Check), tag); err != nil {
+		goa.LogError(ctx, err.Error())
+		return linkError(ctx, err)
+	}
+
+	p, ok := c.storage.(storage.Presigner)
+	if !ok {
+		return linkError(ctx, errPresignUnsupported)
+	}
+
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
+	u, err := p.PresignReport(sctx, ctx.Date, ctx.Scan, ctx.Check)
+	if err != nil {
+		goa.LogError(ctx, err.Error())
+		return linkError(ctx, err)
+	}
+	return ctx.OK(&app.PresignedLink{URL: u.URL, ExpiresAt: u.Expires})
+}
+
+// Log runs the log action.
+func (c *LinksController) Log(ctx *app.LogLinksContext) error {
+	goa.LogInfo(ctx, "Presigning log", "date", ctx.Date, "scan", ctx.Scan, "check", ctx.Check)
+
+	tag := reportTag(ctx, c.storage, ctx.Date, ctx.Scan, ctx.Check)
+	if err := c.Policy.AuthorizeRead(ctx, scanAccess(ctx.Scan, ctx.Check), tag); err != nil {
+		goa.LogError(ctx, err.Error())
+		return linkError(ctx, err)
+	}
+
+	p, ok := c.storage.(storage.Presigner)
+	if !ok {
+		return linkError(ctx, errPresignUnsupported)
+	}
+
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
+	u, err := p.PresignLog(sctx, ctx.Date, ctx.Scan, ctx.Check)
+	if err != nil {
+		goa.LogError(ctx, err.Error())
+		return linkError(ctx, err)
+	}
+	return ctx.OK(&app.PresignedLink{URL: u.URL, ExpiresAt: u.Expires})
+}
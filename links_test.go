@@ -0,0 +1,106 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app/test"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// presignerMock is a storageMock that presigns URLs. The URLs are the
+// date, scan and check appended to url.
+type presignerMock struct {
+	storageMock
+
+	url     string
+	expires time.Time
+}
+
+func (st presignerMock) PresignReport(ctx context.Context, date, scanID, checkID string) (storage.PresignedURL, error) {
+	return st.presign(date, scanID, checkID)
+}
+
+func (st presignerMock) PresignLog(ctx context.Context, date, scanID, checkID string) (storage.PresignedURL, error) {
+	return st.presign(date, scanID, checkID)
+}
+
+func (st presignerMock) presign(date, scanID, checkID string) (storage.PresignedURL, error) {
+	if st.err != nil {
+		return storage.PresignedURL{}, st.err
+	}
+	return storage.PresignedURL{URL: fmt.Sprintf("%s/%s/%s/%s", st.url, date, scanID, checkID), Expires: st.expires}, nil
+}
+
+func TestReportLink(t *testing.T) {
+	expires := time.Date(2019, time.November, 1, 10, 15, 0, 0, time.UTC)
+	st := presignerMock{url: "https://reports.s3", expires: expires}
+
+	service := goa.New("vulcan-results")
+	ctrl := NewLinksController(service, st)
+
+	_, link := test.ReportLinksOK(t, nil, service, ctrl, "dt=2019-11-01", "scan="+scanID.String(), checkID.String()+".json")
+	want := "https://reports.s3/dt=2019-11-01/scan=" + scanID.String() + "/" + checkID.String() + ".json"
+	if link.URL != want {
+		t.Fatalf("expected URL %s, got: %s", want, link.URL)
+	}
+	if !link.ExpiresAt.Equal(expires) {
+		t.Fatalf("expected expiry time %v, got: %v", expires, link.ExpiresAt)
+	}
+}
+
+func TestLogLinkErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		st   storage.Storage
+		code string
+		fErr func(t *testing.T, ctrl *LinksController, service *goa.Service) error
+	}{
+		{
+			name: "not found",
+			st:   presignerMock{storageMock: storageMock{err: fmt.Errorf("%w: NotFound", storage.ErrNotFound)}},
+			code: "not_found",
+			fErr: func(t *testing.T, ctrl *LinksController, service *goa.Service) error {
+				_, err := test.LogLinksNotFound(t, nil, service, ctrl, "dt=2019-11-01", scanID.String(), checkID.String()+".log")
+				return err
+			},
+		},
+		{
+			name: "storage unavailable",
+			st:   presignerMock{storageMock: storageMock{err: fmt.Errorf("%w: SlowDown", storage.ErrUnavailable)}},
+			code: "storage_unavailable",
+			fErr: func(t *testing.T, ctrl *LinksController, service *goa.Service) error {
+				_, err := test.LogLinksServiceUnavailable(t, nil, service, ctrl, "dt=2019-11-01", scanID.String(), checkID.String()+".log")
+				return err
+			},
+		},
+		{
+			name: "presign not supported",
+			st:   storageMock{},
+			code: "not_implemented",
+			fErr: func(t *testing.T, ctrl *LinksController, service *goa.Service) error {
+				_, err := test.LogLinksNotImplemented(t, nil, service, ctrl, "dt=2019-11-01", scanID.String(), checkID.String()+".log")
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			service := goa.New("vulcan-results")
+			ctrl := NewLinksController(service, tc.st)
+
+			err := tc.fErr(t, ctrl, service)
+			checkErrorCode(t, err, tc.code)
+		})
+	}
+}
@@ -4,8 +4,84 @@ Copyright 2019 Adevinta
 
 package metrics
 
+import (
+	"errors"
+	"time"
+
+	metrics "github.com/adevinta/vulcan-metrics-client"
+)
+
 // Config represents the metrics configuration.
 // parameters.
 type Config struct {
+	// Enabled enables pushing the metrics to DogStatsD.
 	Enabled bool
+	// Prometheus enables exposing the metrics to be scraped by
+	// Prometheus at /metrics.
+	Prometheus bool `toml:"prometheus"`
+	// PrometheusAddr, if not empty, is the address of a separate plain
+	// HTTP listener /metrics is served on instead of the listener of
+	// the service.
+	PrometheusAddr string `toml:"prometheus_addr"`
+}
+
+// flushWait is the time given to a client that can not be flushed to
+// send the metrics it buffers. The DogStatsD client sends them every
+// 100ms.
+const flushWait = 200 * time.Millisecond
+
+// flusher is a metrics client that can send the metrics it buffers on
+// demand.
+type flusher interface {
+	Flush() error
+}
+
+// Flush sends the metrics buffered by c, so they are not lost when the
+// service stops. The clients of vulcan-metrics-client can not be flushed,
+// so for them it waits until they send the buffered metrics.
+func Flush(c metrics.Client) error {
+	if f, ok := c.(flusher); ok {
+		return f.Flush()
+	}
+	time.Sleep(flushWait)
+	return nil
+}
+
+// multiClient is a metrics client that pushes the metrics to several
+// clients.
+type multiClient []metrics.Client
+
+// NewMultiClient returns a client that pushes the metrics to all the
+// given clients, or nil if there are none.
+func NewMultiClient(clients ...metrics.Client) metrics.Client {
+	switch len(clients) {
+	case 0:
+		return nil
+	case 1:
+		return clients[0]
+	}
+	return multiClient(clients)
+}
+
+func (m multiClient) Push(metric metrics.Metric) {
+	for _, c := range m {
+		c.Push(metric)
+	}
+}
+
+func (m multiClient) PushWithRate(metric metrics.RatedMetric) {
+	for _, c := range m {
+		c.PushWithRate(metric)
+	}
+}
+
+// Flush flushes all the clients.
+func (m multiClient) Flush() error {
+	var errs []error
+	for _, c := range m {
+		if err := Flush(c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,40 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+type flushClient struct {
+	mockMetricsClient
+	flushed bool
+}
+
+func (c *flushClient) Flush() error {
+	c.flushed = true
+	return nil
+}
+
+func TestFlush(t *testing.T) {
+	fc := &flushClient{}
+	if err := Flush(fc); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !fc.flushed {
+		t.Fatalf("expected the client to be flushed")
+	}
+
+	// Clients that can not be flushed are given time to send the
+	// buffered metrics.
+	start := time.Now()
+	if err := Flush(&mockMetricsClient{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < flushWait {
+		t.Fatalf("expected to wait %v for the client, waited %v", flushWait, elapsed)
+	}
+}
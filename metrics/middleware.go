@@ -21,12 +21,21 @@ const (
 	postReportPathPrefix = "/v1/report"
 	getLogPathPrefix     = "/v1/logs"
 	postLogPathPrefix    = "/v1/raw"
+	scansPathPrefix      = "/v1/scans"
+	checksPathSuffix     = "/checks"
+	reportLinkPathPrefix = "/v1/links/reports"
+	logLinkPathPrefix    = "/v1/links/logs"
 
 	// Endpoint actions
 	postReportAction = "PostReport"
 	getReportAction  = "GetReport"
 	postLogAction    = "PostLog"
 	getLogAction     = "GetLog"
+	putLogAction     = "PutLog"
+	listChecksAction = "ListChecks"
+	listScansAction  = "ListScans"
+	reportLinkAction = "GetReportLink"
+	logLinkAction    = "GetLogLink"
 
 	unknownAction = "unknown"
 
@@ -46,6 +55,8 @@ const (
 
 	reportEntity = "report"
 	logEntity    = "log"
+	checkEntity  = "check"
+	scanEntity   = "scan"
 )
 
 var (
@@ -54,6 +65,11 @@ var (
 		getReportAction:  reportEntity,
 		postLogAction:    logEntity,
 		getLogAction:     logEntity,
+		putLogAction:     logEntity,
+		listChecksAction: checkEntity,
+		listScansAction:  scanEntity,
+		reportLinkAction: reportEntity,
+		logLinkAction:    logEntity,
 	}
 )
 
@@ -61,8 +77,8 @@ var (
 func NewMiddleware(metricsClient metrics.Client) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
-			// Do not push metrics for healtchcheck
-			if req.URL.Path == "/healthcheck" {
+			// Do not push metrics for healtchcheck and readiness
+			if req.URL.Path == "/healthcheck" || req.URL.Path == "/readiness" {
 				return h(ctx, rw, req)
 			}
 
@@ -138,6 +154,22 @@ func parseAction(httpMethod, path string) string {
 		if strings.HasPrefix(path, getLogPathPrefix) {
 			return getLogAction
 		}
+		if strings.HasPrefix(path, scansPathPrefix) && strings.HasSuffix(path, checksPathSuffix) {
+			return listChecksAction
+		}
+		if strings.TrimSuffix(path, "/") == scansPathPrefix {
+			return listScansAction
+		}
+		if strings.HasPrefix(path, reportLinkPathPrefix) {
+			return reportLinkAction
+		}
+		if strings.HasPrefix(path, logLinkPathPrefix) {
+			return logLinkAction
+		}
+	} else if httpMethod == http.MethodPut {
+		if strings.HasPrefix(path, getLogPathPrefix) {
+			return putLogAction
+		}
 	} else if httpMethod == http.MethodPost {
 		if strings.HasPrefix(path, postReportPathPrefix) {
 			return postReportAction
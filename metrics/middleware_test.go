@@ -332,3 +332,28 @@ func TestMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAction(t *testing.T) {
+	testCases := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{http.MethodGet, "/v1/reports/dt=2020-06-01/scan=id/check.json", getReportAction},
+		{http.MethodPost, "/v1/report", postReportAction},
+		{http.MethodGet, "/v1/logs/dt=2020-06-01/scan=id/check.log", getLogAction},
+		{http.MethodPost, "/v1/raw", postLogAction},
+		{http.MethodPut, "/v1/logs/dt=2020-06-01/scan=id/check.log", putLogAction},
+		{http.MethodGet, "/v1/scans/2020-06-01/06b38973-f395-4311-a4af-8f36e1b5b847/checks", listChecksAction},
+		{http.MethodGet, "/v1/scans", listScansAction},
+		{http.MethodGet, "/v1/links/reports/dt=2020-06-01/scan=id/check.json", reportLinkAction},
+		{http.MethodGet, "/v1/links/logs/dt=2020-06-01/scan=id/check.log", logLinkAction},
+		{http.MethodDelete, "/v1/report", unknownAction},
+	}
+
+	for _, tc := range testCases {
+		if got := parseAction(tc.method, tc.path); got != tc.expected {
+			t.Errorf("%s %s: expected action %s, got: %s", tc.method, tc.path, tc.expected, got)
+		}
+	}
+}
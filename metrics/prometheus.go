@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	metrics "github.com/adevinta/vulcan-metrics-client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultBuckets are the buckets of the Prometheus histograms unless
+// others are set for the metric. They suit durations in milliseconds,
+// from 1ms to about 30s.
+var DefaultBuckets = prometheus.ExponentialBuckets(1, 2, 16)
+
+// SizeBuckets suit sizes in bytes, from 256B to 64MB.
+var SizeBuckets = prometheus.ExponentialBuckets(256, 4, 10)
+
+// RatioBuckets suit compression ratios, from 1 to about 38.
+var RatioBuckets = prometheus.ExponentialBuckets(1, 1.5, 10)
+
+// PrometheusClient is a metrics client that exposes the metrics pushed
+// to it to be scraped by Prometheus. The names of the metrics have
+// their dots replaced by underscores, and their tags become labels:
+// vulcan.request.total with the tag action:GetReport is exposed as
+// vulcan_request_total{action="GetReport"}. Counts are counters, gauges
+// are gauges, and histograms and distributions are histograms.
+//
+// The labels of a metric are the tag names of the first time it is
+// pushed. Later pushes of the metric ignore other tags and leave the
+// missing labels empty.
+type PrometheusClient struct {
+	registry *prometheus.Registry
+	buckets  map[string][]float64
+
+	mu      sync.Mutex
+	metrics map[string]*promMetric
+}
+
+// promMetric is a metric registered in the Prometheus registry.
+type promMetric struct {
+	labels    []string
+	collector prometheus.Collector
+}
+
+// NewPrometheusClient returns a PrometheusClient that also exposes the
+// metrics of the Go runtime and the process. The histograms of the
+// metrics in buckets, by their original name, use the given buckets
+// instead of DefaultBuckets.
+func NewPrometheusClient(buckets map[string][]float64) *PrometheusClient {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return &PrometheusClient{
+		registry: registry,
+		buckets:  buckets,
+		metrics:  map[string]*promMetric{},
+	}
+}
+
+// Push records a metric.
+func (c *PrometheusClient) Push(m metrics.Metric) {
+	c.record(m.Name, m.Typ, m.Value, m.Tags)
+}
+
+// PushWithRate records a metric. The rate is ignored, as the metrics
+// are not sampled.
+func (c *PrometheusClient) PushWithRate(m metrics.RatedMetric) {
+	c.Push(m.Metric)
+}
+
+// Flush does nothing, as the metrics are scraped instead of sent.
+func (c *PrometheusClient) Flush() error {
+	return nil
+}
+
+// Handler returns the handler that serves the metrics to Prometheus.
+func (c *PrometheusClient) Handler() http.Handler {
+	return promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{})
+}
+
+func (c *PrometheusClient) record(name string, typ metrics.Type, value float64, tags []string) {
+	tagValues := parseTags(tags)
+
+	c.mu.Lock()
+	m, ok := c.metrics[name]
+	if !ok {
+		m = c.register(name, typ, tagValues)
+		c.metrics[name] = m
+	}
+	c.mu.Unlock()
+	if m == nil {
+		return
+	}
+
+	values := make([]string, len(m.labels))
+	for i, l := range m.labels {
+		values[i] = tagValues[l]
+	}
+	switch col := m.collector.(type) {
+	case *prometheus.CounterVec:
+		if value >= 0 {
+			col.WithLabelValues(values...).Add(value)
+		}
+	case *prometheus.GaugeVec:
+		col.WithLabelValues(values...).Set(value)
+	case *prometheus.HistogramVec:
+		col.WithLabelValues(values...).Observe(value)
+	}
+}
+
+// register registers the collector of a metric with the labels of the
+// given tags. It returns nil if the metric can not be registered, so
+// the metric is ignored.
+func (c *PrometheusClient) register(name string, typ metrics.Type, tags map[string]string) *promMetric {
+	var labels []string
+	for l := range tags {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	promName := sanitize(name)
+	var col prometheus.Collector
+	switch typ {
+	case metrics.Count:
+		col = prometheus.NewCounterVec(prometheus.CounterOpts{Name: promName, Help: name}, labels)
+	case metrics.Gauge:
+		col = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: promName, Help: name}, labels)
+	case metrics.Histogram, metrics.Distribution:
+		buckets, ok := c.buckets[name]
+		if !ok {
+			buckets = DefaultBuckets
+		}
+		col = prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: promName, Help: name, Buckets: buckets}, labels)
+	default:
+		return nil
+	}
+	if err := c.registry.Register(col); err != nil {
+		return nil
+	}
+	return &promMetric{labels: labels, collector: col}
+}
+
+// parseTags returns the values of DogStatsD tags, with the format
+// name:value, by their names converted to valid label names.
+func parseTags(tags []string) map[string]string {
+	values := map[string]string{}
+	for _, t := range tags {
+		name, value, _ := strings.Cut(t, ":")
+		values[sanitize(name)] = value
+	}
+	return values
+}
+
+// sanitize converts a DogStatsD metric or tag name to a valid Prometheus
+// name.
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
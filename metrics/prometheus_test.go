@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package metrics
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metrics "github.com/adevinta/vulcan-metrics-client"
+	"github.com/goadesign/goa"
+)
+
+// scrape returns the metrics exposed by c.
+func scrape(t *testing.T, c *PrometheusClient) string {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := ioutil.ReadAll(rw.Body)
+	if err != nil {
+		t.Fatalf("reading metrics: %v", err)
+	}
+	return string(body)
+}
+
+func TestPrometheusMiddleware(t *testing.T) {
+	c := NewPrometheusClient(nil)
+	h := NewMiddleware(c)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		goa.ContextResponse(ctx).Status = http.StatusNotFound
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/logs/dt=2020-06-01/scan=id/check.log", nil)
+	for i := 0; i < 2; i++ {
+		if err := h(goa.NewContext(nil, nil, nil, nil), httptest.NewRecorder(), req); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+
+	labels := `{action="GetLog",component="results",entity="log",method="GET",status="404"}`
+	body := scrape(t, c)
+	for _, want := range []string{
+		"vulcan_request_total" + labels + " 2",
+		"vulcan_request_failed" + labels + " 2",
+		"vulcan_request_duration_count" + labels + " 2",
+		`vulcan_request_duration_bucket{action="GetLog",component="results",entity="log",method="GET",status="404",le="+Inf"} 2`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the metrics, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestPrometheusLabels(t *testing.T) {
+	c := NewPrometheusClient(map[string][]float64{"vulcan.size": {10, 100}})
+	c.Push(metrics.Metric{Name: "vulcan.size", Typ: metrics.Histogram, Value: 50, Tags: []string{"bucket:logs", "op:put"}})
+	// Unknown tags are ignored and missing ones are left empty.
+	c.Push(metrics.Metric{Name: "vulcan.size", Typ: metrics.Histogram, Value: 5, Tags: []string{"bucket:logs", "other:x"}})
+	c.PushWithRate(metrics.RatedMetric{Metric: metrics.Metric{Name: "vulcan.open-uploads", Typ: metrics.Gauge, Value: 3}, Rate: 0.5})
+
+	body := scrape(t, c)
+	for _, want := range []string{
+		`vulcan_size_bucket{bucket="logs",op="put",le="100"} 1`,
+		`vulcan_size_bucket{bucket="logs",op="",le="10"} 1`,
+		"vulcan_open_uploads 3",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the metrics, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestMultiClient(t *testing.T) {
+	if c := NewMultiClient(); c != nil {
+		t.Fatalf("expected no client, got: %v", c)
+	}
+	a, b := &mockMetricsClient{}, &mockMetricsClient{}
+	m := metrics.Metric{Name: metricTotal, Typ: metrics.Count, Value: 1}
+	NewMultiClient(a, b).Push(m)
+	if len(a.metrics) != 1 || len(b.metrics) != 1 {
+		t.Fatalf("expected the metric to be pushed to both clients, got: %v, %v", a.metrics, b.metrics)
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+// Package mtls configures the TLS listener of the service, optionally
+// requiring the agents to present a client certificate, and reloads the
+// certificates when they are rotated.
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultReloadInterval is the minimum interval between checks of the
+// certificate files for changes unless another one is configured.
+const DefaultReloadInterval = time.Minute
+
+// Client authentication modes.
+const (
+	ClientAuthRequire       = "require"
+	ClientAuthVerifyIfGiven = "verify-if-given"
+)
+
+// Config represents the TLS configuration. TLS is disabled if CertFile
+// and KeyFile are empty.
+type Config struct {
+	// CertFile and KeyFile are the PEM encoded certificate, with its
+	// chain, and key of the service.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds the PEM encoded CAs the client certificates are
+	// verified with. Client certificates are not requested if it is
+	// empty.
+	ClientCAFile string
+	// ClientAuth is ClientAuthRequire, the default, to require a client
+	// certificate, or ClientAuthVerifyIfGiven to only verify it if the
+	// client presents one.
+	ClientAuth string
+	// MinVersion is the minimum TLS version accepted, "1.2", the
+	// default, or "1.3".
+	MinVersion string
+	// ReloadInterval is the minimum interval between checks of the
+	// certificate files for changes, DefaultReloadInterval if zero.
+	ReloadInterval time.Duration
+}
+
+// Enabled tells whether TLS is configured.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// MutualTLS tells whether client certificates are requested.
+func (c Config) MutualTLS() bool {
+	return c.ClientCAFile != ""
+}
+
+// NewTLSConfig returns the TLS configuration of a server configured by
+// c. The certificate and the client CAs are loaded again, on the next
+// handshake, when their files change.
+func NewTLSConfig(c Config, logger *logrus.Entry) (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, fmt.Errorf("both CertFile and KeyFile are required")
+	}
+	// The configurations returned by GetConfigForClient replace the one
+	// the HTTP server adds its protocols to, so they must offer them
+	// too for HTTP/2 to be negotiated.
+	base := &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+	switch c.MinVersion {
+	case "", "1.2":
+		base.MinVersion = tls.VersionTLS12
+	case "1.3":
+		base.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported TLS version %q", c.MinVersion)
+	}
+	if c.MutualTLS() {
+		switch c.ClientAuth {
+		case "", ClientAuthRequire:
+			base.ClientAuth = tls.RequireAndVerifyClientCert
+		case ClientAuthVerifyIfGiven:
+			base.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("unknown client auth %q", c.ClientAuth)
+		}
+	}
+
+	r := &reloader{conf: c, base: base, logger: logger, now: time.Now}
+	if c.ReloadInterval <= 0 {
+		r.conf.ReloadInterval = DefaultReloadInterval
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:         base.MinVersion,
+		NextProtos:         base.NextProtos,
+		GetConfigForClient: r.configForClient,
+	}, nil
+}
+
+// reloader holds the certificates of a TLS configuration, loading them
+// again when their files change.
+type reloader struct {
+	conf   Config
+	base   *tls.Config
+	logger *logrus.Entry
+	now    func() time.Time
+
+	mu        sync.Mutex
+	current   *tls.Config
+	modTimes  []time.Time
+	checkedAt time.Time
+}
+
+// files returns the files the configuration is loaded from.
+func (r *reloader) files() []string {
+	files := []string{r.conf.CertFile, r.conf.KeyFile}
+	if r.conf.MutualTLS() {
+		files = append(files, r.conf.ClientCAFile)
+	}
+	return files
+}
+
+// load loads the certificates from their files.
+func (r *reloader) load() error {
+	var modTimes []time.Time
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return fmt.Errorf("loading TLS certificates: %w", err)
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.conf.CertFile, r.conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	conf := r.base.Clone()
+	conf.Certificates = []tls.Certificate{cert}
+	if r.conf.MutualTLS() {
+		pem, err := os.ReadFile(r.conf.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("loading client CAs: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("loading client CAs: no certificate found in %s", r.conf.ClientCAFile)
+		}
+		conf.ClientCAs = pool
+	}
+
+	r.current = conf
+	r.modTimes = modTimes
+	return nil
+}
+
+// modified tells whether any of the files changed since they were
+// loaded.
+func (r *reloader) modified() bool {
+	for i, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil || !info.ModTime().Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// configForClient returns the current configuration, reloading it
+// first if the files changed. The current configuration is kept if the
+// new files can not be loaded, for instance while they are being
+// replaced.
+func (r *reloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.checkedAt) < r.conf.ReloadInterval {
+		return r.current, nil
+	}
+	r.checkedAt = now
+	if !r.modified() {
+		return r.current, nil
+	}
+	if err := r.load(); err != nil {
+		r.logger.WithError(err).Error("TLS certificates not reloaded")
+		return r.current, nil
+	}
+	r.logger.Info("TLS certificates reloaded")
+	return r.current, nil
+}
+
+type clientSubjectKey struct{}
+
+// WithClientSubject returns a copy of ctx holding the subject of the
+// client certificate of its request.
+func WithClientSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, clientSubjectKey{}, subject)
+}
+
+// ContextClientSubject returns the subject of the verified client
+// certificate of the request of ctx, or an empty string if the client
+// did not present one.
+func ContextClientSubject(ctx context.Context) string {
+	s, _ := ctx.Value(clientSubjectKey{}).(string)
+	return s
+}
+
+// ClientSubject returns a middleware that stores the subject of the
+// verified client certificate of the requests in their context, see
+// ContextClientSubject, and adds it to their log context, so the
+// uploads can be attributed to the agents that made them.
+func ClientSubject() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if req.TLS != nil && len(req.TLS.VerifiedChains) > 0 {
+				subject := req.TLS.VerifiedChains[0][0].Subject.String()
+				ctx = WithClientSubject(ctx, subject)
+				ctx = goa.WithLogContext(ctx, "client_cert", subject)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testCert is a certificate with its key.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert returns a certificate for the given common name signed by
+// parent, or self-signed if parent is nil.
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("generating serial: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn, OrganizationalUnit: []string{"agents"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing certificate: %v", err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// write writes the certificate and its key in PEM files modified at the
+// given time.
+func (c *testCert) write(t *testing.T, certFile, keyFile string, modTime time.Time) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("marshaling key: %v", err)
+	}
+	files := map[string][]byte{
+		certFile: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}),
+		keyFile:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+	for path, data := range files {
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("setting modification time of %s: %v", path, err)
+		}
+	}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestClientSubject(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	ca.write(t, filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca.key"), time.Now())
+	server := newTestCert(t, "server", ca)
+	server.write(t, filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"), time.Now())
+	agent := newTestCert(t, "agent-1", ca)
+	other := newTestCert(t, "agent-2", newTestCert(t, "other-ca", nil))
+
+	conf, err := NewTLSConfig(Config{
+		CertFile:     filepath.Join(dir, "server.pem"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+		MinVersion:   "1.3",
+	}, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var subject string
+	h := ClientSubject()(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		subject = ContextClientSubject(ctx)
+		return nil
+	})
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if err := h(req.Context(), rw, req); err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	srv.TLS = conf
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs ...tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+		}}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	if err := get(agent.tlsCertificate()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if want := "CN=agent-1,OU=agents"; subject != want {
+		t.Fatalf("expected client subject %q, got %q", want, subject)
+	}
+	if err := get(); err == nil {
+		t.Fatalf("expected error without a client certificate, got none")
+	}
+	if err := get(other.tlsCertificate()); err == nil {
+		t.Fatalf("expected error with an untrusted client certificate, got none")
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	modTime := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	first := newTestCert(t, "first", nil)
+	first.write(t, certFile, keyFile, modTime)
+
+	now := time.Now()
+	r := &reloader{
+		conf:   Config{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Minute},
+		base:   &tls.Config{},
+		logger: logrus.NewEntry(logrus.New()),
+		now:    func() time.Time { return now },
+	}
+	if err := r.load(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	served := func() string {
+		t.Helper()
+		conf, err := r.configForClient(nil)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		cert, err := x509.ParseCertificate(conf.Certificates[0].Certificate[0])
+		if err != nil {
+			t.Fatalf("parsing certificate: %v", err)
+		}
+		return cert.Subject.CommonName
+	}
+	if cn := served(); cn != "first" {
+		t.Fatalf("expected certificate %q, got %q", "first", cn)
+	}
+
+	// The rotated certificate is served once the reload interval
+	// passes.
+	second := newTestCert(t, "second", nil)
+	second.write(t, certFile, keyFile, modTime.Add(time.Minute))
+	if cn := served(); cn != "first" {
+		t.Fatalf("expected certificate %q before the reload interval, got %q", "first", cn)
+	}
+	now = now.Add(time.Minute)
+	if cn := served(); cn != "second" {
+		t.Fatalf("expected certificate %q, got %q", "second", cn)
+	}
+
+	// An invalid certificate keeps the current one.
+	if err := os.WriteFile(certFile, []byte("invalid"), 0600); err != nil {
+		t.Fatalf("writing certificate: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if cn := served(); cn != "second" {
+		t.Fatalf("expected certificate %q, got %q", "second", cn)
+	}
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	newTestCert(t, "server", nil).write(t, certFile, keyFile, time.Now())
+
+	testCases := []struct {
+		name string
+		conf Config
+	}{
+		{name: "no key", conf: Config{CertFile: certFile}},
+		{name: "unsupported version", conf: Config{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.1"}},
+		{name: "unknown client auth", conf: Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile, ClientAuth: "optional"}},
+		{name: "no client CAs", conf: Config{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile}},
+		{name: "missing certificate", conf: Config{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewTLSConfig(tc.conf, logrus.NewEntry(logrus.New())); err == nil {
+				t.Fatalf("expected error, got none")
+			}
+		})
+	}
+}
+
+func TestHTTP2AfterReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	first := newTestCert(t, "first", nil)
+	first.write(t, certFile, keyFile, time.Now().Add(-time.Hour))
+
+	conf, err := NewTLSConfig(Config{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ReloadInterval: time.Nanosecond,
+	}, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	srv.EnableHTTP2 = true
+	srv.TLS = conf
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func(ca *testCert) *http.Response {
+		t.Helper()
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots},
+			ForceAttemptHTTP2: true,
+		}}
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := get(first); resp.ProtoMajor != 2 {
+		t.Fatalf("expected HTTP/2, got: %s", resp.Proto)
+	}
+	// The reloaded certificates keep offering HTTP/2.
+	second := newTestCert(t, "second", nil)
+	second.write(t, certFile, keyFile, time.Now())
+	resp := get(second)
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("expected HTTP/2 after the reload, got: %s", resp.Proto)
+	}
+	if cn := resp.TLS.PeerCertificates[0].Subject.CommonName; cn != "second" {
+		t.Fatalf("expected certificate %q, got %q", "second", cn)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// Default values of the ReadinessController fields.
+const (
+	DefaultReadinessCacheTTL = 10 * time.Second
+	DefaultReadinessTimeout  = 5 * time.Second
+)
+
+// ReadinessController implements the readiness resource.
+type ReadinessController struct {
+	*goa.Controller
+	storage storage.Storage
+
+	// Canary makes the checks also write, read back and delete an
+	// object in every bucket.
+	Canary bool
+	// CacheTTL is the time the result of the checks is reused for, so
+	// frequent probes do not hit the storage on every request.
+	CacheTTL time.Duration
+	// Timeout is the maximum time spent checking the storage.
+	Timeout time.Duration
+	// Drainer, if not nil, makes the service not ready while it is
+	// draining before it stops.
+	Drainer *Drainer
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	last      *app.Readiness
+}
+
+// NewReadinessController creates a readiness controller.
+func NewReadinessController(service *goa.Service, s storage.Storage) *ReadinessController {
+	return &ReadinessController{
+		Controller: service.NewController("ReadinessController"),
+		storage:    s,
+		CacheTTL:   DefaultReadinessCacheTTL,
+		Timeout:    DefaultReadinessTimeout,
+	}
+}
+
+// Show runs the show action. The service is ready when it can access
+// every bucket, or its equivalent, the results are stored in.
+func (c *ReadinessController) Show(ctx *app.ShowReadinessContext) error {
+	if c.Drainer.Draining() {
+		return ctx.ServiceUnavailable(&app.Readiness{
+			CheckedAt:    time.Now(),
+			Dependencies: []*app.ReadinessDependency{},
+		})
+	}
+
+	r := c.readiness(ctx)
+	if !r.Ready {
+		return ctx.ServiceUnavailable(r)
+	}
+	return ctx.OK(r)
+}
+
+// readiness returns the result of the last checks if it is recent
+// enough, and checks the storage again otherwise. Concurrent requests
+// wait for the same checks.
+func (c *ReadinessController) readiness(ctx context.Context) *app.Readiness {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.checkedAt) < c.CacheTTL {
+		return c.last
+	}
+
+	r := &app.Readiness{Ready: true, Dependencies: []*app.ReadinessDependency{}}
+	if rc, ok := c.storage.(storage.ReadinessChecker); ok {
+		// The checks are not bound to the request, as their result is
+		// shared with the next ones.
+		cctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+		statuses := rc.CheckReadiness(cctx, c.Canary)
+		cancel()
+
+		for _, st := range statuses {
+			dep := &app.ReadinessDependency{Name: st.Name, Ready: st.Err == nil, Canary: st.Canary}
+			if st.Err != nil {
+				msg := st.Err.Error()
+				dep.Error = &msg
+				r.Ready = false
+				goa.LogError(ctx, "dependency not ready", "dependency", st.Name, "err", st.Err)
+			}
+			r.Dependencies = append(r.Dependencies, dep)
+		}
+	}
+	r.CheckedAt = time.Now()
+
+	c.last, c.checkedAt = r, r.CheckedAt
+	return r
+}
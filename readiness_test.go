@@ -0,0 +1,93 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app/test"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// readinessStorage is a storage that checks its readiness.
+type readinessStorage struct {
+	storageMock
+	statuses []storage.DependencyStatus
+	checks   *int
+	canary   *bool
+}
+
+func (s readinessStorage) CheckReadiness(ctx context.Context, canary bool) []storage.DependencyStatus {
+	*s.checks++
+	*s.canary = canary
+	return s.statuses
+}
+
+func TestReadiness(t *testing.T) {
+	service := goa.New("vulcan-results")
+
+	// Storages that can not be checked are always ready.
+	_, r := test.ShowReadinessOK(t, nil, service, NewReadinessController(service, storageMock{}))
+	if !r.Ready || len(r.Dependencies) != 0 {
+		t.Fatalf("unexpected readiness: %+v", r)
+	}
+
+	var checks int
+	var canary bool
+	st := readinessStorage{
+		statuses: []storage.DependencyStatus{
+			{Name: "s3://reports", Canary: true},
+			{Name: "s3://logs", Canary: true},
+		},
+		checks: &checks,
+		canary: &canary,
+	}
+	c := NewReadinessController(service, st)
+	c.Canary = true
+	_, r = test.ShowReadinessOK(t, nil, service, c)
+	if !r.Ready || len(r.Dependencies) != 2 || !canary {
+		t.Fatalf("unexpected readiness: %+v", r)
+	}
+	for _, dep := range r.Dependencies {
+		if !dep.Ready || !dep.Canary || dep.Error != nil {
+			t.Fatalf("unexpected dependency status: %+v", dep)
+		}
+	}
+
+	// The result is cached.
+	st.statuses[1].Err = errors.New("access denied")
+	test.ShowReadinessOK(t, nil, service, c)
+	if checks != 1 {
+		t.Fatalf("expected the storage to be checked once, got %d", checks)
+	}
+
+	c.CacheTTL = 0
+	_, r = test.ShowReadinessServiceUnavailable(t, nil, service, c)
+	if checks != 2 {
+		t.Fatalf("expected the storage to be checked again, got %d checks", checks)
+	}
+	if r.Ready || r.Dependencies[0].Error != nil {
+		t.Fatalf("unexpected readiness: %+v", r)
+	}
+	if dep := r.Dependencies[1]; dep.Ready || dep.Error == nil || *dep.Error != "access denied" {
+		t.Fatalf("unexpected dependency status: %+v", dep)
+	}
+}
+
+func TestReadinessDraining(t *testing.T) {
+	service := goa.New("vulcan-results")
+	c := NewReadinessController(service, storageMock{})
+	c.Drainer = NewDrainer()
+	c.Drainer.Drain()
+
+	_, r := test.ShowReadinessServiceUnavailable(t, nil, service, c)
+	if r.Ready {
+		t.Fatalf("expected the service not to be ready while draining")
+	}
+}
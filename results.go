@@ -6,15 +6,27 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	report "github.com/adevinta/vulcan-report"
 	"github.com/adevinta/vulcan-results/app"
+	"github.com/adevinta/vulcan-results/auth"
+	"github.com/adevinta/vulcan-results/spool"
 	"github.com/adevinta/vulcan-results/storage"
 	"github.com/goadesign/goa"
+	uuid "github.com/gofrs/uuid"
 )
 
 type Check struct {
@@ -34,43 +46,156 @@ type CheckPatchLogs struct {
 	Raw *string `json:"raw"`
 }
 
+// DefaultMaxLogSize is the maximum size, in bytes, of the logs uploaded
+// with the putLog action unless another one is configured.
+const DefaultMaxLogSize = 1 << 30
+
+// DefaultMaxReportSize is the maximum size, in bytes, of the signed
+// bodies of the report and raw actions unless another one is
+// configured.
+const DefaultMaxReportSize = 32 << 20
+
 // ResultsController implements the Results resource.
 type ResultsController struct {
 	*goa.Controller
 	storage storage.Storage
+
+	// MaxLogSize is the maximum size, in bytes, of the logs uploaded
+	// with the putLog action.
+	MaxLogSize int64
+	// MaxReportSize is the maximum size, in bytes, of the signed bodies
+	// of the report and raw actions, which are buffered to verify them.
+	MaxReportSize int64
+	// Spool, if not nil, keeps the reports and logs that can not be
+	// stored because the storage is unavailable, so they are stored
+	// later instead of being lost.
+	Spool *spool.Spool
+	// Redirect makes the getReport and getLog actions redirect to a
+	// presigned URL unless the request sets redirect=false.
+	Redirect bool
+	// PresignedLinks makes the Location returned when a result is
+	// stored a presigned URL instead of a link to the service.
+	PresignedLinks bool
+	// Policy, if not nil, authorizes the principals to upload and
+	// download the results of the scans.
+	Policy *auth.Policy
+	// Drainer, if not nil, tracks the uploads in flight so they can be
+	// waited for when the service stops.
+	Drainer *Drainer
 }
 
 // NewResultsController creates a Results controller.
 func NewResultsController(service *goa.Service, s storage.Storage) *ResultsController {
-	return &ResultsController{Controller: service.NewController("ResultsController"), storage: s}
+	return &ResultsController{
+		Controller:    service.NewController("ResultsController"),
+		storage:       s,
+		MaxLogSize:    DefaultMaxLogSize,
+		MaxReportSize: DefaultMaxReportSize,
+	}
+}
+
+// MaxBodySize returns the maximum size, in bytes, of the body of a
+// request to the controller: MaxReportSize for the report and raw
+// actions, and MaxLogSize otherwise.
+func (c *ResultsController) MaxBodySize(r *http.Request) int64 {
+	switch r.URL.Path {
+	case "/v1/report", "/v1/raw":
+		return c.MaxReportSize
+	default:
+		return c.MaxLogSize
+	}
+}
+
+// storageContext returns a context, derived from the given action
+// context, that is also canceled when the client of the request goes
+// away. This way storage operations are aborted both when the action
+// times out and when the client disconnects, while keeping the values,
+// like the request ID, set by the goa middlewares.
+func storageContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	sctx, cancel := context.WithCancel(ctx)
+	req := goa.ContextRequest(ctx)
+	if req == nil || req.Request == nil {
+		return sctx, cancel
+	}
+	stop := context.AfterFunc(req.Request.Context(), cancel)
+	return sctx, func() {
+		stop()
+		cancel()
+	}
 }
 
 // Report runs the report action.
 func (c *ResultsController) Report(ctx *app.ReportResultsContext) error {
+	defer c.Drainer.Track()()
+
 	goa.LogInfo(ctx, "Uploading report to S3", "scan_id", ctx.Payload.ScanID, "check_id", ctx.Payload.CheckID, "scan_started_at", ctx.Payload.ScanStartTime)
 	link, err := c.saveReportToS3(ctx)
 	if err == nil {
 		goa.LogInfo(ctx, "Report uploaded to S3", "link", link)
+		dt, scan := storage.Partition(ctx.Payload.ScanID.String(), *ctx.Payload.ScanStartTime)
+		link = c.location(ctx, link, func(sctx context.Context, p storage.Presigner) (storage.PresignedURL, error) {
+			return p.PresignReport(sctx, dt, scan, ctx.Payload.CheckID.String()+".json")
+		})
 		ctx.ResponseData.Header().Add("Location", link)
 		return ctx.Created()
 	}
+	if errors.Is(err, errSpooled) {
+		goa.LogInfo(ctx, "Storage unavailable, report spooled")
+		return ctx.Accepted()
+	}
 	goa.LogError(ctx, err.Error())
-	return ctx.BadRequest()
+	if errors.Is(err, storage.ErrConflict) {
+		return ctx.Conflict(newErrorResponse(ctx, errConflict, err.Error(), "report"))
+	}
+	return uploadError(ctx, err)
 }
 
 // Raw runs the raw action.
 func (c *ResultsController) Raw(ctx *app.RawResultsContext) error {
+	defer c.Drainer.Track()()
+
 	goa.LogInfo(ctx, "Uploading raw logs to S3", "scan_id", ctx.Payload.ScanID, "check_id", ctx.Payload.CheckID, "scan_started_at", ctx.Payload.ScanStartTime)
 	link, err := c.saveLogsToS3(ctx)
 
 	if err == nil {
 		goa.LogInfo(ctx, "Raw logs uploaded to S3", "link", link)
+		dt, scan := storage.Partition(ctx.Payload.ScanID.String(), *ctx.Payload.ScanStartTime)
+		link = c.location(ctx, link, func(sctx context.Context, p storage.Presigner) (storage.PresignedURL, error) {
+			return p.PresignLog(sctx, dt, scan, ctx.Payload.CheckID.String()+".log")
+		})
+		ctx.ResponseData.Header().Add("Location", link)
+		return ctx.Created()
+	}
+	if errors.Is(err, errSpooled) {
+		goa.LogInfo(ctx, "Storage unavailable, raw logs spooled")
+		return ctx.Accepted()
+	}
+
+	goa.LogError(ctx, err.Error())
+	return uploadError(ctx, err)
+}
+
+// PutLog runs the putLog action.
+func (c *ResultsController) PutLog(ctx *app.PutLogResultsContext) error {
+	defer c.Drainer.Track()()
+
+	goa.LogInfo(ctx, "Streaming log to S3", "date", ctx.Date, "scan", ctx.Scan, "check", ctx.Check)
+	link, err := c.streamLogsToS3(ctx)
+
+	if err == nil {
+		goa.LogInfo(ctx, "Log streamed to S3", "link", link)
+		link = c.location(ctx, link, func(sctx context.Context, p storage.Presigner) (storage.PresignedURL, error) {
+			return p.PresignLog(sctx, ctx.Date, ctx.Scan, ctx.Check)
+		})
 		ctx.ResponseData.Header().Add("Location", link)
 		return ctx.Created()
 	}
 
 	goa.LogError(ctx, err.Error())
-	return ctx.BadRequest()
+	if errors.Is(err, errLogTooLarge) {
+		return ctx.RequestEntityTooLarge(newErrorResponse(ctx, errTooLarge, err.Error()))
+	}
+	return uploadError(ctx, err)
 }
 
 // GetReport runs the getReport action.
@@ -78,14 +203,34 @@ func (c *ResultsController) GetReport(ctx *app.GetReportResultsContext) error {
 	goa.LogInfo(ctx, "Downloading report from S3",
 		"date", ctx.Date, "scan", ctx.Scan, "check", ctx.Check)
 
-	report, err := c.storage.GetReport(ctx.Date, ctx.Scan, ctx.Check)
+	tag := reportTag(ctx, c.storage, ctx.Date, ctx.Scan, ctx.Check)
+	if err := c.Policy.AuthorizeRead(ctx, scanAccess(ctx.Scan, ctx.Check), tag); err != nil {
+		goa.LogError(ctx, err.Error())
+		return downloadError(ctx, err)
+	}
+
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
+	if p, ok := c.presigner(ctx.Redirect); ok {
+		u, err := p.PresignReport(sctx, ctx.Date, ctx.Scan, ctx.Check)
+		if err != nil {
+			goa.LogError(ctx, err.Error())
+			return downloadError(ctx, err)
+		}
+		goa.LogInfo(ctx, "Redirecting to presigned report", "expires", u.Expires)
+		ctx.ResponseData.Header().Set("Location", u.URL)
+		return ctx.Found()
+	}
+
+	obj, err := c.storage.GetReport(sctx, ctx.Date, ctx.Scan, ctx.Check, getOptions(ctx.Range, ctx.IfNoneMatch, ctx.IfModifiedSince))
 	if err == nil {
-		goa.LogInfo(ctx, "Report downloaded from S3")
-		return ctx.OK(report)
+		goa.LogInfo(ctx, "Streaming report from S3", "size", obj.Size)
+		return writeObject(ctx, ctx.ResponseData, obj)
 	}
 
 	goa.LogError(ctx, err.Error())
-	return ctx.BadRequest()
+	return downloadError(ctx, err)
 }
 
 // GetLog runs the getLog action.
@@ -93,46 +238,195 @@ func (c *ResultsController) GetLog(ctx *app.GetLogResultsContext) error {
 	goa.LogInfo(ctx, "Downloading log from S3",
 		"date", ctx.Date, "scan", ctx.Scan, "check", ctx.Check)
 
-	log, err := c.storage.GetLog(ctx.Date, ctx.Scan, ctx.Check)
+	tag := reportTag(ctx, c.storage, ctx.Date, ctx.Scan, ctx.Check)
+	if err := c.Policy.AuthorizeRead(ctx, scanAccess(ctx.Scan, ctx.Check), tag); err != nil {
+		goa.LogError(ctx, err.Error())
+		return downloadError(ctx, err)
+	}
+
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
+	if p, ok := c.presigner(ctx.Redirect); ok {
+		u, err := p.PresignLog(sctx, ctx.Date, ctx.Scan, ctx.Check)
+		if err != nil {
+			goa.LogError(ctx, err.Error())
+			return downloadError(ctx, err)
+		}
+		goa.LogInfo(ctx, "Redirecting to presigned log", "expires", u.Expires)
+		ctx.ResponseData.Header().Set("Location", u.URL)
+		return ctx.Found()
+	}
+
+	obj, err := c.storage.GetLog(sctx, ctx.Date, ctx.Scan, ctx.Check, getOptions(ctx.Range, ctx.IfNoneMatch, ctx.IfModifiedSince))
 	if err == nil {
-		goa.LogInfo(ctx, "Log downloaded from S3")
-		return ctx.OK(log)
+		goa.LogInfo(ctx, "Streaming log from S3", "size", obj.Size)
+		return writeObject(ctx, ctx.ResponseData, obj)
 	}
 
 	goa.LogError(ctx, err.Error())
-	return ctx.BadRequest()
+	return downloadError(ctx, err)
+}
+
+// presigner returns the storage as a Presigner if the download must be
+// redirected to a presigned URL, according to the redirect param of the
+// request or, if not set, the Redirect field. Downloads from storages
+// that can not presign URLs are never redirected, but served through
+// the service: the service does not start with the Redirect field set
+// on them, and the redirect param is only a preference of the client.
+func (c *ResultsController) presigner(redirect *bool) (storage.Presigner, bool) {
+	if redirect != nil && !*redirect || redirect == nil && !c.Redirect {
+		return nil, false
+	}
+	p, ok := c.storage.(storage.Presigner)
+	return p, ok
+}
+
+// location returns the Location of a stored result. If PresignedLinks is
+// set and the storage can presign URLs, it is the URL returned by
+// presign, otherwise it is link. A presigned URL that can not be
+// generated is not an error, as the result is already stored.
+func (c *ResultsController) location(ctx context.Context, link string, presign func(context.Context, storage.Presigner) (storage.PresignedURL, error)) string {
+	if !c.PresignedLinks {
+		return link
+	}
+	p, ok := c.storage.(storage.Presigner)
+	if !ok {
+		return link
+	}
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
+	u, err := presign(sctx, p)
+	if err != nil {
+		goa.LogError(ctx, "presigning link", "err", err)
+		return link
+	}
+	return u.URL
+}
+
+// scanAccess returns the access to the results identified by the scan
+// and check path elements of a download.
+func scanAccess(scan, check string) auth.Access {
+	return auth.Access{
+		ScanID:  strings.TrimPrefix(scan, "scan="),
+		CheckID: strings.TrimSuffix(strings.TrimSuffix(check, ".json"), ".log"),
+	}
+}
+
+// reportTag returns the func that looks up the tag of the stored report
+// of the check identified by the date, scan and check path elements of a
+// download, which grants reading its results. The tag is empty if the
+// check has no report or the report can not be parsed.
+func reportTag(ctx context.Context, st storage.Storage, date, scan, check string) func() (string, error) {
+	return func() (string, error) {
+		sctx, cancel := storageContext(ctx)
+		defer cancel()
+
+		name := scanAccess(scan, check).CheckID + ".json"
+		obj, err := st.GetReport(sctx, date, scan, name, storage.GetOptions{})
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		defer obj.Body.Close()
+
+		var r struct {
+			Tag string `json:"tag"`
+		}
+		if err := json.NewDecoder(obj.Body).Decode(&r); err != nil {
+			goa.LogInfo(ctx, "Report with no tag", "err", err)
+			return "", nil
+		}
+		return r.Tag, nil
+	}
+}
+
+// getOptions returns the options of a download with the given Range,
+// If-None-Match and If-Modified-Since headers. An invalid
+// If-Modified-Since date is ignored, as the HTTP spec mandates.
+func getOptions(rng, ifNoneMatch, ifModifiedSince *string) storage.GetOptions {
+	var opts storage.GetOptions
+	if rng != nil {
+		opts.Range = *rng
+	}
+	if ifNoneMatch != nil {
+		opts.IfNoneMatch = *ifNoneMatch
+	}
+	if ifModifiedSince != nil {
+		if t, err := http.ParseTime(*ifModifiedSince); err == nil {
+			opts.IfModifiedSince = t
+		}
+	}
+	return opts
+}
+
+// writeObject streams a downloaded object to the response, with a 206
+// status if only a range of it was downloaded, together with the
+// headers clients need to cache it. Once the response has
+// started errors can not be reported to the client anymore, so they are
+// only logged.
+func writeObject(ctx context.Context, rd *goa.ResponseData, obj *storage.Object) error {
+	defer obj.Body.Close()
+
+	h := rd.Header()
+	if h.Get("Content-Type") == "" {
+		h.Set("Content-Type", "text/plain")
+	}
+	h.Set("Accept-Ranges", "bytes")
+	h.Set("Content-Length", strconv.FormatInt(obj.ContentLength(), 10))
+	if obj.ETag != "" {
+		h.Set("ETag", obj.ETag)
+	}
+	if !obj.LastModified.IsZero() {
+		h.Set("Last-Modified", obj.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if obj.CacheControl != "" {
+		h.Set("Cache-Control", obj.CacheControl)
+	}
+	status := http.StatusOK
+	if obj.Range != nil {
+		h.Set("Content-Range", obj.Range.ContentRange(obj.Size))
+		status = http.StatusPartialContent
+	}
+	rd.WriteHeader(status)
+
+	if _, err := io.Copy(rd, obj.Body); err != nil {
+		goa.LogError(ctx, "streaming object", "err", err)
+	}
+	return nil
 }
 
 // saveReportToS3 must perform the following actions:
 // - upload vulnerable reports to vulcan-core-vulnerable-reports-{env} bucket
 // - upload vulnerable reports to vulcan-core-reports-{env} bucket
+// - skip the upload if the same report is already stored
+// - refuse to overwrite a report that ended later, unless forced
 // - returns a link to the report
 func (c *ResultsController) saveReportToS3(ctx context.Context) (link string, err error) {
 	payload := ctx.(*app.ReportResultsContext).Payload
 
-	missingRequired := false
+	var missing []string
 	if payload.CheckID == nil {
-		missingRequired = true
-		goa.LogError(ctx, "check_id is missing")
+		missing = append(missing, "check_id")
 	}
 
 	if payload.ScanID == nil {
-		missingRequired = true
-		goa.LogError(ctx, "scan_id is missing")
+		missing = append(missing, "scan_id")
 	}
 
 	if payload.ScanStartTime == nil {
-		missingRequired = true
-		goa.LogError(ctx, "scan_start_time is missing")
+		missing = append(missing, "scan_start_time")
 	}
 
 	if payload.Report == nil {
-		missingRequired = true
-		goa.LogError(ctx, "report is missing")
+		missing = append(missing, "report")
 	}
 
-	if missingRequired {
-		return "", fmt.Errorf("Missing required parameters")
+	if len(missing) > 0 {
+		return "", missingFieldsError(missing...)
 	}
 
 	checkID := payload.CheckID.String()
@@ -144,18 +438,45 @@ func (c *ResultsController) saveReportToS3(ctx context.Context) (link string, er
 	// the vulnerable reports bucket
 	var parsedReport report.Report
 	if err := json.Unmarshal([]byte(notParsedReport), &parsedReport); err != nil {
-		return "", fmt.Errorf("the report can not be unmarshaled correctly: %v", err)
+		return "", invalidFieldError("report", fmt.Errorf("the report can not be unmarshaled correctly: %w", err))
 	}
 	vulnerable := len(parsedReport.Vulnerabilities) > 0
 
+	if err := c.Policy.Authorize(ctx, auth.Access{ScanID: scanID, CheckID: checkID}); err != nil {
+		return "", err
+	}
+
 	// Prepare the report to be stored for Athena.
 	marshaledReport, err := parsedReport.MarshalJSONTimeAsString()
 	if err != nil {
 		return "", fmt.Errorf("the report can not be marshaled again: %v", err)
 	}
 
+	// The marshaled report is normalized, so retries of the same report
+	// have the same checksum.
+	sum := sha256.Sum256(marshaledReport)
+	opts := storage.SaveOptions{
+		Checksum: hex.EncodeToString(sum[:]),
+		EndTime:  parsedReport.EndTime,
+		Force:    payload.Force,
+	}
+
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
 	// save the report on report bucket
-	return c.storage.SaveReports(scanID, checkID, scanStartTime, marshaledReport, vulnerable)
+	link, err = c.storage.SaveReports(sctx, scanID, checkID, scanStartTime, marshaledReport, vulnerable, opts)
+	return link, c.spoolOnFailure(ctx, err, spool.Item{
+		Kind:       spool.KindReport,
+		ScanID:     scanID,
+		CheckID:    checkID,
+		StartedAt:  scanStartTime,
+		Vulnerable: vulnerable,
+		Content:    marshaledReport,
+		Checksum:   opts.Checksum,
+		EndTime:    opts.EndTime,
+		Force:      opts.Force,
+	})
 }
 
 // saveLogsToS3 must perform the following actions:
@@ -164,28 +485,25 @@ func (c *ResultsController) saveReportToS3(ctx context.Context) (link string, er
 func (c *ResultsController) saveLogsToS3(ctx context.Context) (link string, err error) {
 	payload := ctx.(*app.RawResultsContext).Payload
 
-	missingRequired := false
+	var missing []string
 	if payload.CheckID == nil {
-		missingRequired = true
-		goa.LogError(ctx, "check_id is missing")
+		missing = append(missing, "check_id")
 	}
 
 	if payload.ScanID == nil {
-		missingRequired = true
-		goa.LogError(ctx, "scan_id is missing")
+		missing = append(missing, "scan_id")
 	}
 
 	if payload.ScanStartTime == nil {
-		missingRequired = true
-		goa.LogError(ctx, "scan_start_time is missing")
+		missing = append(missing, "scan_start_time")
 	}
 
 	if payload.Raw == nil {
-		goa.LogInfo(ctx, "raw is missing")
+		missing = append(missing, "raw")
 	}
 
-	if missingRequired {
-		return "", fmt.Errorf("Missing required parameters. Received payload is %+v", ctx.(*app.RawResultsContext).Body)
+	if len(missing) > 0 {
+		return "", missingFieldsError(missing...)
 	}
 
 	checkID := payload.CheckID.String()
@@ -193,11 +511,139 @@ func (c *ResultsController) saveLogsToS3(ctx context.Context) (link string, err
 	scanStartTime := *payload.ScanStartTime
 	raw := *payload.Raw
 
+	if err := c.Policy.Authorize(ctx, auth.Access{ScanID: scanID, CheckID: checkID}); err != nil {
+		return "", err
+	}
+
 	dataRaw, err := base64.StdEncoding.DecodeString(raw)
 	if err != nil {
-		return "", err
+		return "", invalidFieldError("raw", err)
 	}
 
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
 	//save the report on report bucket
-	return c.storage.SaveLogs(scanID, checkID, scanStartTime, dataRaw)
+	link, err = c.storage.SaveLogs(sctx, scanID, checkID, scanStartTime, dataRaw)
+	return link, c.spoolOnFailure(ctx, err, spool.Item{
+		Kind:      spool.KindLog,
+		ScanID:    scanID,
+		CheckID:   checkID,
+		StartedAt: scanStartTime,
+		Content:   dataRaw,
+	})
+}
+
+// errSpooled is returned when a result could not be stored and was
+// spooled to be stored later.
+var errSpooled = errors.New("the result was spooled")
+
+// spoolOnFailure spools the result if err means the storage is
+// unavailable and a spool is configured, returning errSpooled. Otherwise
+// it returns err.
+func (c *ResultsController) spoolOnFailure(ctx context.Context, err error, it spool.Item) error {
+	if c.Spool == nil || !errors.Is(err, storage.ErrUnavailable) {
+		return err
+	}
+	if serr := c.Spool.Put(it); serr != nil {
+		goa.LogError(ctx, "spooling result", "err", serr)
+		return err
+	}
+	return errSpooled
+}
+
+// errLogTooLarge is returned when the logs uploaded with the putLog
+// action exceed the maximum size.
+var errLogTooLarge = errors.New("the log exceeds the maximum size")
+
+// streamLogsToS3 must perform the following actions:
+// - stream the logs in the body of the request to vulcan-core-logs-{env} bucket
+// - returns a link to the logs
+func (c *ResultsController) streamLogsToS3(ctx *app.PutLogResultsContext) (link string, err error) {
+	startedAt, err := time.Parse("dt=2006-01-02", ctx.Date)
+	if err != nil {
+		return "", invalidFieldError("date", err)
+	}
+	scanID, err := uuid.FromString(strings.TrimPrefix(ctx.Scan, "scan="))
+	if err != nil {
+		return "", invalidFieldError("scan", err)
+	}
+	checkID, err := uuid.FromString(strings.TrimSuffix(ctx.Check, ".log"))
+	if err != nil {
+		return "", invalidFieldError("check", err)
+	}
+
+	if err := c.Policy.Authorize(ctx, auth.Access{ScanID: scanID.String(), CheckID: checkID.String()}); err != nil {
+		return "", err
+	}
+
+	req := ctx.Request
+	if req.ContentLength > c.MaxLogSize {
+		return "", errLogTooLarge
+	}
+
+	body := &limitedReader{r: req.Body, n: c.MaxLogSize}
+	var logs io.Reader = body
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		logs, err = formFile(multipart.NewReader(body, params["boundary"]), "log")
+		if err != nil {
+			if body.exceeded {
+				return "", errLogTooLarge
+			}
+			return "", err
+		}
+	}
+
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
+	link, err = c.storage.StreamLogs(sctx, scanID.String(), checkID.String(), startedAt, logs)
+	if err != nil && body.exceeded {
+		return "", errLogTooLarge
+	}
+	return link, err
+}
+
+// formFile returns the part of a multipart form with the given field
+// name. The parts before it are discarded.
+func formFile(mr *multipart.Reader, field string) (io.Reader, error) {
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, missingFieldsError(field)
+		}
+		if err != nil {
+			return nil, invalidFieldError(field, err)
+		}
+		if part.FormName() == field {
+			return part, nil
+		}
+	}
+}
+
+// limitedReader reads from r failing with errLogTooLarge as soon as more
+// than n bytes are read.
+type limitedReader struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, errLogTooLarge
+	}
+	// Read one byte more than allowed to detect the body is too large
+	// without waiting for the next call.
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		l.exceeded = true
+		return int(l.n), errLogTooLarge
+	}
+	l.n -= int64(n)
+	return n, err
 }
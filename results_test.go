@@ -5,20 +5,33 @@ Copyright 2019 Adevinta
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/goatest"
+	"github.com/goadesign/goa/middleware"
 	uuid "github.com/gofrs/uuid"
+	"github.com/sirupsen/logrus"
 
 	"github.com/adevinta/vulcan-results/app"
 	"github.com/adevinta/vulcan-results/app/test"
+	"github.com/adevinta/vulcan-results/auth"
+	"github.com/adevinta/vulcan-results/spool"
 	"github.com/adevinta/vulcan-results/storage"
 )
 
@@ -87,30 +100,65 @@ var testCasesRaw = []struct {
 
 type funcTestReport func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, *app.ReportPayload) http.ResponseWriter
 type funcTestRaw func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, *app.RawPayload) http.ResponseWriter
-type funcTestGetReport func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, string, string, string) http.ResponseWriter
-type funcTestGetLog func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, string, string, string) http.ResponseWriter
+type funcTestGetReport func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, string, string, string, *bool, *string, *string, *string) (http.ResponseWriter, error)
+type funcTestGetLog func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, string, string, string, *bool, *string, *string, *string) (http.ResponseWriter, error)
+
+// noErrorBody adapts the test helpers of the download actions responses
+// that do not return an error document.
+func noErrorBody(f func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, string, string, string, *bool, *string, *string, *string) http.ResponseWriter) func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, string, string, string, *bool, *string, *string, *string) (http.ResponseWriter, error) {
+	return func(t goatest.TInterface, ctx context.Context, service *goa.Service, ctrl app.ResultsController, date, scan, check string, redirect *bool, ifModifiedSince, ifNoneMatch, rng *string) (http.ResponseWriter, error) {
+		return f(t, ctx, service, ctrl, date, scan, check, redirect, ifModifiedSince, ifNoneMatch, rng), nil
+	}
+}
 
 type storageMock struct {
 	link   string
 	report []byte
 	log    []byte
+	checks *storage.CheckPage
+	scans  []storage.ScanSummary
 	err    error
 }
 
-func (st storageMock) SaveLogs(checkID, scanID string, startedAt time.Time, raw []byte) (link string, err error) {
+func (st storageMock) SaveLogs(ctx context.Context, checkID, scanID string, startedAt time.Time, raw []byte) (link string, err error) {
+	return st.link, st.err
+}
+
+func (st storageMock) SaveReports(ctx context.Context, checkID, scanID string, startedAt time.Time, result []byte, compress bool, opts storage.SaveOptions) (link string, err error) {
 	return st.link, st.err
 }
 
-func (st storageMock) SaveReports(checkID, scanID string, startedAt time.Time, result []byte, compress bool) (link string, err error) {
+func (st storageMock) StreamLogs(ctx context.Context, checkID, scanID string, startedAt time.Time, logs io.Reader) (link string, err error) {
+	if _, err := ioutil.ReadAll(logs); err != nil {
+		return "", err
+	}
 	return st.link, st.err
 }
 
-func (st storageMock) GetReport(date, scanID, checkID string) ([]byte, error) {
-	return st.report, st.err
+func (st storageMock) GetReport(ctx context.Context, date, scanID, checkID string, opts storage.GetOptions) (*storage.Object, error) {
+	return mockObject(st.report, st.err)
 }
 
-func (st storageMock) GetLog(date, scanID, checkID string) ([]byte, error) {
-	return st.log, st.err
+func (st storageMock) GetLog(ctx context.Context, date, scanID, checkID string, opts storage.GetOptions) (*storage.Object, error) {
+	return mockObject(st.log, st.err)
+}
+
+func mockObject(content []byte, err error) (*storage.Object, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &storage.Object{
+		Body: ioutil.NopCloser(bytes.NewReader(content)),
+		Size: int64(len(content)),
+	}, nil
+}
+
+func (st storageMock) ListChecks(ctx context.Context, date, scanID, token string, limit int) (*storage.CheckPage, error) {
+	return st.checks, st.err
+}
+
+func (st storageMock) ListScans(ctx context.Context, from, to time.Time) ([]storage.ScanSummary, error) {
+	return st.scans, st.err
 }
 
 func ok(w http.ResponseWriter, r *http.Request) {
@@ -316,6 +364,8 @@ func TestSaveResult(t *testing.T) {
 	}
 }
 
+var unsatisfiableRange = "bytes=100-"
+
 var testCasesGetReport = []struct {
 	name   string
 	date   string
@@ -324,7 +374,9 @@ var testCasesGetReport = []struct {
 	stMock storage.Storage
 	psMock http.HandlerFunc
 	psURL  string
+	rng    *string
 	f      funcTestGetReport
+	code   string
 }{
 	{
 		name:  "Happy path OK",
@@ -336,10 +388,46 @@ var testCasesGetReport = []struct {
 			err:    nil,
 		},
 		psMock: func(w http.ResponseWriter, r *http.Request) {},
-		f:      test.GetReportResultsOK,
+		f:      noErrorBody(test.GetReportResultsOK),
 	},
 	{
 		name:  "Should return bad request",
+		date:  "..",
+		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
+		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
+		stMock: storageMock{
+			err: fmt.Errorf("%w: invalid path element \"..\"", storage.ErrInvalidKey),
+		},
+		psMock: func(w http.ResponseWriter, r *http.Request) {},
+		f:      test.GetReportResultsBadRequest,
+		code:   "invalid_key",
+	},
+	{
+		name:  "Should return not found",
+		date:  "dt=2019-11-01",
+		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
+		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
+		stMock: storageMock{
+			err: fmt.Errorf("%w: NoSuchKey", storage.ErrNotFound),
+		},
+		psMock: func(w http.ResponseWriter, r *http.Request) {},
+		f:      test.GetReportResultsNotFound,
+		code:   "not_found",
+	},
+	{
+		name:  "Should return service unavailable",
+		date:  "dt=2019-11-01",
+		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
+		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
+		stMock: storageMock{
+			err: fmt.Errorf("%w: SlowDown", storage.ErrUnavailable),
+		},
+		psMock: func(w http.ResponseWriter, r *http.Request) {},
+		f:      test.GetReportResultsServiceUnavailable,
+		code:   "storage_unavailable",
+	},
+	{
+		name:  "Should return internal server error",
 		date:  "dt=2019-11-01",
 		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
 		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
@@ -348,7 +436,21 @@ var testCasesGetReport = []struct {
 			err:    errors.New("Error"),
 		},
 		psMock: func(w http.ResponseWriter, r *http.Request) {},
-		f:      test.GetReportResultsBadRequest,
+		f:      test.GetReportResultsInternalServerError,
+		code:   "internal",
+	},
+	{
+		name:  "Should return range not satisfiable",
+		date:  "dt=2019-11-01",
+		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
+		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
+		rng:   &unsatisfiableRange,
+		stMock: storageMock{
+			err: fmt.Errorf("%w: bytes=100- of 10 bytes", storage.ErrRangeNotSatisfiable),
+		},
+		psMock: func(w http.ResponseWriter, r *http.Request) {},
+		f:      test.GetReportResultsRequestedRangeNotSatisfiable,
+		code:   "range_not_satisfiable",
 	},
 }
 
@@ -369,7 +471,8 @@ func TestGetReport(t *testing.T) {
 
 			ctrl := NewResultsController(service, tc.stMock)
 
-			_ = tc.f(t, nil, service, ctrl, tc.date, tc.scan, tc.check)
+			_, err := tc.f(t, nil, service, ctrl, tc.date, tc.scan, tc.check, nil, nil, nil, tc.rng)
+			checkErrorCode(t, err, tc.code)
 		})
 	}
 }
@@ -382,7 +485,9 @@ var testCasesGetLog = []struct {
 	stMock storage.Storage
 	psMock http.HandlerFunc
 	psURL  string
+	rng    *string
 	f      funcTestGetLog
+	code   string
 }{
 	{
 		name:  "Happy path OK",
@@ -394,10 +499,46 @@ var testCasesGetLog = []struct {
 			err:    nil,
 		},
 		psMock: func(w http.ResponseWriter, r *http.Request) {},
-		f:      test.GetLogResultsOK,
+		f:      noErrorBody(test.GetLogResultsOK),
 	},
 	{
 		name:  "Should return bad request",
+		date:  "..",
+		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
+		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
+		stMock: storageMock{
+			err: fmt.Errorf("%w: invalid path element \"..\"", storage.ErrInvalidKey),
+		},
+		psMock: func(w http.ResponseWriter, r *http.Request) {},
+		f:      test.GetLogResultsBadRequest,
+		code:   "invalid_key",
+	},
+	{
+		name:  "Should return not found",
+		date:  "dt=2019-11-01",
+		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
+		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
+		stMock: storageMock{
+			err: fmt.Errorf("%w: NoSuchKey", storage.ErrNotFound),
+		},
+		psMock: func(w http.ResponseWriter, r *http.Request) {},
+		f:      test.GetLogResultsNotFound,
+		code:   "not_found",
+	},
+	{
+		name:  "Should return service unavailable",
+		date:  "dt=2019-11-01",
+		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
+		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
+		stMock: storageMock{
+			err: fmt.Errorf("%w: SlowDown", storage.ErrUnavailable),
+		},
+		psMock: func(w http.ResponseWriter, r *http.Request) {},
+		f:      test.GetLogResultsServiceUnavailable,
+		code:   "storage_unavailable",
+	},
+	{
+		name:  "Should return internal server error",
 		date:  "dt=2019-11-01",
 		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
 		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
@@ -406,7 +547,21 @@ var testCasesGetLog = []struct {
 			err:    errors.New("Error"),
 		},
 		psMock: func(w http.ResponseWriter, r *http.Request) {},
-		f:      test.GetLogResultsBadRequest,
+		f:      test.GetLogResultsInternalServerError,
+		code:   "internal",
+	},
+	{
+		name:  "Should return range not satisfiable",
+		date:  "dt=2019-11-01",
+		scan:  "scan=9126034c-7caf-4acd-93f3-bee1941aa140",
+		check: "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json",
+		rng:   &unsatisfiableRange,
+		stMock: storageMock{
+			err: fmt.Errorf("%w: bytes=100- of 10 bytes", storage.ErrRangeNotSatisfiable),
+		},
+		psMock: func(w http.ResponseWriter, r *http.Request) {},
+		f:      test.GetLogResultsRequestedRangeNotSatisfiable,
+		code:   "range_not_satisfiable",
 	},
 }
 
@@ -427,7 +582,722 @@ func TestGetLog(t *testing.T) {
 
 			ctrl := NewResultsController(service, tc.stMock)
 
-			_ = tc.f(t, nil, service, ctrl, tc.date, tc.scan, tc.check)
+			_, err := tc.f(t, nil, service, ctrl, tc.date, tc.scan, tc.check, nil, nil, nil, tc.rng)
+			checkErrorCode(t, err, tc.code)
 		})
 	}
 }
+
+func TestGetLogRange(t *testing.T) {
+	st := storage.NewMemoryStorage(storage.Config{BucketLogs: "logs"})
+	startedAt := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := st.SaveLogs(context.Background(), scanID.String(), checkID.String(), startedAt, []byte("0123456789")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	service := goa.New("vulcan-results")
+	ctrl := NewResultsController(service, st)
+
+	date, scan, check := "dt=2019-11-01", "scan="+scanID.String(), checkID.String()+".log"
+	rng := "bytes=-4"
+	rw := test.GetLogResultsPartialContent(t, nil, service, ctrl, date, scan, check, nil, nil, nil, &rng).(*httptest.ResponseRecorder)
+	if body := rw.Body.String(); body != "6789" {
+		t.Fatalf("expected the last 4 bytes of the log, got: %q", body)
+	}
+	if cr := rw.Header().Get("Content-Range"); cr != "bytes 6-9/10" {
+		t.Fatalf("unexpected Content-Range: %s", cr)
+	}
+	if cl := rw.Header().Get("Content-Length"); cl != "4" {
+		t.Fatalf("unexpected Content-Length: %s", cl)
+	}
+
+	rw = test.GetLogResultsOK(t, nil, service, ctrl, date, scan, check, nil, nil, nil, nil).(*httptest.ResponseRecorder)
+	if body := rw.Body.String(); body != "0123456789" {
+		t.Fatalf("expected the whole log, got: %q", body)
+	}
+	if ar := rw.Header().Get("Accept-Ranges"); ar != "bytes" {
+		t.Fatalf("unexpected Accept-Ranges: %s", ar)
+	}
+}
+
+func TestGetReportConditional(t *testing.T) {
+	st := storage.NewMemoryStorage(storage.Config{BucketReports: "reports", CacheControl: "max-age=60"})
+	startedAt := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := st.SaveReports(context.Background(), scanID.String(), checkID.String(), startedAt, []byte("{}"), false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	service := goa.New("vulcan-results")
+	ctrl := NewResultsController(service, st)
+
+	date, scan, check := "dt=2019-11-01", "scan="+scanID.String(), checkID.String()+".json"
+	rw := test.GetReportResultsOK(t, nil, service, ctrl, date, scan, check, nil, nil, nil, nil)
+	etag := rw.Header().Get("ETag")
+	lastModified := rw.Header().Get("Last-Modified")
+	if etag == "" || lastModified == "" {
+		t.Fatalf("expected ETag and Last-Modified headers, got: %v", rw.Header())
+	}
+	if cc := rw.Header().Get("Cache-Control"); cc != "max-age=60" {
+		t.Fatalf("unexpected Cache-Control: %s", cc)
+	}
+
+	test.GetReportResultsNotModified(t, nil, service, ctrl, date, scan, check, nil, nil, &etag, nil)
+	test.GetReportResultsNotModified(t, nil, service, ctrl, date, scan, check, nil, &lastModified, nil, nil)
+
+	other := `"other"`
+	test.GetReportResultsOK(t, nil, service, ctrl, date, scan, check, nil, nil, &other, nil)
+	invalid := "yesterday"
+	test.GetReportResultsOK(t, nil, service, ctrl, date, scan, check, nil, &invalid, nil, nil)
+}
+
+func TestGetReportRedirect(t *testing.T) {
+	st := presignerMock{storageMock: storageMock{report: []byte("{}")}, url: "https://reports.s3"}
+	date, scan, check := "dt=2019-11-01", "scan="+scanID.String(), checkID.String()+".json"
+	location := "https://reports.s3/" + date + "/" + scan + "/" + check
+	yes, no := true, false
+
+	service := goa.New("vulcan-results")
+	ctrl := NewResultsController(service, st)
+
+	rw := test.GetReportResultsFound(t, nil, service, ctrl, date, scan, check, &yes, nil, nil, nil)
+	if l := rw.Header().Get("Location"); l != location {
+		t.Fatalf("expected redirect to %s, got: %s", location, l)
+	}
+	test.GetReportResultsOK(t, nil, service, ctrl, date, scan, check, nil, nil, nil, nil)
+
+	ctrl.Redirect = true
+	rw = test.GetLogResultsFound(t, nil, service, ctrl, date, scan, check, nil, nil, nil, nil)
+	if l := rw.Header().Get("Location"); l != location {
+		t.Fatalf("expected redirect to %s, got: %s", location, l)
+	}
+	test.GetLogResultsOK(t, nil, service, ctrl, date, scan, check, &no, nil, nil, nil)
+
+	// Storages that can not presign URLs return the content.
+	ctrl = NewResultsController(service, st.storageMock)
+	ctrl.Redirect = true
+	test.GetReportResultsOK(t, nil, service, ctrl, date, scan, check, &yes, nil, nil, nil)
+}
+
+func TestReportPresignedLink(t *testing.T) {
+	st := presignerMock{storageMock: storageMock{link: "http://results/v1/reports/report.json"}, url: "https://reports.s3"}
+	startedAt := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	payload := &app.ReportPayload{
+		Report:        &plainReport,
+		ScanID:        &scanID,
+		CheckID:       &checkID,
+		ScanStartTime: &startedAt,
+	}
+
+	service := goa.New("vulcan-results")
+	ctrl := NewResultsController(service, st)
+
+	rw := test.ReportResultsCreated(t, nil, service, ctrl, payload)
+	if l := rw.Header().Get("Location"); l != st.link {
+		t.Fatalf("expected Location %s, got: %s", st.link, l)
+	}
+
+	ctrl.PresignedLinks = true
+	rw = test.ReportResultsCreated(t, nil, service, ctrl, payload)
+	location := "https://reports.s3/dt=2019-11-01/scan=" + scanID.String() + "/" + checkID.String() + ".json"
+	if l := rw.Header().Get("Location"); l != location {
+		t.Fatalf("expected Location %s, got: %s", location, l)
+	}
+}
+
+// loadTestPolicy returns a policy that grants the scan of the tests to
+// team-a, and the checks tagged team-c to team-c.
+func loadTestPolicy(t *testing.T) *auth.Policy {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "policy.toml")
+	policy := fmt.Sprintf("[[Rule]]\nPrincipals = [\"team-a\"]\nScans = [%q]\n", scanID.String()) +
+		"[[Rule]]\nPrincipals = [\"team-c\"]\nTags = [\"team-c\"]\n"
+	if err := os.WriteFile(path, []byte(policy), 0600); err != nil {
+		t.Fatalf("writing policy: %v", err)
+	}
+	p, err := auth.LoadPolicy(path, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return p
+}
+
+func TestResultsPolicy(t *testing.T) {
+	p := loadTestPolicy(t)
+
+	st := storageMock{report: []byte("{}"), link: "http://results/v1/reports/report.json"}
+	service := goa.New("vulcan-results")
+	ctrl := NewResultsController(service, st)
+	ctrl.Policy = p
+
+	teamA := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "team-a"})
+	teamB := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "team-b"})
+	date, scan, check := "dt=2019-11-01", "scan="+scanID.String(), checkID.String()+".json"
+
+	test.GetReportResultsOK(t, teamA, service, ctrl, date, scan, check, nil, nil, nil, nil)
+	_, err := test.GetReportResultsForbidden(t, teamB, service, ctrl, date, scan, check, nil, nil, nil, nil)
+	checkErrorCode(t, err, "forbidden")
+
+	payload := &app.ReportPayload{
+		Report:        &plainReport,
+		ScanID:        &scanID,
+		CheckID:       &checkID,
+		ScanStartTime: &scanStartTime,
+	}
+	test.ReportResultsCreated(t, teamA, service, ctrl, payload)
+	_, err = test.ReportResultsForbidden(t, teamB, service, ctrl, payload)
+	checkErrorCode(t, err, "forbidden")
+
+	// The tag of the report is set by the client, so it does not grant
+	// the scan it is uploaded to.
+	otherScanID := uuid.Must(uuid.NewV4())
+	taggedReport := `{"tag":"team-a","vulnerabilities":[]}`
+	payload = &app.ReportPayload{
+		Report:        &taggedReport,
+		ScanID:        &otherScanID,
+		CheckID:       &checkID,
+		ScanStartTime: &scanStartTime,
+	}
+	_, err = test.ReportResultsForbidden(t, teamA, service, ctrl, payload)
+	checkErrorCode(t, err, "forbidden")
+
+	// The tag of the stored report grants reading the results of its
+	// check, but not uploading them.
+	teamC := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "team-c"})
+	_, err = test.GetReportResultsForbidden(t, teamC, service, ctrl, date, scan, check, nil, nil, nil, nil)
+	checkErrorCode(t, err, "forbidden")
+	tagged := NewResultsController(service, storageMock{report: []byte(`{"tag":"team-c"}`), log: []byte("log")})
+	tagged.Policy = p
+	test.GetReportResultsOK(t, teamC, service, tagged, date, scan, check, nil, nil, nil, nil)
+	test.GetLogResultsOK(t, teamC, service, tagged, date, scan, checkID.String()+".log", nil, nil, nil, nil)
+	_, err = test.GetReportResultsForbidden(t, teamB, service, tagged, date, scan, check, nil, nil, nil, nil)
+	checkErrorCode(t, err, "forbidden")
+	taggedReport = `{"tag":"team-c","vulnerabilities":[]}`
+	_, err = test.ReportResultsForbidden(t, teamC, service, tagged, payload)
+	checkErrorCode(t, err, "forbidden")
+}
+
+// savingStorage is a storage that records the reports saved.
+type savingStorage struct {
+	storageMock
+	saved *[]string
+}
+
+func (st savingStorage) SaveReports(ctx context.Context, checkID, scanID string, startedAt time.Time, result []byte, compress bool, opts storage.SaveOptions) (link string, err error) {
+	*st.saved = append(*st.saved, string(result))
+	return st.link, st.err
+}
+
+// mountResults mounts ctrl on service. The test is skipped if goa can
+// not create its JSON decoder, as happens with the encoding/json of the
+// jsonv2 experiment.
+func mountResults(t *testing.T, service *goa.Service, ctrl app.ResultsController) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("mounting the controller: %v", r)
+		}
+	}()
+	app.MountResultsController(service, ctrl)
+}
+
+func TestReportSignedBody(t *testing.T) {
+	keys := map[string]auth.HMACKey{
+		"agents": {Secret: []byte("secret"), Principal: auth.Principal{Subject: "agents", Scopes: []string{auth.ScopeWrite}}},
+	}
+	service := goa.New("vulcan-results")
+	authn := auth.NewHMACAuthenticator(keys, 0)
+	app.UseResultsMiddleware(service, auth.Middleware(authn))
+	var saved []string
+	ctrl := NewResultsController(service, savingStorage{storageMock: storageMock{link: "http://results/report.json"}, saved: &saved})
+	mountResults(t, service, ctrl)
+	h := authn.VerifyContentHash(service.Mux, ctrl.MaxBodySize)
+
+	body, err := json.Marshal(&app.ReportPayload{
+		Report:        &plainReport,
+		ScanID:        &scanID,
+		CheckID:       &checkID,
+		ScanStartTime: &scanStartTime,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tampered := bytes.Replace(body, []byte("vulnerabilities"), []byte("vulnerabilitiez"), 1)
+
+	testCases := []struct {
+		name       string
+		body       []byte
+		wantStatus int
+	}{
+		{name: "signed body", body: body, wantStatus: http.StatusCreated},
+		{name: "tampered body", body: tampered, wantStatus: http.StatusUnauthorized},
+		{name: "trailing data", body: append(append([]byte{}, body...), " {}"...), wantStatus: http.StatusUnauthorized},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			saved = nil
+			req := httptest.NewRequest(http.MethodPost, "/v1/report", bytes.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			auth.SignRequest(req, "agents", []byte("secret"), body, time.Now())
+			rw := httptest.NewRecorder()
+			h.ServeHTTP(rw, req)
+			if rw.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got: %d: %s", tc.wantStatus, rw.Code, rw.Body)
+			}
+			if stored := len(saved) > 0; stored != (tc.wantStatus == http.StatusCreated) {
+				t.Fatalf("expected only the signed body to be stored, got: %v", saved)
+			}
+		})
+	}
+}
+
+// checkErrorCode verifies that err is an error document with the given
+// code. An empty code means no error document is expected.
+func checkErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+
+	if code == "" {
+		if err != nil {
+			t.Fatalf("expected no error document, got: %v", err)
+		}
+		return
+	}
+
+	eresp, ok := err.(*goa.ErrorResponse)
+	if !ok {
+		t.Fatalf("expected error document with code %s, got: %v", code, err)
+	}
+	if eresp.Code != code {
+		t.Fatalf("expected error code %s, got: %s", code, eresp.Code)
+	}
+}
+
+func TestStorageContext(t *testing.T) {
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/v1/reports/dt=2019-11-01/scan=id/check.json", nil).WithContext(reqCtx)
+	goaCtx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, nil)
+
+	sctx, cancel := storageContext(goaCtx)
+	defer cancel()
+
+	if err := sctx.Err(); err != nil {
+		t.Fatalf("expected storage context to be alive, got: %v", err)
+	}
+
+	cancelReq()
+	select {
+	case <-sctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("expected storage context to be canceled when the request is canceled")
+	}
+}
+
+func TestReportErrors(t *testing.T) {
+	invalidReport := "not a json report"
+
+	testCases := []struct {
+		name    string
+		payload *app.ReportPayload
+		stMock  storage.Storage
+		f       func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, *app.ReportPayload) (http.ResponseWriter, error)
+		code    string
+		fields  []string
+	}{
+		{
+			name:    "missing fields",
+			payload: &app.ReportPayload{CheckID: &checkID},
+			stMock:  storageMock{},
+			f:       test.ReportResultsBadRequest,
+			code:    "missing_fields",
+			fields:  []string{"scan_id", "scan_start_time", "report"},
+		},
+		{
+			name: "invalid report",
+			payload: &app.ReportPayload{
+				Report:        &invalidReport,
+				ScanID:        &scanID,
+				CheckID:       &checkID,
+				ScanStartTime: &scanStartTime,
+			},
+			stMock: storageMock{},
+			f:      test.ReportResultsBadRequest,
+			code:   "invalid_field",
+			fields: []string{"report"},
+		},
+		{
+			name: "storage unavailable",
+			payload: &app.ReportPayload{
+				Report:        &plainReport,
+				ScanID:        &scanID,
+				CheckID:       &checkID,
+				ScanStartTime: &scanStartTime,
+			},
+			stMock: storageMock{err: fmt.Errorf("%w: SlowDown", storage.ErrUnavailable)},
+			f:      test.ReportResultsServiceUnavailable,
+			code:   "storage_unavailable",
+		},
+		{
+			name: "storage error",
+			payload: &app.ReportPayload{
+				Report:        &plainReport,
+				ScanID:        &scanID,
+				CheckID:       &checkID,
+				ScanStartTime: &scanStartTime,
+			},
+			stMock: storageMock{err: errors.New("AccessDenied")},
+			f:      test.ReportResultsInternalServerError,
+			code:   "internal",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			service := goa.New("vulcan-results")
+			ctrl := NewResultsController(service, tc.stMock)
+
+			_, err := tc.f(t, nil, service, ctrl, tc.payload)
+			checkErrorCode(t, err, tc.code)
+			checkErrorFields(t, err, tc.fields)
+		})
+	}
+}
+
+func TestRawErrors(t *testing.T) {
+	invalidRaw := "not base64!"
+
+	testCases := []struct {
+		name    string
+		payload *app.RawPayload
+		f       func(goatest.TInterface, context.Context, *goa.Service, app.ResultsController, *app.RawPayload) (http.ResponseWriter, error)
+		code    string
+		fields  []string
+	}{
+		{
+			name:    "missing fields",
+			payload: &app.RawPayload{ScanID: &scanID},
+			f:       test.RawResultsBadRequest,
+			code:    "missing_fields",
+			fields:  []string{"check_id", "scan_start_time", "raw"},
+		},
+		{
+			name: "invalid base64",
+			payload: &app.RawPayload{
+				Raw:           &invalidRaw,
+				ScanID:        &scanID,
+				CheckID:       &checkID,
+				ScanStartTime: &scanStartTime,
+			},
+			f:      test.RawResultsBadRequest,
+			code:   "invalid_field",
+			fields: []string{"raw"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			service := goa.New("vulcan-results")
+			ctrl := NewResultsController(service, storageMock{})
+
+			_, err := tc.f(t, nil, service, ctrl, tc.payload)
+			checkErrorCode(t, err, tc.code)
+			checkErrorFields(t, err, tc.fields)
+		})
+	}
+}
+
+func TestSpooledResults(t *testing.T) {
+	sp, err := spool.New(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	service := goa.New("vulcan-results")
+	ctrl := NewResultsController(service, storageMock{err: fmt.Errorf("%w: SlowDown", storage.ErrUnavailable)})
+	ctrl.Spool = sp
+
+	test.ReportResultsAccepted(t, nil, service, ctrl, &app.ReportPayload{
+		Report:        &plainReport,
+		ScanID:        &scanID,
+		CheckID:       &checkID,
+		ScanStartTime: &scanStartTime,
+	})
+	test.RawResultsAccepted(t, nil, service, ctrl, &app.RawPayload{
+		Raw:           &base64raw,
+		ScanID:        &scanID,
+		CheckID:       &checkID,
+		ScanStartTime: &scanStartTime,
+	})
+
+	stats, err := sp.Stats()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if stats.Depth != 2 {
+		t.Fatalf("expected 2 spooled results, got: %d", stats.Depth)
+	}
+
+	// Errors other than an unavailable storage are not spooled.
+	ctrl = NewResultsController(service, storageMock{err: errors.New("AccessDenied")})
+	ctrl.Spool = sp
+	test.ReportResultsInternalServerError(t, nil, service, ctrl, &app.ReportPayload{
+		Report:        &plainReport,
+		ScanID:        &scanID,
+		CheckID:       &checkID,
+		ScanStartTime: &scanStartTime,
+	})
+	if stats, _ := sp.Stats(); stats.Depth != 2 {
+		t.Fatalf("expected 2 spooled results, got: %d", stats.Depth)
+	}
+}
+
+func TestReportConflict(t *testing.T) {
+	st := storage.NewMemoryStorage(storage.Config{
+		BucketReports:           "reports",
+		BucketVulnerableReports: "vulnerable-reports",
+		BucketLogs:              "logs",
+	})
+	service := goa.New("vulcan-results")
+	ctrl := NewResultsController(service, st)
+
+	payload := func(endTime string, force bool) *app.ReportPayload {
+		r := `{"end_time":"` + endTime + `","vulnerabilities":[{}]}`
+		return &app.ReportPayload{
+			Report:        &r,
+			ScanID:        &scanID,
+			CheckID:       &checkID,
+			ScanStartTime: &scanStartTime,
+			Force:         force,
+		}
+	}
+
+	test.ReportResultsCreated(t, nil, service, ctrl, payload("2019-11-01T10:00:00Z", false))
+	// Retries of the same report succeed.
+	test.ReportResultsCreated(t, nil, service, ctrl, payload("2019-11-01T10:00:00Z", false))
+
+	_, err := test.ReportResultsConflict(t, nil, service, ctrl, payload("2019-11-01T09:00:00Z", false))
+	checkErrorCode(t, err, "conflict")
+
+	test.ReportResultsCreated(t, nil, service, ctrl, payload("2019-11-01T09:00:00Z", true))
+}
+
+func checkErrorFields(t *testing.T, err error, fields []string) {
+	t.Helper()
+
+	eresp, ok := err.(*goa.ErrorResponse)
+	if !ok {
+		t.Fatalf("expected error document, got: %v", err)
+	}
+	if len(fields) == 0 {
+		return
+	}
+	if got := fmt.Sprint(eresp.Meta["fields"]); got != fmt.Sprint(fields) {
+		t.Fatalf("expected fields %v, got: %v", fields, got)
+	}
+}
+
+func TestErrorRequestID(t *testing.T) {
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return goa.ErrBadRequest("invalid payload")
+	}
+	h = middleware.RequestID()(ErrorRequestID()(h))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/report", nil)
+	req.Header.Set("X-Request-Id", "my-request")
+	ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, nil)
+
+	err := h(ctx, httptest.NewRecorder(), req)
+	eresp, ok := err.(*goa.ErrorResponse)
+	if !ok {
+		t.Fatalf("expected error document, got: %v", err)
+	}
+	if eresp.Meta["request_id"] != "my-request" {
+		t.Fatalf("expected request ID my-request, got: %v", eresp.Meta["request_id"])
+	}
+}
+
+const putLogPath = "/v1/logs/dt=2019-11-01/scan=e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0/e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.log"
+
+// putLog runs the putLog action with the given request body. The
+// helpers generated by goa can not set the body of the request.
+func putLog(t *testing.T, ctrl *ResultsController, path, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, path, body)
+	req.Header.Set("Content-Type", contentType)
+	elems := strings.Split(strings.TrimPrefix(path, "/v1/logs/"), "/")
+	prms := url.Values{
+		"date":  {elems[0]},
+		"scan":  {elems[1]},
+		"check": {elems[2]},
+	}
+
+	rw := httptest.NewRecorder()
+	goaCtx := goa.NewContext(goa.WithAction(context.Background(), "ResultsTest"), rw, req, prms)
+	ctx, err := app.NewPutLogResultsContext(goaCtx, req, ctrl.Service)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := ctrl.PutLog(ctx); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return rw
+}
+
+func TestPutLog(t *testing.T) {
+	rawBody := func(r io.Reader) func() (string, io.Reader) {
+		return func() (string, io.Reader) { return "application/octet-stream", r }
+	}
+	multipartBody := func(field string, logs []byte) func() (string, io.Reader) {
+		return func() (string, io.Reader) {
+			var buf bytes.Buffer
+			w := multipart.NewWriter(&buf)
+			if err := w.WriteField("comment", "ignored"); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			fw, err := w.CreateFormFile(field, "check.log")
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			fw.Write(logs)
+			w.Close()
+			return w.FormDataContentType(), &buf
+		}
+	}
+
+	logs := []byte("check output\n")
+	key := "dt=2019-11-01/scan=e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0/e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.log"
+
+	testCases := []struct {
+		name       string
+		path       string
+		body       func() (string, io.Reader)
+		maxLogSize int64
+		status     int
+		code       string
+		stored     []byte
+	}{
+		{
+			name:       "raw body",
+			path:       putLogPath,
+			body:       rawBody(bytes.NewReader(logs)),
+			maxLogSize: DefaultMaxLogSize,
+			status:     http.StatusCreated,
+			stored:     logs,
+		},
+		{
+			name:       "multipart body",
+			path:       putLogPath,
+			body:       multipartBody("log", logs),
+			maxLogSize: DefaultMaxLogSize,
+			status:     http.StatusCreated,
+			stored:     logs,
+		},
+		{
+			name:       "multipart body without log",
+			path:       putLogPath,
+			body:       multipartBody("other", logs),
+			maxLogSize: DefaultMaxLogSize,
+			status:     http.StatusBadRequest,
+			code:       "missing_fields",
+		},
+		{
+			name:       "too large with content length",
+			path:       putLogPath,
+			body:       rawBody(bytes.NewReader(logs)),
+			maxLogSize: int64(len(logs) - 1),
+			status:     http.StatusRequestEntityTooLarge,
+			code:       "too_large",
+		},
+		{
+			name:       "too large without content length",
+			path:       putLogPath,
+			body:       rawBody(io.MultiReader(bytes.NewReader(logs))),
+			maxLogSize: int64(len(logs) - 1),
+			status:     http.StatusRequestEntityTooLarge,
+			code:       "too_large",
+		},
+		{
+			name:       "exactly the maximum size",
+			path:       putLogPath,
+			body:       rawBody(io.MultiReader(bytes.NewReader(logs))),
+			maxLogSize: int64(len(logs)),
+			status:     http.StatusCreated,
+			stored:     logs,
+		},
+		{
+			name:       "invalid scan",
+			path:       strings.Replace(putLogPath, "scan=e0c1ac1a", "scan=zzz", 1),
+			body:       rawBody(bytes.NewReader(logs)),
+			maxLogSize: DefaultMaxLogSize,
+			status:     http.StatusBadRequest,
+			code:       "invalid_field",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			st := storage.NewMemoryStorage(storage.Config{
+				BucketLogs: "logs",
+				LinkBase:   "http://results/v1",
+			})
+			service := goa.New("vulcan-results")
+			service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+			ctrl := NewResultsController(service, st)
+			ctrl.MaxLogSize = tc.maxLogSize
+
+			contentType, body := tc.body()
+			rw := putLog(t, ctrl, tc.path, contentType, body)
+			if rw.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, rw.Code, rw.Body)
+			}
+
+			if tc.code != "" {
+				var eresp goa.ErrorResponse
+				if err := json.Unmarshal(rw.Body.Bytes(), &eresp); err != nil {
+					t.Fatalf("expected error document, got: %s", rw.Body)
+				}
+				if eresp.Code != tc.code {
+					t.Fatalf("expected error code %s, got: %s", tc.code, eresp.Code)
+				}
+			}
+
+			stored, ok := st.Object("logs", key)
+			if tc.stored == nil {
+				if ok {
+					t.Fatalf("expected log to not be stored, got: %q", stored)
+				}
+				return
+			}
+			if !bytes.Equal(stored, tc.stored) {
+				t.Fatalf("expected stored log %q, got: %q", tc.stored, stored)
+			}
+			if loc := rw.Header().Get("Location"); loc != "http://results/v1/logs/"+key {
+				t.Fatalf("unexpected location: %s", loc)
+			}
+		})
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	ctrl := NewResultsController(goa.New("vulcan-results"), storageMock{})
+	testCases := []struct {
+		method, path string
+		want         int64
+	}{
+		{http.MethodPost, "/v1/report", DefaultMaxReportSize},
+		{http.MethodPost, "/v1/raw", DefaultMaxReportSize},
+		{http.MethodPut, "/v1/logs/dt=2019-11-01/scan=id/check.log", DefaultMaxLogSize},
+	}
+	for _, tc := range testCases {
+		if got := ctrl.MaxBodySize(httptest.NewRequest(tc.method, tc.path, nil)); got != tc.want {
+			t.Fatalf("expected a maximum size of %d for %s, got: %d", tc.want, tc.path, got)
+		}
+	}
+}
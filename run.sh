@@ -4,10 +4,34 @@
 
 export PORT=${PORT:-8080}
 export DEBUG=${DEBUG:-false}
+export MAX_LOG_SIZE=${MAX_LOG_SIZE:-1073741824}
+export MAX_REPORT_SIZE=${MAX_REPORT_SIZE:-33554432}
+export REDIRECT_DOWNLOADS=${REDIRECT_DOWNLOADS:-false}
+export PRESIGNED_LINKS=${PRESIGNED_LINKS:-false}
+export PRESIGN_EXPIRY=${PRESIGN_EXPIRY:-15m}
+export SHUTDOWN_TIMEOUT=${SHUTDOWN_TIMEOUT:-30s}
+export SHUTDOWN_DELAY=${SHUTDOWN_DELAY:-10s}
+export AUTH_MAX_CLOCK_SKEW=${AUTH_MAX_CLOCK_SKEW:-5m}
+export TLS_CLIENT_AUTH=${TLS_CLIENT_AUTH:-require}
+export TLS_MIN_VERSION=${TLS_MIN_VERSION:-1.2}
 export PATH_STYLE=${PATH_STYLE:-false}
+export STORAGE_BACKEND=${STORAGE_BACKEND:-s3}
+export S3_MAX_RETRIES=${S3_MAX_RETRIES:-3}
+export S3_CALL_TIMEOUT=${S3_CALL_TIMEOUT:-30s}
+export S3_BREAKER_THRESHOLD=${S3_BREAKER_THRESHOLD:-5}
+export SSE_REPORTS_MODE=${SSE_REPORTS_MODE:-none}
+export SSE_REPORTS_BUCKET_KEY=${SSE_REPORTS_BUCKET_KEY:-false}
+export SSE_LOGS_MODE=${SSE_LOGS_MODE:-none}
+export SSE_LOGS_BUCKET_KEY=${SSE_LOGS_BUCKET_KEY:-false}
+export ENVELOPE_ALLOW_PLAINTEXT=${ENVELOPE_ALLOW_PLAINTEXT:-false}
+export READINESS_CANARY=${READINESS_CANARY:-false}
+export SPOOL_DRAIN_TIMEOUT=${SPOOL_DRAIN_TIMEOUT:-30s}
 export DOGSTATSD_ENABLED=${DOGSTATSD_ENABLED:-false}
+export PROMETHEUS_ENABLED=${PROMETHEUS_ENABLED:-false}
 
 # Apply env variables
 cat config.toml | envsubst > run.toml
 
-./vulcan-results run.toml
+# Replace the shell so the service receives the termination signals and
+# can drain the spool.
+exec ./vulcan-results run.toml
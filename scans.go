@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"time"
+
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app"
+	"github.com/adevinta/vulcan-results/auth"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// ScansController implements the scans resource.
+type ScansController struct {
+	*goa.Controller
+	storage storage.Storage
+
+	// Policy, if not nil, authorizes the principals to list scans, and
+	// filters out the scans not granted to them.
+	Policy *auth.Policy
+}
+
+// NewScansController creates a scans controller.
+func NewScansController(service *goa.Service, s storage.Storage) *ScansController {
+	return &ScansController{Controller: service.NewController("ScansController"), storage: s}
+}
+
+// List runs the list action.
+func (c *ScansController) List(ctx *app.ListScansContext) error {
+	to := ctx.From
+	if ctx.To != nil {
+		to = *ctx.To
+	}
+	goa.LogInfo(ctx, "Listing scans", "from", ctx.From, "to", to)
+
+	granted, err := c.Policy.AuthorizeList(ctx)
+	if err != nil {
+		goa.LogError(ctx, err.Error())
+		return listError(ctx, err)
+	}
+
+	fromDate, err := time.Parse("2006-01-02", ctx.From)
+	if err != nil {
+		return listError(ctx, invalidFieldError("from", err))
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return listError(ctx, invalidFieldError("to", err))
+	}
+
+	sctx, cancel := storageContext(ctx)
+	defer cancel()
+
+	scans, err := c.storage.ListScans(sctx, fromDate, toDate)
+	if err != nil {
+		goa.LogError(ctx, err.Error())
+		return listError(ctx, err)
+	}
+
+	res := app.ScanSummaryCollection{}
+	for _, s := range scans {
+		if !granted(s.ScanID) {
+			continue
+		}
+		res = append(res, &app.ScanSummary{
+			ScanID:     s.ScanID,
+			Date:       s.Date.Format("2006-01-02"),
+			Checks:     s.Checks,
+			Vulnerable: s.Vulnerable,
+		})
+	}
+
+	return ctx.OK(res)
+}
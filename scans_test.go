@@ -0,0 +1,144 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goadesign/goa"
+
+	"github.com/adevinta/vulcan-results/app/test"
+	"github.com/adevinta/vulcan-results/auth"
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+func TestListScans(t *testing.T) {
+	st := storage.NewMemoryStorage(storage.Config{
+		BucketReports:           "reports",
+		BucketVulnerableReports: "vulnerable-reports",
+		BucketLogs:              "logs",
+		LinkBase:                "http://results/v1",
+	})
+	startedAt := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := st.SaveReports(context.Background(), scanID.String(), checkID.String(), startedAt, []byte("report"), true, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	service := goa.New("vulcan-results")
+	ctrl := NewScansController(service, st)
+
+	_, scans := test.ListScansOK(t, nil, service, ctrl, "2019-11-01", nil)
+	if len(scans) != 1 {
+		t.Fatalf("expected one scan, got: %+v", scans)
+	}
+	s := scans[0]
+	if s.ScanID != scanID.String() || s.Date != "2019-11-01" || s.Checks != 1 || !s.Vulnerable {
+		t.Fatalf("unexpected scan summary: %+v", s)
+	}
+
+	to := "2019-11-02"
+	_, scans = test.ListScansOK(t, nil, service, ctrl, "2019-11-02", &to)
+	if len(scans) != 0 {
+		t.Fatalf("expected no scans, got: %+v", scans)
+	}
+}
+
+func TestListScansPolicy(t *testing.T) {
+	date := time.Date(2019, time.November, 1, 0, 0, 0, 0, time.UTC)
+	service := goa.New("vulcan-results")
+	ctrl := NewScansController(service, storageMock{scans: []storage.ScanSummary{
+		{ScanID: scanID.String(), Date: date, Checks: 1},
+		{ScanID: "other-scan", Date: date, Checks: 2},
+	}})
+	ctrl.Policy = loadTestPolicy(t)
+
+	teamA := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "team-a"})
+	teamB := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "team-b"})
+
+	// The scans not granted are filtered out.
+	_, scans := test.ListScansOK(t, teamA, service, ctrl, "2019-11-01", nil)
+	if len(scans) != 1 || scans[0].ScanID != scanID.String() {
+		t.Fatalf("expected only the granted scan, got: %+v", scans)
+	}
+	_, err := test.ListScansForbidden(t, teamB, service, ctrl, "2019-11-01", nil)
+	checkErrorCode(t, err, "forbidden")
+}
+
+func TestListScansErrors(t *testing.T) {
+	before := "2019-10-31"
+	tooLate := "2019-12-31"
+	invalid := "2019-13-01"
+
+	testCases := []struct {
+		name   string
+		to     *string
+		err    error
+		code   string
+		fields []string
+		fErr   func(t *testing.T, ctrl *ScansController, service *goa.Service, to *string) error
+	}{
+		{
+			name:   "range ends before it starts",
+			to:     &before,
+			code:   "invalid_field",
+			fields: []string{"from", "to"},
+			fErr: func(t *testing.T, ctrl *ScansController, service *goa.Service, to *string) error {
+				_, err := test.ListScansBadRequest(t, nil, service, ctrl, "2019-11-01", to)
+				return err
+			},
+		},
+		{
+			name:   "range too long",
+			to:     &tooLate,
+			code:   "invalid_field",
+			fields: []string{"from", "to"},
+			fErr: func(t *testing.T, ctrl *ScansController, service *goa.Service, to *string) error {
+				_, err := test.ListScansBadRequest(t, nil, service, ctrl, "2019-11-01", to)
+				return err
+			},
+		},
+		{
+			name:   "invalid date",
+			to:     &invalid,
+			code:   "invalid_field",
+			fields: []string{"to"},
+			fErr: func(t *testing.T, ctrl *ScansController, service *goa.Service, to *string) error {
+				_, err := test.ListScansBadRequest(t, nil, service, ctrl, "2019-11-01", to)
+				return err
+			},
+		},
+		{
+			name: "storage unavailable",
+			err:  fmt.Errorf("%w: SlowDown", storage.ErrUnavailable),
+			code: "storage_unavailable",
+			fErr: func(t *testing.T, ctrl *ScansController, service *goa.Service, to *string) error {
+				_, err := test.ListScansServiceUnavailable(t, nil, service, ctrl, "2019-11-01", to)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			service := goa.New("vulcan-results")
+			var st storage.Storage = storageMock{err: tc.err}
+			if tc.err == nil {
+				st = storage.NewMemoryStorage(storage.Config{})
+			}
+			ctrl := NewScansController(service, st)
+
+			err := tc.fErr(t, ctrl, service, tc.to)
+			checkErrorCode(t, err, tc.code)
+			if tc.fields != nil {
+				checkErrorFields(t, err, tc.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+// Package spool implements a durable local queue where the results that
+// could not be stored are kept until they can be replayed into the
+// storage.
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/gofrs/uuid"
+
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+const (
+	itemExt = ".json"
+	tmpExt  = ".tmp"
+
+	// quarantineDir is the directory, inside the directory of the
+	// spool, where the items that can never be stored are moved to.
+	quarantineDir = "quarantine"
+)
+
+// Kinds of the spooled results.
+const (
+	KindReport = "report"
+	KindLog    = "log"
+)
+
+// errCorrupt is returned when a spooled item can not be decoded.
+var errCorrupt = errors.New("corrupt item")
+
+// Item is a result waiting to be stored.
+type Item struct {
+	Kind       string    `json:"kind"`
+	ScanID     string    `json:"scan_id"`
+	CheckID    string    `json:"check_id"`
+	StartedAt  time.Time `json:"started_at"`
+	Vulnerable bool      `json:"vulnerable,omitempty"`
+	Content    []byte    `json:"content"`
+	SpooledAt  time.Time `json:"spooled_at"`
+
+	// Checksum, EndTime and Force are the options of the report write.
+	Checksum string    `json:"checksum,omitempty"`
+	EndTime  time.Time `json:"end_time"`
+	Force    bool      `json:"force,omitempty"`
+}
+
+// save stores the item in st. A report older than the stored one is
+// discarded, as it was superseded while it was waiting in the spool.
+func (it Item) save(ctx context.Context, st storage.Storage) error {
+	var err error
+	switch it.Kind {
+	case KindReport:
+		opts := storage.SaveOptions{Checksum: it.Checksum, EndTime: it.EndTime, Force: it.Force}
+		_, err = st.SaveReports(ctx, it.ScanID, it.CheckID, it.StartedAt, it.Content, it.Vulnerable, opts)
+		if errors.Is(err, storage.ErrConflict) {
+			err = nil
+		}
+	case KindLog:
+		_, err = st.SaveLogs(ctx, it.ScanID, it.CheckID, it.StartedAt, it.Content)
+	default:
+		err = fmt.Errorf("unknown kind %q", it.Kind)
+	}
+	return err
+}
+
+// Quarantined is an item moved to the quarantine directory of a spool
+// because it can never be stored.
+type Quarantined struct {
+	// Path is the path of the item in the quarantine directory.
+	Path string
+	// Err is the error the item failed with.
+	Err error
+}
+
+// Stats describe the items waiting in a spool.
+type Stats struct {
+	// Depth is the number of items in the spool.
+	Depth int
+	// Oldest is the time the oldest item was spooled. It is zero when
+	// the spool is empty.
+	Oldest time.Time
+}
+
+// Spool is a queue of items stored in a directory, one file per item.
+// The file names start with the time the item was spooled, so items are
+// replayed in the order they were spooled.
+type Spool struct {
+	dir string
+
+	// mu serializes the replays so an item is never stored twice.
+	mu    sync.Mutex
+	added chan struct{}
+}
+
+// New returns a Spool that keeps its items in dir, creating it and its
+// quarantine directory if they do not exist. Items only partially
+// written before a crash are removed.
+func New(dir string) (*Spool, error) {
+	if err := os.MkdirAll(filepath.Join(dir, quarantineDir), 0700); err != nil {
+		return nil, err
+	}
+	tmps, err := filepath.Glob(filepath.Join(dir, "*"+tmpExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, tmp := range tmps {
+		if err := os.Remove(tmp); err != nil {
+			return nil, err
+		}
+	}
+	return &Spool{dir: dir, added: make(chan struct{}, 1)}, nil
+}
+
+// Put adds an item to the spool. The item is synced to disk before Put
+// returns.
+func (s *Spool) Put(it Item) error {
+	if it.SpooledAt.IsZero() {
+		it.SpooledAt = time.Now()
+	}
+	content, err := json.Marshal(it)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%020d-%s%s", it.SpooledAt.UnixNano(), id, itemExt)
+	tmp := filepath.Join(s.dir, name+tmpExt)
+	if err := writeSync(tmp, content); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	select {
+	case s.added <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Added returns a channel that receives a value when items are added to
+// the spool.
+func (s *Spool) Added() <-chan struct{} {
+	return s.added
+}
+
+// Stats returns the stats of the items in the spool.
+func (s *Spool) Stats() (Stats, error) {
+	names, err := s.names()
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{Depth: len(names)}
+	if len(names) > 0 {
+		it, err := s.read(names[0])
+		if err == nil {
+			stats.Oldest = it.SpooledAt
+		}
+	}
+	return stats, nil
+}
+
+// Replay stores the items of the spool in st, oldest first, removing
+// them from the spool once stored. It stops at the first item that can
+// not be stored because the storage is unavailable, returning the number
+// of items replayed and the error. Items that can not be decoded, or
+// that the storage refuses for any other reason, are moved to the
+// quarantine directory so they do not block the spool, and returned.
+func (s *Spool) Replay(ctx context.Context, st storage.Storage) (int, []Quarantined, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names, err := s.names()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		n           int
+		quarantined []Quarantined
+	)
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return n, quarantined, err
+		}
+		it, err := s.read(name)
+		if err != nil && !errors.Is(err, errCorrupt) {
+			return n, quarantined, err
+		}
+		if err == nil {
+			err = it.save(ctx, st)
+		}
+		if err != nil && retryable(ctx, err) {
+			return n, quarantined, fmt.Errorf("replaying %s: %w", name, err)
+		}
+		if err != nil {
+			q, qerr := s.quarantine(name, err)
+			if qerr != nil {
+				return n, quarantined, qerr
+			}
+			quarantined = append(quarantined, q)
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+			return n, quarantined, err
+		}
+		n++
+	}
+	return n, quarantined, nil
+}
+
+// retryable tells whether the replay of an item that failed with err
+// must be retried later, because the storage is unavailable or the
+// replay was interrupted, instead of the item being quarantined.
+func retryable(ctx context.Context, err error) bool {
+	return errors.Is(err, storage.ErrUnavailable) || ctx.Err() != nil
+}
+
+// quarantine moves the item with the given name, that failed with err,
+// to the quarantine directory.
+func (s *Spool) quarantine(name string, err error) (Quarantined, error) {
+	path := filepath.Join(s.dir, quarantineDir, name)
+	if rerr := os.Rename(filepath.Join(s.dir, name), path); rerr != nil {
+		return Quarantined{}, rerr
+	}
+	return Quarantined{Path: path, Err: err}, nil
+}
+
+// names returns the file names of the items in the spool, oldest first.
+func (s *Spool) names() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), itemExt) {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// read returns the item stored in the file with the given name.
+func (s *Spool) read(name string) (Item, error) {
+	content, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return Item{}, err
+	}
+	var it Item
+	if err := json.Unmarshal(content, &it); err != nil {
+		return Item{}, fmt.Errorf("%w: %w", errCorrupt, err)
+	}
+	if it.Kind != KindReport && it.Kind != KindLog {
+		return Item{}, fmt.Errorf("%w: unknown kind %q", errCorrupt, it.Kind)
+	}
+	return it, nil
+}
+
+// writeSync writes content to a new file and syncs it to disk.
+func writeSync(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package spool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	metrics "github.com/adevinta/vulcan-metrics-client"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+const (
+	scanID  = "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0"
+	checkID = "7ae7f3a2-98b2-4b0c-9c3e-52d4a1c2d7e1"
+)
+
+var startedAt = time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+
+// failingStorage is a storage where results can not be saved.
+type failingStorage struct {
+	storage.Storage
+}
+
+func (failingStorage) SaveReports(ctx context.Context, scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool, opts storage.SaveOptions) (string, error) {
+	return "", fmt.Errorf("%w: SlowDown", storage.ErrUnavailable)
+}
+
+func (failingStorage) SaveLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs []byte) (string, error) {
+	return "", fmt.Errorf("%w: SlowDown", storage.ErrUnavailable)
+}
+
+// refusingStorage is a storage that refuses the reports of a check with
+// an error that is not fixed by retrying.
+type refusingStorage struct {
+	*storage.MemoryStorage
+	checkID string
+}
+
+func (st refusingStorage) SaveReports(ctx context.Context, scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool, opts storage.SaveOptions) (string, error) {
+	if checkID == st.checkID {
+		return "", fmt.Errorf("%w: invalid check ID", storage.ErrInvalidKey)
+	}
+	return st.MemoryStorage.SaveReports(ctx, scanID, checkID, startedAt, report, vulnerable, opts)
+}
+
+// metricsMock records the pushed metrics.
+type metricsMock struct {
+	mu     sync.Mutex
+	pushed map[string]float64
+}
+
+func (m *metricsMock) Push(metric metrics.Metric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pushed == nil {
+		m.pushed = map[string]float64{}
+	}
+	m.pushed[metric.Name] = metric.Value
+}
+
+func (m *metricsMock) PushWithRate(ratedMetric metrics.RatedMetric) {
+	m.Push(ratedMetric.Metric)
+}
+
+func (m *metricsMock) value(name string) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.pushed[name]
+	return v, ok
+}
+
+func newMemoryStorage() *storage.MemoryStorage {
+	return storage.NewMemoryStorage(storage.Config{
+		BucketReports:           "reports",
+		BucketVulnerableReports: "vulnerable-reports",
+		BucketLogs:              "logs",
+	})
+}
+
+func newSpool(t *testing.T, items ...Item) *Spool {
+	t.Helper()
+
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, it := range items {
+		if err := s.Put(it); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	return s
+}
+
+func reportItem(spooledAt time.Time) Item {
+	return Item{
+		Kind:       KindReport,
+		ScanID:     scanID,
+		CheckID:    checkID,
+		StartedAt:  startedAt,
+		Vulnerable: true,
+		Content:    []byte("report"),
+		SpooledAt:  spooledAt,
+	}
+}
+
+func logItem(spooledAt time.Time) Item {
+	return Item{
+		Kind:      KindLog,
+		ScanID:    scanID,
+		CheckID:   checkID,
+		StartedAt: startedAt,
+		Content:   []byte("log"),
+		SpooledAt: spooledAt,
+	}
+}
+
+func checkDepth(t *testing.T, s *Spool, depth int) {
+	t.Helper()
+
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if stats.Depth != depth {
+		t.Fatalf("expected %d items in the spool, got: %d", depth, stats.Depth)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	oldest := time.Now().Add(-time.Hour)
+	s := newSpool(t, logItem(time.Now()), reportItem(oldest))
+
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if stats.Depth != 2 || !stats.Oldest.Equal(oldest) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	st := newMemoryStorage()
+	n, _, err := s.Replay(context.Background(), st)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 items replayed, got: %d", n)
+	}
+	checkDepth(t, s, 0)
+
+	key := "dt=2019-11-01/scan=" + scanID + "/" + checkID
+	objects := []struct {
+		bucket, key, content string
+	}{
+		{"reports", key + ".json", "report"},
+		{"vulnerable-reports", key + ".json.gz", ""},
+		{"logs", key + ".log", "log"},
+	}
+	for _, o := range objects {
+		content, ok := st.Object(o.bucket, o.key)
+		if !ok {
+			t.Fatalf("expected %s/%s to be stored", o.bucket, o.key)
+		}
+		if o.content != "" && string(content) != o.content {
+			t.Fatalf("unexpected content of %s/%s: %q", o.bucket, o.key, content)
+		}
+	}
+}
+
+func TestReplayFailure(t *testing.T) {
+	s := newSpool(t, reportItem(time.Time{}), logItem(time.Time{}))
+
+	n, _, err := s.Replay(context.Background(), failingStorage{})
+	if !errors.Is(err, storage.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected no items replayed, got: %d", n)
+	}
+	checkDepth(t, s, 2)
+}
+
+func TestReplaySuperseded(t *testing.T) {
+	endTime := startedAt.Add(time.Hour)
+	st := newMemoryStorage()
+	opts := storage.SaveOptions{EndTime: endTime}
+	if _, err := st.SaveReports(context.Background(), scanID, checkID, startedAt, []byte("newer"), true, opts); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	it := reportItem(time.Time{})
+	it.EndTime = endTime.Add(-time.Minute)
+	s := newSpool(t, it)
+	if _, _, err := s.Replay(context.Background(), st); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkDepth(t, s, 0)
+
+	key := "dt=2019-11-01/scan=" + scanID + "/" + checkID + ".json"
+	if content, _ := st.Object("reports", key); string(content) != "newer" {
+		t.Fatalf("expected the newer report to be kept, got: %q", content)
+	}
+}
+
+func TestReplayCorrupt(t *testing.T) {
+	s := newSpool(t, reportItem(time.Time{}))
+	corrupt := filepath.Join(s.dir, "00000000000000000000-corrupt.json")
+	if err := ioutil.WriteFile(corrupt, []byte("not json"), 0600); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkDepth(t, s, 2)
+
+	n, quarantined, err := s.Replay(context.Background(), newMemoryStorage())
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 item replayed, got: %d", n)
+	}
+	checkDepth(t, s, 0)
+	if len(quarantined) != 1 || !errors.Is(quarantined[0].Err, errCorrupt) {
+		t.Fatalf("expected the corrupt item to be quarantined, got: %v", quarantined)
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, quarantineDir, filepath.Base(corrupt))); err != nil {
+		t.Fatalf("expected the corrupt item to be kept aside, got: %v", err)
+	}
+}
+
+func TestReplayPoison(t *testing.T) {
+	poison := reportItem(time.Now().Add(-time.Hour))
+	poison.CheckID = "poison"
+	s := newSpool(t, poison, reportItem(time.Now()), logItem(time.Now()))
+	st := refusingStorage{MemoryStorage: newMemoryStorage(), checkID: "poison"}
+	mc := &metricsMock{}
+
+	// The item the storage refuses does not block the ones behind it.
+	w := NewWorker(Config{}, s, st, logrus.NewEntry(logrus.New()), mc)
+	if err := w.Drain(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkDepth(t, s, 0)
+	if n, _ := mc.value(metricQuarantined); n != 1 {
+		t.Fatalf("expected 1 item quarantined to be reported, got: %v", n)
+	}
+	if _, ok := st.Object("logs", "dt=2019-11-01/scan="+scanID+"/"+checkID+".log"); !ok {
+		t.Fatalf("expected the items behind the poison item to be stored")
+	}
+	names, err := filepath.Glob(filepath.Join(s.dir, quarantineDir, "*"+itemExt))
+	if err != nil || len(names) != 1 {
+		t.Fatalf("expected the poison item in the quarantine directory, got: %v, %v", names, err)
+	}
+	it, err := (&Spool{dir: filepath.Join(s.dir, quarantineDir)}).read(filepath.Base(names[0]))
+	if err != nil || it.CheckID != "poison" {
+		t.Fatalf("expected the poison item to be quarantined, got: %+v, %v", it, err)
+	}
+}
+
+func TestNewRemovesPartialItems(t *testing.T) {
+	dir := t.TempDir()
+	tmp := filepath.Join(dir, "00000000000000000000-partial.json"+tmpExt)
+	if err := ioutil.WriteFile(tmp, []byte("{"), 0600); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	if _, err := New(dir); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Fatalf("expected the partial item to be removed, got: %v", err)
+	}
+}
+
+func TestWorkerRun(t *testing.T) {
+	s := newSpool(t)
+	mc := &metricsMock{}
+	w := NewWorker(Config{PollInterval: time.Hour}, s, newMemoryStorage(), logrus.NewEntry(logrus.New()), mc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if err := s.Put(reportItem(time.Time{})); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats, err := s.Stats()
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if stats.Depth == 0 {
+			if depth, ok := mc.value(metricDepth); ok && depth == 0 {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the spool to be drained, got: %+v", stats)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWorkerDrain(t *testing.T) {
+	s := newSpool(t, reportItem(time.Time{}))
+	mc := &metricsMock{}
+
+	w := NewWorker(Config{}, s, failingStorage{}, logrus.NewEntry(logrus.New()), mc)
+	if err := w.Drain(context.Background()); !errors.Is(err, storage.ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got: %v", err)
+	}
+	if failed, _ := mc.value(metricReplayFailed); failed != 1 {
+		t.Fatalf("expected a replay failure to be reported, got: %v", failed)
+	}
+	if depth, _ := mc.value(metricDepth); depth != 1 {
+		t.Fatalf("expected a depth of 1, got: %v", depth)
+	}
+
+	w = NewWorker(Config{}, s, newMemoryStorage(), logrus.NewEntry(logrus.New()), nil)
+	if err := w.Drain(context.Background()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkDepth(t, s, 0)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package spool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	metrics "github.com/adevinta/vulcan-metrics-client"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// Default values of the Config fields.
+const (
+	DefaultMinBackoff   = time.Second
+	DefaultMaxBackoff   = 5 * time.Minute
+	DefaultPollInterval = 30 * time.Second
+	DefaultDrainTimeout = 30 * time.Second
+)
+
+const (
+	// Metric names
+	metricDepth        = "vulcan.spool.depth"
+	metricOldestAge    = "vulcan.spool.oldest_age"
+	metricReplayFailed = "vulcan.spool.replay.failed"
+	metricQuarantined  = "vulcan.spool.quarantined"
+
+	// Metric tags
+	tagComponent = "component:results"
+)
+
+// Config represents the configuration of the spool.
+type Config struct {
+	// Dir is the directory where the spooled items are stored. The
+	// spool is disabled if it is empty. The items are stored as they
+	// were received, unencrypted even if the storage encrypts them.
+	Dir string
+	// MinBackoff and MaxBackoff bound the time the worker waits before
+	// retrying a failed replay. The wait doubles after every failure.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// PollInterval is the time the worker waits before checking the
+	// spool again when it is empty.
+	PollInterval time.Duration
+	// DrainTimeout is the maximum time spent draining the spool on
+	// shutdown.
+	DrainTimeout time.Duration
+}
+
+// Worker replays the items of a spool into a storage in the background.
+type Worker struct {
+	spool   *Spool
+	storage storage.Storage
+	logger  *logrus.Entry
+	metrics metrics.Client
+	conf    Config
+}
+
+// NewWorker returns a Worker that replays the items of s into st. Zero
+// fields of c take their default values. If mc is not nil the stats of
+// the spool are pushed to it.
+func NewWorker(c Config, s *Spool, st storage.Storage, l *logrus.Entry, mc metrics.Client) *Worker {
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = DefaultMinBackoff
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	if c.DrainTimeout <= 0 {
+		c.DrainTimeout = DefaultDrainTimeout
+	}
+	return &Worker{spool: s, storage: st, logger: l, metrics: mc, conf: c}
+}
+
+// Run replays the items of the spool until ctx is done. Failed replays
+// are retried with exponential backoff.
+func (w *Worker) Run(ctx context.Context) {
+	backoff := time.Duration(0)
+	for {
+		wait := w.conf.PollInterval
+		if err := w.replay(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if backoff == 0 {
+				backoff = w.conf.MinBackoff
+			} else if backoff *= 2; backoff > w.conf.MaxBackoff {
+				backoff = w.conf.MaxBackoff
+			}
+			w.logger.WithError(err).WithField("backoff", backoff).Warn("spool replay failed")
+			wait = backoff
+		} else {
+			backoff = 0
+		}
+
+		// While the storage is failing new items do not shorten the
+		// backoff.
+		added := w.spool.Added()
+		if backoff > 0 {
+			added = nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-added:
+			timer.Stop()
+		}
+	}
+}
+
+// Drain replays the items of the spool once, giving up after the
+// configured drain timeout. It returns an error if items remain in the
+// spool.
+func (w *Worker) Drain(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, w.conf.DrainTimeout)
+	defer cancel()
+
+	err := w.replay(ctx)
+	stats, serr := w.spool.Stats()
+	if serr != nil {
+		return errors.Join(err, serr)
+	}
+	if stats.Depth == 0 {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%d items left in the spool: %w", stats.Depth, err)
+	}
+	return fmt.Errorf("%d items left in the spool", stats.Depth)
+}
+
+// replay replays the items of the spool and pushes its stats.
+func (w *Worker) replay(ctx context.Context) error {
+	n, quarantined, err := w.spool.Replay(ctx, w.storage)
+	if n > 0 {
+		w.logger.WithField("items", n).Info("spooled results replayed")
+	}
+	for _, q := range quarantined {
+		w.logger.WithError(q.Err).WithField("path", q.Path).Error("spooled result quarantined")
+	}
+	if len(quarantined) > 0 {
+		w.push(metricQuarantined, metrics.Count, float64(len(quarantined)))
+	}
+	if err != nil && ctx.Err() == nil {
+		w.push(metricReplayFailed, metrics.Count, 1)
+	}
+
+	stats, serr := w.spool.Stats()
+	if serr != nil {
+		w.logger.WithError(serr).Error("reading spool stats")
+		return err
+	}
+	w.push(metricDepth, metrics.Gauge, float64(stats.Depth))
+	var age time.Duration
+	if !stats.Oldest.IsZero() {
+		age = time.Since(stats.Oldest)
+	}
+	w.push(metricOldestAge, metrics.Gauge, age.Seconds())
+	return err
+}
+
+func (w *Worker) push(name string, typ metrics.Type, value float64) {
+	if w.metrics == nil {
+		return
+	}
+	w.metrics.Push(metrics.Metric{
+		Name:  name,
+		Typ:   typ,
+		Value: value,
+		Tags:  []string{tagComponent},
+	})
+}
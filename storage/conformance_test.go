@@ -0,0 +1,55 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+func TestS3StorageConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, c storage.Config) storagetest.Harness {
+		svc := storagetest.NewFakeS3()
+		l := logrus.New().WithFields(logrus.Fields{"test": t.Name()})
+		return storagetest.Harness{
+			Storage: storage.NewS3Storage(c, l, svc),
+			Object: func(bucket, key string) ([]byte, bool) {
+				obj, ok := svc.Object(bucket, key)
+				return obj.Body, ok
+			},
+		}
+	})
+}
+
+func TestFilesystemStorageConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, c storage.Config) storagetest.Harness {
+		c.Root = t.TempDir()
+		l := logrus.New().WithFields(logrus.Fields{"test": t.Name()})
+		s, err := storage.NewFilesystemStorage(c, l)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		return storagetest.Harness{
+			Storage: s,
+			Object: func(bucket, key string) ([]byte, bool) {
+				content, err := ioutil.ReadFile(filepath.Join(c.Root, bucket, filepath.FromSlash(key)))
+				return content, err == nil
+			},
+		}
+	})
+}
+
+func TestMemoryStorageConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, c storage.Config) storagetest.Harness {
+		s := storage.NewMemoryStorage(c)
+		return storagetest.Harness{Storage: s, Object: s.Object}
+	})
+}
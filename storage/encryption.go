@@ -0,0 +1,187 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Server-side encryption modes of the objects uploaded to S3.
+const (
+	EncryptionNone   = "none"
+	EncryptionAES256 = s3.ServerSideEncryptionAes256
+	EncryptionKMS    = s3.ServerSideEncryptionAwsKms
+)
+
+// BucketEncryption is the server-side encryption of the objects uploaded
+// to a bucket.
+type BucketEncryption struct {
+	// Mode is "none", "AES256" or "aws:kms". If empty, the default mode
+	// is used.
+	Mode string
+	// KMSKeyID is the ID, ARN or alias of the KMS key that encrypts the
+	// objects when the mode is "aws:kms". If empty, S3 uses the AWS
+	// managed key.
+	KMSKeyID string
+	// BucketKey enables S3 Bucket Keys when the mode is "aws:kms",
+	// reducing the calls S3 makes to KMS.
+	BucketKey bool
+}
+
+// EncryptionConfig configures the server-side encryption of the objects
+// uploaded by the S3 backend. The buckets without a mode use the
+// default settings.
+type EncryptionConfig struct {
+	Default           BucketEncryption
+	Reports           BucketEncryption
+	VulnerableReports BucketEncryption
+	Logs              BucketEncryption
+}
+
+// or returns e, or def if e has no mode.
+func (e BucketEncryption) or(def BucketEncryption) BucketEncryption {
+	if e.Mode == "" {
+		return def
+	}
+	return e
+}
+
+// validate checks the settings are consistent.
+func (e BucketEncryption) validate() error {
+	switch e.Mode {
+	case "", EncryptionNone, EncryptionAES256:
+		if e.KMSKeyID != "" || e.BucketKey {
+			return fmt.Errorf("KMS settings require the %q encryption mode", EncryptionKMS)
+		}
+	case EncryptionKMS:
+	default:
+		return fmt.Errorf("unknown encryption mode %q", e.Mode)
+	}
+	return nil
+}
+
+// s3Params returns the values of the ServerSideEncryption, SSEKMSKeyId
+// and BucketKeyEnabled params of the uploads.
+func (e BucketEncryption) s3Params() (sse, keyID *string, bucketKey *bool) {
+	switch e.Mode {
+	case EncryptionAES256:
+		return aws.String(EncryptionAES256), nil, nil
+	case EncryptionKMS:
+		sse = aws.String(EncryptionKMS)
+		if e.KMSKeyID != "" {
+			keyID = aws.String(e.KMSKeyID)
+		}
+		if e.BucketKey {
+			bucketKey = aws.Bool(true)
+		}
+		return sse, keyID, bucketKey
+	default:
+		return nil, nil, nil
+	}
+}
+
+// bucketSetting is the encryption of a configured bucket.
+type bucketSetting struct {
+	// name is the name of the setting in EncryptionConfig.
+	name   string
+	bucket string
+	enc    BucketEncryption
+}
+
+// bucketEncryptions returns the encryption of every configured bucket.
+func (c Config) bucketEncryptions() []bucketSetting {
+	e := c.Encryption
+	return []bucketSetting{
+		{"Reports", c.BucketReports, e.Reports.or(e.Default)},
+		{"VulnerableReports", c.BucketVulnerableReports, e.VulnerableReports.or(e.Default)},
+		{"Logs", c.BucketLogs, e.Logs.or(e.Default)},
+	}
+}
+
+// encryption returns the encryption of the objects uploaded to the
+// given bucket.
+func (c Config) encryption(bucket string) BucketEncryption {
+	for _, b := range c.bucketEncryptions() {
+		if b.bucket == bucket {
+			return b.enc
+		}
+	}
+	return c.Encryption.Default
+}
+
+// validateEncryption checks the encryption settings are consistent,
+// including that the settings sharing a bucket agree.
+func (c Config) validateEncryption() error {
+	if err := c.Encryption.Default.validate(); err != nil {
+		return fmt.Errorf("encryption Default: %w", err)
+	}
+	buckets := c.bucketEncryptions()
+	for i, b := range buckets {
+		if err := b.enc.validate(); err != nil {
+			return fmt.Errorf("encryption %s: %w", b.name, err)
+		}
+		for _, o := range buckets[:i] {
+			if o.bucket == b.bucket && o.enc != b.enc {
+				return fmt.Errorf("encryption %s and %s: different settings for the same bucket %q", o.name, b.name, b.bucket)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckEncryption validates the encryption settings of c, and checks
+// that the configured KMS keys are enabled and can be used to encrypt
+// the uploaded objects, so a misconfigured key is detected on startup
+// instead of failing every upload.
+func CheckEncryption(ctx context.Context, c Config, k kmsiface.KMSAPI) error {
+	if err := c.validateEncryption(); err != nil {
+		return err
+	}
+
+	checked := map[string]bool{}
+	for _, b := range c.bucketEncryptions() {
+		id := b.enc.KMSKeyID
+		if b.enc.Mode != EncryptionKMS || id == "" || checked[id] {
+			continue
+		}
+		if err := checkKMSKey(ctx, k, id); err != nil {
+			return fmt.Errorf("encryption %s: %w", b.name, err)
+		}
+		checked[id] = true
+	}
+	return nil
+}
+
+// checkKMSKey checks a KMS key can be used to encrypt S3 objects.
+func checkKMSKey(ctx context.Context, k kmsiface.KMSAPI, id string) error {
+	out, err := k.DescribeKeyWithContext(ctx, &kms.DescribeKeyInput{KeyId: aws.String(id)})
+	if err != nil {
+		return fmt.Errorf("describing KMS key %s: %w", id, err)
+	}
+	md := out.KeyMetadata
+	if state := aws.StringValue(md.KeyState); state != kms.KeyStateEnabled {
+		return fmt.Errorf("KMS key %s is %s", id, state)
+	}
+	if usage := aws.StringValue(md.KeyUsage); usage != kms.KeyUsageTypeEncryptDecrypt {
+		return fmt.Errorf("KMS key %s can not be used to encrypt, its usage is %s", id, usage)
+	}
+
+	// S3 generates a data key for every object it encrypts, so the
+	// service must be allowed to do it.
+	_, err = k.GenerateDataKeyWithContext(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(id),
+		KeySpec: aws.String(kms.DataKeySpecAes256),
+	})
+	if err != nil {
+		return fmt.Errorf("generating a data key with KMS key %s: %w", id, err)
+	}
+	return nil
+}
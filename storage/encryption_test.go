@@ -0,0 +1,201 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+// kmsMock is a KMS client that knows the keys in the map, with their
+// state. Generating data keys fails if denied is true.
+type kmsMock struct {
+	kmsiface.KMSAPI
+
+	keys   map[string]string
+	denied bool
+}
+
+func (m kmsMock) DescribeKeyWithContext(ctx aws.Context, in *kms.DescribeKeyInput, opts ...request.Option) (*kms.DescribeKeyOutput, error) {
+	state, ok := m.keys[aws.StringValue(in.KeyId)]
+	if !ok {
+		return nil, awserr.New(kms.ErrCodeNotFoundException, "Key does not exist", nil)
+	}
+	return &kms.DescribeKeyOutput{KeyMetadata: &kms.KeyMetadata{
+		KeyId:    in.KeyId,
+		KeyState: aws.String(state),
+		KeyUsage: aws.String(kms.KeyUsageTypeEncryptDecrypt),
+	}}, nil
+}
+
+func (m kmsMock) GenerateDataKeyWithContext(ctx aws.Context, in *kms.GenerateDataKeyInput, opts ...request.Option) (*kms.GenerateDataKeyOutput, error) {
+	if m.denied {
+		return nil, awserr.New("AccessDeniedException", "not authorized to perform kms:GenerateDataKey", nil)
+	}
+	return &kms.GenerateDataKeyOutput{KeyId: in.KeyId}, nil
+}
+
+func TestEncryptionUploads(t *testing.T) {
+	c := repairConfig
+	c.Encryption = storage.EncryptionConfig{
+		Default: storage.BucketEncryption{Mode: storage.EncryptionAES256},
+		Reports: storage.BucketEncryption{Mode: storage.EncryptionKMS, KMSKeyID: "reports-key", BucketKey: true},
+		Logs:    storage.BucketEncryption{Mode: storage.EncryptionKMS, KMSKeyID: "logs-key"},
+	}
+	svc := storagetest.NewFakeS3()
+	s := storage.NewS3Storage(c, logrus.New().WithFields(logrus.Fields{"test": t.Name()}), svc)
+
+	if err := saveVulnerableReport(s, "report"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	startedAt := time.Date(1984, time.April, 4, 13, 0, 0, 0, time.UTC)
+	if _, err := s.StreamLogs(context.Background(), repairScanID, repairCheckID, startedAt, bytes.NewReader([]byte("log"))); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	logKey := strings.TrimSuffix(repairKey, ".json") + ".log"
+	expected := []struct {
+		bucket, key string
+		enc         storagetest.FakeEncryption
+	}{
+		{"reports", repairKey, storagetest.FakeEncryption{
+			ServerSideEncryption: aws.String("aws:kms"),
+			SSEKMSKeyID:          aws.String("reports-key"),
+			BucketKeyEnabled:     aws.Bool(true),
+		}},
+		{"vulnerable-reports", repairKey + ".gz", storagetest.FakeEncryption{
+			ServerSideEncryption: aws.String("AES256"),
+		}},
+		{"logs", logKey, storagetest.FakeEncryption{
+			ServerSideEncryption: aws.String("aws:kms"),
+			SSEKMSKeyID:          aws.String("logs-key"),
+		}},
+	}
+	for _, e := range expected {
+		obj, ok := svc.Object(e.bucket, e.key)
+		if !ok {
+			t.Fatalf("expected %s/%s to exist", e.bucket, e.key)
+		}
+		got := obj.Encryption
+		if aws.StringValue(got.ServerSideEncryption) != aws.StringValue(e.enc.ServerSideEncryption) ||
+			aws.StringValue(got.SSEKMSKeyID) != aws.StringValue(e.enc.SSEKMSKeyID) ||
+			aws.BoolValue(got.BucketKeyEnabled) != aws.BoolValue(e.enc.BucketKeyEnabled) {
+			t.Fatalf("unexpected encryption of %s/%s: %q, %q, %v", e.bucket, e.key,
+				aws.StringValue(got.ServerSideEncryption), aws.StringValue(got.SSEKMSKeyID), aws.BoolValue(got.BucketKeyEnabled))
+		}
+	}
+}
+
+func TestCheckEncryption(t *testing.T) {
+	keys := map[string]string{
+		"enabled":  kms.KeyStateEnabled,
+		"disabled": kms.KeyStateDisabled,
+	}
+
+	testCases := []struct {
+		name       string
+		encryption storage.EncryptionConfig
+		denied     bool
+		err        string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "usable key",
+			encryption: storage.EncryptionConfig{
+				Default: storage.BucketEncryption{Mode: storage.EncryptionKMS, KMSKeyID: "enabled", BucketKey: true},
+				Logs:    storage.BucketEncryption{Mode: storage.EncryptionNone},
+			},
+		},
+		{
+			name: "AWS managed key",
+			encryption: storage.EncryptionConfig{
+				Default: storage.BucketEncryption{Mode: storage.EncryptionKMS},
+			},
+			denied: true,
+		},
+		{
+			name: "unknown key",
+			encryption: storage.EncryptionConfig{
+				Default: storage.BucketEncryption{Mode: storage.EncryptionKMS, KMSKeyID: "unknown"},
+			},
+			err: "describing KMS key unknown",
+		},
+		{
+			name: "disabled key",
+			encryption: storage.EncryptionConfig{
+				Logs: storage.BucketEncryption{Mode: storage.EncryptionKMS, KMSKeyID: "disabled"},
+			},
+			err: "KMS key disabled is Disabled",
+		},
+		{
+			name: "data keys denied",
+			encryption: storage.EncryptionConfig{
+				Logs: storage.BucketEncryption{Mode: storage.EncryptionKMS, KMSKeyID: "enabled"},
+			},
+			denied: true,
+			err:    "generating a data key",
+		},
+		{
+			name: "unknown mode",
+			encryption: storage.EncryptionConfig{
+				Reports: storage.BucketEncryption{Mode: "aws:kms:dsse"},
+			},
+			err: `unknown encryption mode "aws:kms:dsse"`,
+		},
+		{
+			name: "key without KMS",
+			encryption: storage.EncryptionConfig{
+				Default: storage.BucketEncryption{Mode: storage.EncryptionAES256, KMSKeyID: "enabled"},
+			},
+			err: "KMS settings require",
+		},
+		{
+			name: "shared bucket",
+			encryption: storage.EncryptionConfig{
+				Reports:           storage.BucketEncryption{Mode: storage.EncryptionAES256},
+				VulnerableReports: storage.BucketEncryption{Mode: storage.EncryptionNone},
+			},
+			err: "different settings for the same bucket",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := repairConfig
+			// Reports and vulnerable reports share the bucket, as in
+			// the default configuration.
+			c.BucketVulnerableReports = c.BucketReports
+			c.Encryption = tc.encryption
+
+			err := storage.CheckEncryption(context.Background(), c, kmsMock{keys: keys, denied: tc.denied})
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("expected error containing %q, got: %v", tc.err, err)
+			}
+		})
+	}
+}
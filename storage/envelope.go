@@ -0,0 +1,518 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+)
+
+// Layout of the objects encrypted by EnvelopeStorage. The content of an
+// object is split in segments sealed with AES-GCM using the data key of
+// the object, so objects can be encrypted and decrypted while they are
+// streamed, and ranges of them can be decrypted without downloading the
+// whole object. The data key, wrapped by a master key, is stored in the
+// user metadata of the object with the ID of the master key and the
+// nonce prefix of the segments.
+//
+// The nonce of a segment is the nonce prefix followed by the index of
+// the segment and a byte telling whether it is the last one, so
+// segments can not be reordered nor the object truncated. The bucket
+// and key of the object are authenticated with the wrapped data key and
+// every segment, so an object copied to another key can not be
+// decrypted. The vulnerable copy of a report is bound to the report.
+const (
+	keyIDSize        = 8
+	dataKeySize      = 32
+	wrappedKeySize   = 12 + dataKeySize + 16
+	noncePrefixSize  = 7
+	segmentSize      = 64 << 10
+	segmentTagSize   = 16
+	segmentCryptSize = segmentSize + segmentTagSize
+)
+
+// Keys of the user metadata that stores the envelope of an object, sent
+// to S3 as x-amz-meta-* headers.
+const (
+	metaEnvelopeKeyID      = "Envelope-Key-Id"
+	metaEnvelopeWrappedKey = "Envelope-Wrapped-Key"
+	metaEnvelopeNonce      = "Envelope-Nonce"
+)
+
+var (
+	// ErrUnknownKey is returned when an object is encrypted with a
+	// master key that is not in the keyring.
+	ErrUnknownKey = errors.New("unknown master key")
+	// ErrCorrupt is returned when an encrypted object can not be
+	// decrypted because it was modified or truncated.
+	ErrCorrupt = errors.New("corrupt encrypted object")
+	// ErrNotEncrypted is returned when an object that must be
+	// encrypted has no envelope.
+	ErrNotEncrypted = errors.New("object not encrypted")
+)
+
+// masterKey is a key that wraps the data keys of the objects.
+type masterKey struct {
+	id   [keyIDSize]byte
+	aead cipher.AEAD
+}
+
+// Keyring holds the master keys of the envelope encryption. The primary
+// key wraps the data keys of the new objects, and all of them unwrap
+// the data keys of the stored ones.
+type Keyring struct {
+	primary masterKey
+	keys    map[[keyIDSize]byte]masterKey
+}
+
+// NewKeyring returns a keyring with the given 32 bytes AES keys. The
+// first one is the primary key.
+func NewKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no master keys")
+	}
+	k := &Keyring{keys: map[[keyIDSize]byte]masterKey{}}
+	for i, key := range keys {
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("master key %d: expected %d bytes, got %d", i+1, dataKeySize, len(key))
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		mk := masterKey{aead: aead}
+		sum := sha256.Sum256(key)
+		copy(mk.id[:], sum[:])
+		if _, ok := k.keys[mk.id]; ok {
+			return nil, fmt.Errorf("master key %d: duplicated", i+1)
+		}
+		k.keys[mk.id] = mk
+		if i == 0 {
+			k.primary = mk
+		}
+	}
+	return k, nil
+}
+
+// LoadKeyring reads a keyring from a keyfile holding one base64 encoded
+// master key per line, the primary key first. Empty lines and lines
+// starting with # are ignored. To rotate the master key, a new key is
+// added at the top of the file and the old one is kept until the
+// stored objects are re-wrapped.
+func LoadKeyring(path string) (*Keyring, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys [][]byte
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid master key: %w", path, n, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	k, err := NewKeyring(keys...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+// Encrypt returns the content of the object with the given bucket and
+// key encrypted with a new data key wrapped by the primary key, and the
+// user metadata to store it with.
+func (k *Keyring) Encrypt(bucket, key string, content []byte) ([]byte, map[string]string, error) {
+	r, e, err := k.encryptReader(bucket, key, bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+	encrypted, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encrypted, e.metadata(), nil
+}
+
+// Decrypt returns the decrypted content of the object with the given
+// bucket and key, stored with the given user metadata. It fails with
+// ErrNotEncrypted if the metadata has no envelope, and with ErrCorrupt
+// if the object was encrypted for another bucket or key.
+func (k *Keyring) Decrypt(bucket, key string, content []byte, metadata map[string]string) ([]byte, error) {
+	e, ok, err := parseEnvelope(bucket, key, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotEncrypted
+	}
+	obj, err := k.decryptObject(&Object{
+		Body: ioutil.NopCloser(bytes.NewReader(content)),
+		Size: int64(len(content)),
+	}, e)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(obj.Body)
+}
+
+// envelope holds the wrapped data key of an encrypted object.
+type envelope struct {
+	keyID       [keyIDSize]byte
+	wrappedKey  []byte
+	noncePrefix []byte
+	// object identifies the object the envelope belongs to. It is not
+	// stored, but authenticated with the data key and the segments.
+	object []byte
+}
+
+// objectID returns the identifier of the object with the given bucket
+// and key. Bucket names can not contain slashes, so it is unambiguous.
+func objectID(bucket, key string) []byte {
+	return []byte(bucket + "/" + key)
+}
+
+// parseEnvelope parses the envelope stored in the user metadata of the
+// object with the given bucket and key. It returns false if the object
+// is not encrypted, and fails with ErrCorrupt if the envelope is
+// invalid.
+func parseEnvelope(bucket, key string, md map[string]string) (envelope, bool, error) {
+	keyID, okID := md[metaEnvelopeKeyID]
+	wrapped, okKey := md[metaEnvelopeWrappedKey]
+	nonce, okNonce := md[metaEnvelopeNonce]
+	if !okID && !okKey && !okNonce {
+		return envelope{}, false, nil
+	}
+
+	e := envelope{object: objectID(bucket, key)}
+	id, err := hex.DecodeString(keyID)
+	if err != nil || len(id) != keyIDSize {
+		return envelope{}, false, fmt.Errorf("%w: invalid %s %q", ErrCorrupt, metaEnvelopeKeyID, keyID)
+	}
+	copy(e.keyID[:], id)
+	e.wrappedKey, err = base64.StdEncoding.DecodeString(wrapped)
+	if err != nil || len(e.wrappedKey) != wrappedKeySize {
+		return envelope{}, false, fmt.Errorf("%w: invalid %s", ErrCorrupt, metaEnvelopeWrappedKey)
+	}
+	e.noncePrefix, err = base64.StdEncoding.DecodeString(nonce)
+	if err != nil || len(e.noncePrefix) != noncePrefixSize {
+		return envelope{}, false, fmt.Errorf("%w: invalid %s %q", ErrCorrupt, metaEnvelopeNonce, nonce)
+	}
+	return e, true, nil
+}
+
+// metadata returns the user metadata that stores the envelope.
+func (e envelope) metadata() map[string]string {
+	return map[string]string{
+		metaEnvelopeKeyID:      hex.EncodeToString(e.keyID[:]),
+		metaEnvelopeWrappedKey: base64.StdEncoding.EncodeToString(e.wrappedKey),
+		metaEnvelopeNonce:      base64.StdEncoding.EncodeToString(e.noncePrefix),
+	}
+}
+
+// additionalData returns the data authenticated with the wrapped data
+// key, so neither the master key ID nor the nonce prefix stored with it
+// can be replaced, nor the data key used for another object.
+func (e envelope) additionalData() []byte {
+	ad := append([]byte(nil), e.keyID[:]...)
+	ad = append(ad, e.noncePrefix...)
+	return append(ad, e.object...)
+}
+
+// newEnvelope returns the envelope of a new object with the given bucket
+// and key, with a new data key wrapped by the primary key, and the
+// cipher of its segments.
+func (k *Keyring) newEnvelope(bucket, key string) (envelope, cipher.AEAD, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return envelope{}, nil, err
+	}
+	e := envelope{noncePrefix: make([]byte, noncePrefixSize), object: objectID(bucket, key)}
+	if _, err := rand.Read(e.noncePrefix); err != nil {
+		return envelope{}, nil, err
+	}
+	if err := k.wrap(&e, dataKey); err != nil {
+		return envelope{}, nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return envelope{}, nil, err
+	}
+	return e, aead, nil
+}
+
+// wrap stores in e the data key wrapped by the primary key.
+func (k *Keyring) wrap(e *envelope, key []byte) error {
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	e.keyID = k.primary.id
+	e.wrappedKey = k.primary.aead.Seal(nonce, nonce, key, e.additionalData())
+	return nil
+}
+
+// unwrap returns the data key of an object.
+func (k *Keyring) unwrap(e envelope) ([]byte, error) {
+	mk, ok := k.keys[e.keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %x", ErrUnknownKey, e.keyID)
+	}
+	key, err := mk.aead.Open(nil, e.wrappedKey[:12], e.wrappedKey[12:], e.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("%w: unwrapping data key: %w", ErrCorrupt, err)
+	}
+	return key, nil
+}
+
+// rewrap returns the envelope with its data key wrapped by the primary
+// key.
+func (k *Keyring) rewrap(e envelope) (envelope, error) {
+	key, err := k.unwrap(e)
+	if err != nil {
+		return envelope{}, err
+	}
+	if err := k.wrap(&e, key); err != nil {
+		return envelope{}, err
+	}
+	return e, nil
+}
+
+// segmentCipher returns the cipher of the segments of an object.
+func (k *Keyring) segmentCipher(e envelope) (cipher.AEAD, error) {
+	key, err := k.unwrap(e)
+	if err != nil {
+		return nil, err
+	}
+	return newAEAD(key)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// segmentNonce returns the nonce of the segment with the given index.
+func segmentNonce(prefix []byte, index uint32, last bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], index)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// plaintextSize returns the size of the content of an encrypted object
+// of the given size, and the number of segments it has. It returns false
+// if no encrypted object can have that size.
+func plaintextSize(size int64) (plain, segments int64, ok bool) {
+	if size < segmentTagSize {
+		return 0, 0, false
+	}
+	segments = (size + segmentCryptSize - 1) / segmentCryptSize
+	if size-(segments-1)*segmentCryptSize < segmentTagSize {
+		return 0, 0, false
+	}
+	return size - segments*segmentTagSize, segments, true
+}
+
+// encryptReader returns a reader with the content of the object with the
+// given bucket and key, read from r, encrypted with a new data key, and
+// the envelope of the data key.
+func (k *Keyring) encryptReader(bucket, key string, r io.Reader) (io.Reader, envelope, error) {
+	e, aead, err := k.newEnvelope(bucket, key)
+	if err != nil {
+		return nil, envelope{}, err
+	}
+	return &encryptReader{
+		src:    r,
+		aead:   aead,
+		prefix: e.noncePrefix,
+		object: e.object,
+		plain:  make([]byte, segmentSize),
+	}, e, nil
+}
+
+// encryptReader encrypts, segment by segment, the content read from src.
+// Errors returned by src are returned unchanged.
+type encryptReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	object []byte
+	index  uint32
+	// plain is the buffer of the segment being read, and next the byte
+	// read after it to know whether it is the last one.
+	plain []byte
+	next  []byte
+	out   []byte
+	done  bool
+	err   error
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.seal()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// seal reads and encrypts the next segment.
+func (r *encryptReader) seal() error {
+	n := copy(r.plain, r.next)
+	r.next = nil
+	m, err := io.ReadFull(r.src, r.plain[n:])
+	n += m
+	last := false
+	switch err {
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	case nil:
+		var b [1]byte
+		m, err := io.ReadFull(r.src, b[:])
+		switch {
+		case m == 1:
+			r.next = b[:]
+		case err == io.EOF:
+			last = true
+		default:
+			return err
+		}
+	default:
+		return err
+	}
+	if r.index == math.MaxUint32 && !last {
+		return errors.New("content too large to be encrypted")
+	}
+
+	r.out = r.aead.Seal(r.out[:0], segmentNonce(r.prefix, r.index, last), r.plain[:n], r.object)
+	r.index++
+	r.done = last
+	return nil
+}
+
+// decryptReader decrypts the segments read from src, from the segment
+// with index first to the one with index end. last is the index of the
+// last segment of the object.
+type decryptReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	object []byte
+	index  int64
+	end    int64
+	last   int64
+	buf    []byte
+	out    []byte
+	err    error
+}
+
+func newDecryptReader(src io.Reader, aead cipher.AEAD, e envelope, first, end, last int64) *decryptReader {
+	return &decryptReader{
+		src:    src,
+		aead:   aead,
+		prefix: e.noncePrefix,
+		object: e.object,
+		index:  first,
+		end:    end,
+		last:   last,
+		buf:    make([]byte, segmentCryptSize),
+	}
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.index > r.end {
+			return 0, io.EOF
+		}
+		r.err = r.open()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next segment.
+func (r *decryptReader) open() error {
+	last := r.index == r.last
+	n, err := io.ReadFull(r.src, r.buf)
+	switch {
+	case err == io.ErrUnexpectedEOF && last:
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		return fmt.Errorf("%w: truncated", ErrCorrupt)
+	case err != nil:
+		return err
+	}
+	out, err := r.aead.Open(r.buf[:0], segmentNonce(r.prefix, uint32(r.index), last), r.buf[:n], r.object)
+	if err != nil {
+		return fmt.Errorf("%w: segment %d: %w", ErrCorrupt, r.index, err)
+	}
+	r.out = out
+	r.index++
+	return nil
+}
+
+// decryptObject returns the object, encrypted with the given envelope,
+// with its content decrypted.
+func (k *Keyring) decryptObject(obj *Object, e envelope) (*Object, error) {
+	size, segments, ok := plaintextSize(obj.Size)
+	if !ok {
+		obj.Body.Close()
+		return nil, fmt.Errorf("%w: invalid size %d", ErrCorrupt, obj.Size)
+	}
+	aead, err := k.segmentCipher(e)
+	if err != nil {
+		obj.Body.Close()
+		return nil, err
+	}
+	obj.Body = readCloser{newDecryptReader(obj.Body, aead, e, 0, segments-1, segments-1), obj.Body}
+	obj.Size = size
+	return obj, nil
+}
+
+// readCloser reads from a Reader and closes a Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,406 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// rotatePageSize is the number of checks listed at once by Rotate.
+const rotatePageSize = 1000
+
+// EnvelopeConfig configures the envelope encryption of the results.
+//
+// The checksums of the reports are not stored with the encryption on, so
+// the writes of the same report are not skipped. The results kept in the
+// spool while the storage is unavailable are not encrypted, as they are
+// encrypted when they are replayed, so the spool directory must be
+// protected as the results are.
+type EnvelopeConfig struct {
+	// KeyFile is the keyfile with the master keys, as read by
+	// LoadKeyring. If empty, the results are not encrypted by the
+	// service.
+	KeyFile string
+	// AllowPlaintext makes the results stored before enabling the
+	// encryption readable, and lets Rotate encrypt them. It is meant
+	// to be set only while migrating the stored results.
+	AllowPlaintext bool
+}
+
+// EnvelopeStorage implements the Storage interface encrypting the
+// results before storing them in another storage, so they never leave
+// the process unencrypted. Every object is encrypted with its own
+// AES-GCM data key, which is stored in the user metadata of the object
+// wrapped by the primary master key of the keyring, and bound to the
+// bucket and key of the object. The results are decrypted when they are
+// downloaded.
+//
+// The checksums of the reports are not stored, as they would disclose
+// the reports, so the writes of the same report are not skipped.
+type EnvelopeStorage struct {
+	// AllowPlaintext makes the objects that are not encrypted, like
+	// the ones stored before enabling the encryption, be returned as
+	// they are. Otherwise reading them fails with ErrNotEncrypted.
+	AllowPlaintext bool
+
+	st   Storage
+	conf Config
+	keys *Keyring
+}
+
+// NewEnvelopeStorage returns an EnvelopeStorage that stores the results
+// in st, configured with c, encrypted with the keys of k.
+func NewEnvelopeStorage(st Storage, c Config, k *Keyring) *EnvelopeStorage {
+	return &EnvelopeStorage{st: st, conf: c, keys: k}
+}
+
+// objectKey returns the key of the object of a check, stored with the
+// given partition and name.
+func objectKey(dt, scan, name string) string {
+	return dt + "/" + scan + "/" + name
+}
+
+// SaveReports encrypts the report and stores it. The options are applied
+// to the encrypted report, except for the checksum, which is dropped.
+func (s *EnvelopeStorage) SaveReports(ctx context.Context, scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool, opts SaveOptions) (link string, err error) {
+	dt, scan := Partition(scanID, startedAt)
+	content, md, err := s.keys.Encrypt(s.conf.BucketReports, objectKey(dt, scan, checkID+".json"), report)
+	if err != nil {
+		return "", err
+	}
+	opts.Checksum = ""
+	return s.st.SaveReports(withObjectMetadata(ctx, md), scanID, checkID, startedAt, content, vulnerable, opts)
+}
+
+// SaveLogs encrypts the logs and stores them.
+func (s *EnvelopeStorage) SaveLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs []byte) (link string, err error) {
+	dt, scan := Partition(scanID, startedAt)
+	content, md, err := s.keys.Encrypt(s.conf.BucketLogs, objectKey(dt, scan, checkID+".log"), logs)
+	if err != nil {
+		return "", err
+	}
+	return s.st.SaveLogs(withObjectMetadata(ctx, md), scanID, checkID, startedAt, content)
+}
+
+// StreamLogs encrypts the logs while they are streamed to the storage.
+func (s *EnvelopeStorage) StreamLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs io.Reader) (link string, err error) {
+	dt, scan := Partition(scanID, startedAt)
+	r, e, err := s.keys.encryptReader(s.conf.BucketLogs, objectKey(dt, scan, checkID+".log"), logs)
+	if err != nil {
+		return "", err
+	}
+	return s.st.StreamLogs(withObjectMetadata(ctx, e.metadata()), scanID, checkID, startedAt, r)
+}
+
+// GetReport returns the decrypted report.
+func (s *EnvelopeStorage) GetReport(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error) {
+	return s.get(s.conf.BucketReports, objectKey(date, scanID, checkID), opts, func(opts GetOptions) (*Object, error) {
+		return s.st.GetReport(ctx, date, scanID, checkID, opts)
+	})
+}
+
+// GetLog returns the decrypted log.
+func (s *EnvelopeStorage) GetLog(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error) {
+	return s.get(s.conf.BucketLogs, objectKey(date, scanID, checkID), opts, func(opts GetOptions) (*Object, error) {
+		return s.st.GetLog(ctx, date, scanID, checkID, opts)
+	})
+}
+
+// get returns the decrypted object with the given bucket and key, or the
+// range of it selected by opts, downloaded with the given func. To return
+// a range, the first byte of the object is downloaded first, to know its
+// envelope and size, and then only the segments that hold the range.
+func (s *EnvelopeStorage) get(bucket, key string, opts GetOptions, get func(GetOptions) (*Object, error)) (*Object, error) {
+	spec, ok := parseRange(opts.Range)
+	if !ok {
+		obj, err := get(opts)
+		if err != nil {
+			return nil, err
+		}
+		e, ok, err := parseEnvelope(bucket, key, obj.Metadata)
+		if err != nil || !ok {
+			return s.plaintext(obj, err)
+		}
+		return s.keys.decryptObject(obj, e)
+	}
+
+	hopts := opts
+	hopts.Range = "bytes=0-0"
+	hobj, err := get(hopts)
+	if errors.Is(err, ErrRangeNotSatisfiable) {
+		// An empty object, that can not be encrypted.
+		return s.plaintext(get(opts))
+	}
+	if err != nil {
+		return nil, err
+	}
+	hobj.Body.Close()
+	e, ok, err := parseEnvelope(bucket, key, hobj.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s.plaintext(get(opts))
+	}
+	size, segments, ok := plaintextSize(hobj.Size)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid size %d", ErrCorrupt, hobj.Size)
+	}
+	aead, err := s.keys.segmentCipher(e)
+	if err != nil {
+		return nil, err
+	}
+	r, err := spec.resolve(size)
+	if err != nil {
+		return nil, err
+	}
+
+	first, end := r.Start/segmentSize, r.End/segmentSize
+	start := first * segmentCryptSize
+	stop := (end + 1) * segmentCryptSize
+	if stop > hobj.Size {
+		stop = hobj.Size
+	}
+	cobj, err := get(GetOptions{Range: fmt.Sprintf("bytes=%d-%d", start, stop-1)})
+	if err != nil {
+		return nil, err
+	}
+	body := newDecryptReader(cobj.Body, aead, e, first, end, segments-1)
+	if _, err := io.CopyN(ioutil.Discard, body, r.Start-first*segmentSize); err != nil {
+		cobj.Body.Close()
+		return nil, err
+	}
+	return &Object{
+		Body:         readCloser{io.LimitReader(body, r.Length()), cobj.Body},
+		Size:         size,
+		Range:        r,
+		ETag:         hobj.ETag,
+		LastModified: hobj.LastModified,
+		CacheControl: hobj.CacheControl,
+	}, nil
+}
+
+// plaintext returns an object that is not encrypted as it is, if the
+// storage allows it, or the error getting it.
+func (s *EnvelopeStorage) plaintext(obj *Object, err error) (*Object, error) {
+	if err != nil {
+		if obj != nil {
+			obj.Body.Close()
+		}
+		return nil, err
+	}
+	if !s.AllowPlaintext {
+		obj.Body.Close()
+		return nil, ErrNotEncrypted
+	}
+	return obj, nil
+}
+
+// ListChecks returns a page of the reports and logs of the checks of a
+// scan. The objects are assumed to be encrypted, so the sizes returned
+// are the sizes of the decrypted content.
+func (s *EnvelopeStorage) ListChecks(ctx context.Context, date, scanID, token string, limit int) (*CheckPage, error) {
+	page, err := s.st.ListChecks(ctx, date, scanID, token, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range page.Checks {
+		if size, _, ok := plaintextSize(c.Size); ok {
+			page.Checks[i].Size = size
+		}
+	}
+	return page, nil
+}
+
+// ListScans returns the scans that stored reports between the given
+// dates.
+func (s *EnvelopeStorage) ListScans(ctx context.Context, from, to time.Time) ([]ScanSummary, error) {
+	return s.st.ListScans(ctx, from, to)
+}
+
+// RotateOptions are the options of a rotation of the master key.
+type RotateOptions struct {
+	// Inspect returns whether a decrypted report is vulnerable and the
+	// options it is stored with, so the vulnerable copy and the
+	// metadata of the report are kept when it is rewritten.
+	Inspect func(report []byte) (vulnerable bool, opts SaveOptions, err error)
+}
+
+// RotateSummary summarizes a rotation of the master key.
+type RotateSummary struct {
+	// Rewrapped is the number of objects whose data key was wrapped
+	// again with the primary key.
+	Rewrapped int
+	// Encrypted is the number of objects that were not encrypted.
+	Encrypted int
+	// Current is the number of objects already wrapped with the
+	// primary key, that are left untouched.
+	Current int
+}
+
+// Rotate rewrites the reports and logs of the scans started between from
+// and to, both included, so their data keys are wrapped by the primary
+// master key and the old master keys can be removed from the keyring.
+// The content of the objects is not decrypted, except for the reports,
+// that are inspected to store them with the same options. Objects that
+// are not encrypted are encrypted if AllowPlaintext is set, and make the
+// rotation fail with ErrNotEncrypted otherwise. Only the checks of the
+// scans with reports are rotated.
+func (s *EnvelopeStorage) Rotate(ctx context.Context, from, to time.Time, opts RotateOptions) (RotateSummary, error) {
+	var summary RotateSummary
+	if to.Before(from) {
+		return summary, fmt.Errorf("%w: %s is before %s", ErrInvalidRange, to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		scans, err := s.st.ListScans(ctx, day, day)
+		if err != nil {
+			return summary, err
+		}
+		for _, scan := range scans {
+			if err := s.rotateScan(ctx, scan, opts, &summary); err != nil {
+				return summary, err
+			}
+		}
+	}
+	return summary, nil
+}
+
+// rotateScan rotates the reports and logs of the checks of a scan.
+func (s *EnvelopeStorage) rotateScan(ctx context.Context, scan ScanSummary, opts RotateOptions, summary *RotateSummary) error {
+	var token string
+	for {
+		page, err := s.st.ListChecks(ctx, scan.Date.Format("2006-01-02"), scan.ScanID, token, rotatePageSize)
+		if err != nil {
+			return err
+		}
+		for _, c := range page.Checks {
+			if err := s.rotateObject(ctx, scan, c, opts, summary); err != nil {
+				return fmt.Errorf("rotating %s of check %s of scan %s: %w", c.Kind, c.CheckID, scan.ScanID, err)
+			}
+		}
+		if page.Next == "" {
+			return nil
+		}
+		token = page.Next
+	}
+}
+
+// rotateObject rewrites a report or a log with its data key wrapped by
+// the primary key.
+func (s *EnvelopeStorage) rotateObject(ctx context.Context, scan ScanSummary, c CheckObject, opts RotateOptions, summary *RotateSummary) error {
+	dt, scanPart := Partition(scan.ScanID, scan.Date)
+	var (
+		obj    *Object
+		bucket string
+		key    string
+		err    error
+	)
+	if c.Kind == KindReport {
+		bucket, key = s.conf.BucketReports, objectKey(dt, scanPart, c.CheckID+".json")
+		obj, err = s.st.GetReport(ctx, dt, scanPart, c.CheckID+".json", GetOptions{})
+	} else {
+		bucket, key = s.conf.BucketLogs, objectKey(dt, scanPart, c.CheckID+".log")
+		obj, err = s.st.GetLog(ctx, dt, scanPart, c.CheckID+".log", GetOptions{})
+	}
+	if err != nil {
+		return err
+	}
+	defer obj.Body.Close()
+
+	e, encrypted, err := parseEnvelope(bucket, key, obj.Metadata)
+	if err != nil {
+		return err
+	}
+	if encrypted && e.keyID == s.keys.primary.id {
+		summary.Current++
+		return nil
+	}
+	if !encrypted && !s.AllowPlaintext {
+		return ErrNotEncrypted
+	}
+
+	if c.Kind == KindReport {
+		err = s.rotateReport(ctx, scan, c.CheckID, key, obj, e, encrypted, opts)
+	} else {
+		err = s.rotateLog(ctx, scan, c.CheckID, key, obj, e, encrypted)
+	}
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		summary.Rewrapped++
+	} else {
+		summary.Encrypted++
+	}
+	return nil
+}
+
+// rotateReport stores a report with its data key rewrapped, or encrypted
+// if it is not, with the options returned by the Inspect func for its
+// decrypted content.
+func (s *EnvelopeStorage) rotateReport(ctx context.Context, scan ScanSummary, checkID, key string, obj *Object, e envelope, encrypted bool, opts RotateOptions) error {
+	content, err := ioutil.ReadAll(obj.Body)
+	if err != nil {
+		return err
+	}
+	var (
+		report []byte
+		md     map[string]string
+	)
+	if encrypted {
+		// Decrypting the report also checks it is not corrupt.
+		report, err = s.keys.Decrypt(s.conf.BucketReports, key, content, obj.Metadata)
+		if err != nil {
+			return err
+		}
+		e, err = s.keys.rewrap(e)
+		if err != nil {
+			return err
+		}
+		md = e.metadata()
+	} else {
+		report = content
+		content, md, err = s.keys.Encrypt(s.conf.BucketReports, key, report)
+		if err != nil {
+			return err
+		}
+	}
+
+	vulnerable, sopts, err := opts.Inspect(report)
+	if err != nil {
+		return err
+	}
+	// The report is the same, so it must be written even if its end
+	// time matches, and its checksum is not stored.
+	sopts.Force = true
+	sopts.Checksum = ""
+	_, err = s.st.SaveReports(withObjectMetadata(ctx, md), scan.ScanID, checkID, scan.Date, content, vulnerable, sopts)
+	return err
+}
+
+// rotateLog streams a log back to the storage with its data key
+// rewrapped, or encrypted if it is not.
+func (s *EnvelopeStorage) rotateLog(ctx context.Context, scan ScanSummary, checkID, key string, obj *Object, e envelope, encrypted bool) error {
+	var (
+		content io.Reader = obj.Body
+		err     error
+	)
+	if encrypted {
+		e, err = s.keys.rewrap(e)
+	} else {
+		content, e, err = s.keys.encryptReader(s.conf.BucketLogs, key, content)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.st.StreamLogs(withObjectMetadata(ctx, e.metadata()), scan.ScanID, checkID, scan.Date, content)
+	return err
+}
@@ -0,0 +1,515 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+const (
+	envScanID  = "9126034c-7caf-4acd-93f3-bee1941aa140"
+	envCheckID = "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0"
+	envPrefix  = "dt=2019-11-16/scan=" + envScanID + "/" + envCheckID
+)
+
+var envStartedAt = time.Date(2019, time.November, 16, 13, 0, 0, 0, time.UTC)
+
+func newMasterKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return key
+}
+
+func newKeyring(t *testing.T, keys ...[]byte) *storage.Keyring {
+	k, err := storage.NewKeyring(keys...)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return k
+}
+
+func randomContent(t *testing.T, n int) []byte {
+	content := make([]byte, n)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return content
+}
+
+func readAll(t *testing.T, obj *storage.Object) ([]byte, error) {
+	defer obj.Body.Close()
+	return ioutil.ReadAll(obj.Body)
+}
+
+func TestEnvelopeStorageConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, c storage.Config) storagetest.Harness {
+		mem := storage.NewMemoryStorage(c)
+		k := newKeyring(t, newMasterKey(t))
+		return storagetest.Harness{
+			Storage: storage.NewEnvelopeStorage(mem, c, k),
+			Object: func(bucket, key string) ([]byte, bool) {
+				content, ok := mem.Object(bucket, key)
+				if !ok {
+					return nil, false
+				}
+				md := mem.Metadata(bucket, key)
+				if filepath.Ext(key) != ".gz" {
+					plain, err := k.Decrypt(bucket, key, content, md)
+					if err != nil {
+						t.Fatalf("expected no error decrypting %s, got: %v", key, err)
+					}
+					return plain, true
+				}
+				// The vulnerable copies are the encrypted reports
+				// gzipped, stored with the same envelope, so they are
+				// bound to the reports.
+				zr, err := gzip.NewReader(bytes.NewReader(content))
+				if err != nil {
+					t.Fatalf("expected gzip content in %s, got: %v", key, err)
+				}
+				gz, err := ioutil.ReadAll(zr)
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+				plain, err := k.Decrypt(c.BucketReports, strings.TrimSuffix(key, ".gz"), gz, md)
+				if err != nil {
+					t.Fatalf("expected no error decrypting %s, got: %v", key, err)
+				}
+				var buf bytes.Buffer
+				zw := gzip.NewWriter(&buf)
+				zw.Write(plain)
+				zw.Close()
+				return buf.Bytes(), true
+			},
+			NoChecksums: true,
+		}
+	})
+}
+
+// TestEnvelopeStorageBackends checks the envelopes are stored with the
+// objects by every backend.
+func TestEnvelopeStorageBackends(t *testing.T) {
+	backends := map[string]func(t *testing.T) storage.Storage{
+		"s3": func(t *testing.T) storage.Storage {
+			return storage.NewS3Storage(storagetest.Config, logrus.NewEntry(logrus.New()), storagetest.NewFakeS3())
+		},
+		"filesystem": func(t *testing.T) storage.Storage {
+			c := storagetest.Config
+			c.Root = t.TempDir()
+			s, err := storage.NewFilesystemStorage(c, logrus.NewEntry(logrus.New()))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			return s
+		},
+		"memory": func(t *testing.T) storage.Storage {
+			return storage.NewMemoryStorage(storagetest.Config)
+		},
+	}
+	for name, newStorage := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			s := storage.NewEnvelopeStorage(newStorage(t), storagetest.Config, newKeyring(t, newMasterKey(t)))
+
+			report := []byte(`{"vulnerabilities":[{}]}`)
+			if _, err := s.SaveReports(ctx, envScanID, envCheckID, envStartedAt, report, true, storage.SaveOptions{EndTime: envStartedAt}); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			logs := randomContent(t, 100<<10)
+			if _, err := s.StreamLogs(ctx, envScanID, envCheckID, envStartedAt, bytes.NewReader(logs)); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			obj, err := s.GetReport(ctx, "dt=2019-11-16", "scan="+envScanID, envCheckID+".json", storage.GetOptions{})
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if got, err := readAll(t, obj); err != nil || !bytes.Equal(got, report) {
+				t.Fatalf("expected report '%s', got: '%s', %v", report, got, err)
+			}
+			obj, err = s.GetLog(ctx, "dt=2019-11-16", "scan="+envScanID, envCheckID+".log", storage.GetOptions{Range: "bytes=70000-70009"})
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if got, err := readAll(t, obj); err != nil || !bytes.Equal(got, logs[70000:70010]) {
+				t.Fatalf("expected range of the logs, got: %x, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestEnvelopeStorageEncrypts(t *testing.T) {
+	svc := storagetest.NewFakeS3()
+	st := storage.NewS3Storage(storagetest.Config, logrus.NewEntry(logrus.New()), svc)
+	k := newKeyring(t, newMasterKey(t))
+	s := storage.NewEnvelopeStorage(st, storagetest.Config, k)
+
+	report := []byte(`{"vulnerabilities":[{}]}`)
+	opts := storage.SaveOptions{Checksum: "0123456789abcdef"}
+	if _, err := s.SaveReports(context.Background(), envScanID, envCheckID, envStartedAt, report, true, opts); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.StreamLogs(context.Background(), envScanID, envCheckID, envStartedAt, bytes.NewReader(report)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	objects := []struct{ bucket, key string }{
+		{storagetest.Config.BucketReports, envPrefix + ".json"},
+		{storagetest.Config.BucketVulnerableReports, envPrefix + ".json.gz"},
+		{storagetest.Config.BucketLogs, envPrefix + ".log"},
+	}
+	for _, o := range objects {
+		obj, ok := svc.Object(o.bucket, o.key)
+		if !ok {
+			t.Fatalf("expected %s/%s to be stored", o.bucket, o.key)
+		}
+		if bytes.Contains(obj.Body, report) {
+			t.Fatalf("expected %s/%s to be encrypted, got: '%s'", o.bucket, o.key, obj.Body)
+		}
+		// The envelope is stored in the metadata of the object, and
+		// the checksum of the report is not stored.
+		for _, key := range []string{"Envelope-Key-Id", "Envelope-Wrapped-Key", "Envelope-Nonce"} {
+			if obj.Metadata[key] == nil {
+				t.Fatalf("expected metadata %s in %s/%s, got: %v", key, o.bucket, o.key, obj.Metadata)
+			}
+		}
+		if obj.Metadata["Sha256"] != nil {
+			t.Fatalf("expected no checksum in %s/%s", o.bucket, o.key)
+		}
+	}
+
+	other := storage.NewEnvelopeStorage(st, storagetest.Config, newKeyring(t, newMasterKey(t)))
+	_, err := other.GetReport(context.Background(), "dt=2019-11-16", "scan="+envScanID, envCheckID+".json", storage.GetOptions{})
+	if !errors.Is(err, storage.ErrUnknownKey) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrUnknownKey, err)
+	}
+}
+
+func TestEnvelopeStorageRange(t *testing.T) {
+	mem := storage.NewMemoryStorage(storagetest.Config)
+	s := storage.NewEnvelopeStorage(mem, storagetest.Config, newKeyring(t, newMasterKey(t)))
+
+	const seg = 64 << 10
+	logs := randomContent(t, 3*seg+100)
+	if _, err := s.StreamLogs(context.Background(), envScanID, envCheckID, envStartedAt, bytes.NewReader(logs)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	testCases := []struct {
+		rng        string
+		start, end int
+	}{
+		{"", 0, len(logs) - 1},
+		{"bytes=0-9", 0, 9},
+		{"bytes=65530-65545", seg - 6, seg + 9},
+		{"bytes=65536-131071", seg, 2*seg - 1},
+		{"bytes=100-150000", 100, 150000},
+		{"bytes=-50", len(logs) - 50, len(logs) - 1},
+		{"bytes=196608-", 3 * seg, len(logs) - 1},
+	}
+	for _, tc := range testCases {
+		obj, err := s.GetLog(context.Background(), "dt=2019-11-16", "scan="+envScanID, envCheckID+".log", storage.GetOptions{Range: tc.rng})
+		if err != nil {
+			t.Fatalf("range %q: expected no error, got: %v", tc.rng, err)
+		}
+		got, err := readAll(t, obj)
+		if err != nil {
+			t.Fatalf("range %q: expected no error, got: %v", tc.rng, err)
+		}
+		if !bytes.Equal(got, logs[tc.start:tc.end+1]) {
+			t.Fatalf("range %q: expected %d bytes from %d, got %d bytes", tc.rng, tc.end-tc.start+1, tc.start, len(got))
+		}
+		if obj.Size != int64(len(logs)) {
+			t.Fatalf("range %q: expected size %d, got: %d", tc.rng, len(logs), obj.Size)
+		}
+	}
+
+	_, err := s.GetLog(context.Background(), "dt=2019-11-16", "scan="+envScanID, envCheckID+".log", storage.GetOptions{Range: "bytes=196708-"})
+	if !errors.Is(err, storage.ErrRangeNotSatisfiable) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrRangeNotSatisfiable, err)
+	}
+}
+
+func TestEnvelopeStorageUnencrypted(t *testing.T) {
+	mem := storage.NewMemoryStorage(storagetest.Config)
+	s := storage.NewEnvelopeStorage(mem, storagetest.Config, newKeyring(t, newMasterKey(t)))
+
+	logs := []byte("0123456789")
+	if _, err := mem.SaveLogs(context.Background(), envScanID, envCheckID, envStartedAt, logs); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	testCases := []struct {
+		rng      string
+		expected string
+	}{
+		{"", "0123456789"},
+		{"bytes=2-4", "234"},
+		{"bytes=-3", "789"},
+	}
+	for _, tc := range testCases {
+		// The objects that are not encrypted are only returned while
+		// migrating.
+		s.AllowPlaintext = false
+		_, err := s.GetLog(context.Background(), "dt=2019-11-16", "scan="+envScanID, envCheckID+".log", storage.GetOptions{Range: tc.rng})
+		if !errors.Is(err, storage.ErrNotEncrypted) {
+			t.Fatalf("range %q: expected error %v, got: %v", tc.rng, storage.ErrNotEncrypted, err)
+		}
+
+		s.AllowPlaintext = true
+		obj, err := s.GetLog(context.Background(), "dt=2019-11-16", "scan="+envScanID, envCheckID+".log", storage.GetOptions{Range: tc.rng})
+		if err != nil {
+			t.Fatalf("range %q: expected no error, got: %v", tc.rng, err)
+		}
+		got, err := readAll(t, obj)
+		if err != nil {
+			t.Fatalf("range %q: expected no error, got: %v", tc.rng, err)
+		}
+		if string(got) != tc.expected {
+			t.Fatalf("range %q: expected content '%s', got: '%s'", tc.rng, tc.expected, got)
+		}
+	}
+}
+
+func TestEnvelopeStorageCorrupt(t *testing.T) {
+	const seg = 64 << 10
+	k := newKeyring(t, newMasterKey(t))
+	logs := randomContent(t, 2*seg)
+	bucket := storagetest.Config.BucketLogs
+	content, md, err := k.Encrypt(bucket, envPrefix+".log", logs)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	_, other, err := k.Encrypt(bucket, envPrefix+".log", logs)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// Objects copied from another key or bucket can not be decrypted.
+	otherKey, otherKeyMD, err := k.Encrypt(bucket, "dt=2019-11-16/scan="+envScanID+"/other.log", logs)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	otherBucket, otherBucketMD, err := k.Encrypt(storagetest.Config.BucketReports, envPrefix+".log", logs)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	tampered := append([]byte(nil), content...)
+	tampered[len(tampered)-100] ^= 1
+	// The nonce of another object can not be used with the wrapped
+	// key of this one.
+	swapped := map[string]string{}
+	for k, v := range md {
+		swapped[k] = v
+	}
+	swapped["Envelope-Nonce"] = other["Envelope-Nonce"]
+
+	testCases := []struct {
+		name     string
+		content  []byte
+		metadata map[string]string
+		rng      string
+	}{
+		{"tampered", tampered, md, ""},
+		{"tampered range", tampered, md, "bytes=-10"},
+		// Dropping the last segment must be detected even if the
+		// object ends at the end of a segment.
+		{"truncated", content[:len(content)-(seg+16)], md, ""},
+		{"swapped nonce", content, swapped, ""},
+		{"copied from another key", otherKey, otherKeyMD, ""},
+		{"copied from another key range", otherKey, otherKeyMD, "bytes=-10"},
+		{"copied from another bucket", otherBucket, otherBucketMD, ""},
+		{"invalid metadata", content, map[string]string{"Envelope-Key-Id": "invalid"}, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := storagetest.NewFakeS3()
+			s := storage.NewEnvelopeStorage(storage.NewS3Storage(storagetest.Config, logrus.NewEntry(logrus.New()), svc), storagetest.Config, k)
+			putObjectWithMetadata(t, svc, storagetest.Config.BucketLogs, envPrefix+".log", tc.content, tc.metadata)
+
+			obj, err := s.GetLog(context.Background(), "dt=2019-11-16", "scan="+envScanID, envCheckID+".log", storage.GetOptions{Range: tc.rng})
+			if err == nil {
+				_, err = readAll(t, obj)
+			}
+			if !errors.Is(err, storage.ErrCorrupt) {
+				t.Fatalf("expected error %v, got: %v", storage.ErrCorrupt, err)
+			}
+		})
+	}
+}
+
+// putObjectWithMetadata stores an object in the fake S3 with the given
+// user metadata.
+func putObjectWithMetadata(t *testing.T, svc *storagetest.FakeS3, bucket, key string, content []byte, md map[string]string) {
+	t.Helper()
+
+	metadata := map[string]*string{}
+	for k, v := range md {
+		metadata[k] = aws.String(v)
+	}
+	_, err := svc.PutObjectWithContext(context.Background(), &s3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		Body:     bytes.NewReader(content),
+		Metadata: metadata,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	k1, k2 := newMasterKey(t), newMasterKey(t)
+	testCases := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			content: "# primary\n" + base64.StdEncoding.EncodeToString(k1) + "\n\n" +
+				"# previous\n" + base64.StdEncoding.EncodeToString(k2) + "\n",
+		},
+		{name: "empty", content: "# no keys\n", wantErr: true},
+		{name: "invalid base64", content: "not a key\n", wantErr: true},
+		{name: "short key", content: base64.StdEncoding.EncodeToString(k1[:16]) + "\n", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys")
+			if err := ioutil.WriteFile(path, []byte(tc.content), 0600); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			k, err := storage.LoadKeyring(path)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			// The first key is the primary one.
+			content, md, err := k.Encrypt("bucket", "key", []byte("content"))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if _, err := newKeyring(t, k1).Decrypt("bucket", "key", content, md); err != nil {
+				t.Fatalf("expected content encrypted with the first key, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestEnvelopeStorageRotate(t *testing.T) {
+	ctx := context.Background()
+	oldKey, newKey := newMasterKey(t), newMasterKey(t)
+	mem := storage.NewMemoryStorage(storagetest.Config)
+	old := storage.NewEnvelopeStorage(mem, storagetest.Config, newKeyring(t, oldKey))
+
+	report := []byte(`{"vulnerabilities":[{}]}`)
+	if _, err := old.SaveReports(ctx, envScanID, envCheckID, envStartedAt, report, true, storage.SaveOptions{Checksum: "sum"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	logs := []byte("logs")
+	if _, err := old.SaveLogs(ctx, envScanID, envCheckID, envStartedAt, logs); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// A report stored before enabling the encryption.
+	const legacyCheckID = "6a5d3e1b-2f34-4f8a-9a1d-0d6b4cf0b4a1"
+	legacy := []byte(`{"vulnerabilities":[]}`)
+	if _, err := mem.SaveReports(ctx, envScanID, legacyCheckID, envStartedAt, legacy, false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var inspected [][]byte
+	opts := storage.RotateOptions{
+		Inspect: func(report []byte) (bool, storage.SaveOptions, error) {
+			inspected = append(inspected, report)
+			return bytes.Contains(report, []byte("{}")), storage.SaveOptions{Checksum: "sum"}, nil
+		},
+	}
+	s := storage.NewEnvelopeStorage(mem, storagetest.Config, newKeyring(t, newKey, oldKey))
+	// The reports that are not encrypted are only encrypted while
+	// migrating.
+	if _, err := s.Rotate(ctx, envStartedAt, envStartedAt, opts); !errors.Is(err, storage.ErrNotEncrypted) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrNotEncrypted, err)
+	}
+	s.AllowPlaintext = true
+	inspected = nil
+	summary, err := s.Rotate(ctx, envStartedAt, envStartedAt, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if want := (storage.RotateSummary{Rewrapped: 2, Encrypted: 1}); summary != want {
+		t.Fatalf("expected summary %+v, got: %+v", want, summary)
+	}
+	if len(inspected) != 2 {
+		t.Fatalf("expected 2 reports inspected, got: %d", len(inspected))
+	}
+
+	// The objects can be read without the old key.
+	rotated := storage.NewEnvelopeStorage(mem, storagetest.Config, newKeyring(t, newKey))
+	expected := map[string][]byte{
+		envCheckID + ".json":    report,
+		envCheckID + ".log":     logs,
+		legacyCheckID + ".json": legacy,
+	}
+	for name, want := range expected {
+		get := rotated.GetReport
+		if filepath.Ext(name) == ".log" {
+			get = rotated.GetLog
+		}
+		obj, err := get(ctx, "dt=2019-11-16", "scan="+envScanID, name, storage.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", name, err)
+		}
+		got, err := readAll(t, obj)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s: expected content '%s', got: '%s'", name, want, got)
+		}
+	}
+	if _, ok := mem.Object(storagetest.Config.BucketVulnerableReports, envPrefix+".json.gz"); !ok {
+		t.Fatalf("expected the vulnerable copy to be kept")
+	}
+	gzmd := mem.Metadata(storagetest.Config.BucketVulnerableReports, envPrefix+".json.gz")
+	if md := mem.Metadata(storagetest.Config.BucketReports, envPrefix+".json"); !maps.Equal(gzmd, md) {
+		t.Fatalf("expected the vulnerable copy to be rewrapped, got: %v, %v", gzmd, md)
+	}
+
+	summary, err = s.Rotate(ctx, envStartedAt, envStartedAt, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if want := (storage.RotateSummary{Current: 3}); summary != want {
+		t.Fatalf("expected summary %+v, got: %+v", want, summary)
+	}
+}
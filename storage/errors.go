@@ -0,0 +1,94 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+var (
+	// ErrNotFound is returned when the requested object does not exist.
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey is returned when the elements identifying an object
+	// are not valid.
+	ErrInvalidKey = errors.New("invalid object key")
+	// ErrInvalidToken is returned when a pagination token is not valid.
+	ErrInvalidToken = errors.New("invalid page token")
+	// ErrInvalidRange is returned when a range of dates is not valid.
+	ErrInvalidRange = errors.New("invalid range")
+	// ErrRangeNotSatisfiable is returned when the byte range requested
+	// in a download does not overlap the object.
+	ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+	// ErrNotModified is returned when a conditional download is not
+	// performed because the object was not modified.
+	ErrNotModified = errors.New("object not modified")
+	// ErrConflict is returned when a report is not stored because the
+	// stored one is newer.
+	ErrConflict = errors.New("conflict with the stored object")
+	// ErrUnavailable is returned when the storage backend can not be
+	// reached or is temporarily unable to serve the request.
+	ErrUnavailable = errors.New("storage unavailable")
+	// ErrMisconfigured is returned when the storage backend refuses a
+	// call because of the configuration of the service, like a bucket
+	// that does not exist. Retrying the call does not fix it.
+	ErrMisconfigured = errors.New("storage misconfigured")
+)
+
+// s3Error classifies an error returned by the S3 API, wrapping it with
+// the storage error it corresponds to, if any. The original error is
+// kept in the chain so it can still be inspected with errors.As.
+func s3Error(err error) error {
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) {
+		return err
+	}
+
+	switch aerr.Code() {
+	case s3.ErrCodeNoSuchKey, "NotFound":
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case "NotModified":
+		return fmt.Errorf("%w: %w", ErrNotModified, err)
+	case "InvalidRange":
+		return fmt.Errorf("%w: %w", ErrRangeNotSatisfiable, err)
+	case s3.ErrCodeNoSuchBucket:
+		// A missing bucket is a misconfiguration of the service, not
+		// something the client can fix nor that goes away by retrying.
+		return fmt.Errorf("%w: %w", ErrMisconfigured, err)
+	case request.ErrCodeRequestError, request.ErrCodeResponseTimeout,
+		"RequestTimeout", "SlowDown", "ServiceUnavailable", "InternalError":
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	var rerr awserr.RequestFailure
+	if errors.As(err, &rerr) && rerr.StatusCode() >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	return err
+}
+
+// readError returns the error returned by the reader of the content
+// uploaded by an s3manager.Uploader, if that is what made the upload
+// fail. The uploader wraps those errors in errors that can not be
+// unwrapped.
+func readError(err error) error {
+	for err != nil {
+		aerr, ok := err.(awserr.Error)
+		if !ok {
+			return nil
+		}
+		if aerr.Code() == "ReadRequestBody" {
+			return aerr.OrigErr()
+		}
+		err = aerr.OrigErr()
+	}
+	return nil
+}
@@ -0,0 +1,605 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default directory names used by FilesystemStorage when the
+// corresponding bucket is not configured.
+const (
+	defaultDirReports           = "reports"
+	defaultDirVulnerableReports = "vulnerable-reports"
+	defaultDirLogs              = "logs"
+)
+
+// FilesystemStorage implements the Storage interface storing the results
+// in a local directory. Every configured bucket is mapped to a
+// subdirectory of the root, and the objects keep the same layout they
+// have in S3.
+type FilesystemStorage struct {
+	Conf   Config
+	logger *logrus.Entry
+}
+
+// NewFilesystemStorage creates a FilesystemStorage rooted at c.Root.
+func NewFilesystemStorage(c Config, l *logrus.Entry) (*FilesystemStorage, error) {
+	if c.Root == "" {
+		return nil, errors.New("filesystem storage requires a root directory")
+	}
+	if c.BucketReports == "" {
+		c.BucketReports = defaultDirReports
+	}
+	if c.BucketVulnerableReports == "" {
+		c.BucketVulnerableReports = defaultDirVulnerableReports
+	}
+	if c.BucketLogs == "" {
+		c.BucketLogs = defaultDirLogs
+	}
+	if err := os.MkdirAll(c.Root, 0755); err != nil {
+		return nil, err
+	}
+	return &FilesystemStorage{Conf: c, logger: l}, nil
+}
+
+// SaveReports stores the report in a file. The checksum and end time in
+// opts, and the user metadata of ctx, are stored in a hidden file next
+// to it.
+func (s *FilesystemStorage) SaveReports(ctx context.Context, scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool, opts SaveOptions) (link string, err error) {
+	dt, scan := Partition(scanID, startedAt)
+	name := checkID + ".json"
+
+	stored, err := s.readMeta(s.Conf.BucketReports, dt, scan, metaName(name))
+	if err != nil {
+		return "", err
+	}
+	skip, err := checkSave(opts, stored)
+	if err != nil {
+		return "", err
+	}
+	if skip {
+		return urlConcat(s.Conf.LinkBase, "reports", dt, scan, name)
+	}
+	// Remove the metadata of the stored report first, so it never
+	// describes a different report.
+	if err := s.removeFile(s.Conf.BucketReports, dt, scan, metaName(name)); err != nil {
+		return "", err
+	}
+
+	link, err = urlConcat(s.Conf.LinkBase, "reports", dt, scan, name)
+	if err != nil {
+		return "", err
+	}
+
+	meta := opts.meta()
+	meta.Metadata = objectMetadata(ctx)
+	if !vulnerable {
+		if err := s.writeObject(ctx, s.Conf.BucketReports, report, meta, dt, scan, name); err != nil {
+			return "", err
+		}
+		return link, nil
+	}
+	if err := s.saveVulnerableReport(ctx, report, meta, dt, scan, name); err != nil {
+		return "", err
+	}
+	return link, nil
+}
+
+// saveVulnerableReport stores a vulnerable report both in the
+// vulnerable reports directory, gzipped, and in the reports directory,
+// with a repair marker written before and removed after, as the S3
+// backend does, so RepairReports can make both directories agree if the
+// rollback of a failed write also fails.
+func (s *FilesystemStorage) saveVulnerableReport(ctx context.Context, report []byte, meta reportMeta, dt, scan, name string) error {
+	marker := []string{repairDir, dt, scan, name}
+	if err := s.writeFile(ctx, s.Conf.BucketReports, nil, marker...); err != nil {
+		return err
+	}
+
+	gz, err := gzipContent(report)
+	if err != nil {
+		return err
+	}
+	err = s.writeObject(ctx, s.Conf.BucketVulnerableReports, gz, reportMeta{Metadata: meta.Metadata}, dt, scan, name+".gz")
+	if err == nil {
+		err = s.writeObject(ctx, s.Conf.BucketReports, report, meta, dt, scan, name)
+	}
+	if err != nil {
+		// Roll back the vulnerable copy so both directories agree.
+		if rerr := s.removeObject(s.Conf.BucketVulnerableReports, dt, scan, name+".gz"); rerr != nil {
+			contextLogger(ctx, s.logger).WithError(rerr).Error("rolling back vulnerable report, left for repair")
+		}
+		return err
+	}
+
+	if err := s.removeFile(s.Conf.BucketReports, marker...); err != nil {
+		contextLogger(ctx, s.logger).WithError(err).Warn("removing repair marker")
+	}
+	return nil
+}
+
+// RepairReports completes the writes of the vulnerable reports left
+// unfinished, making the vulnerable reports directory agree with the
+// reports directory as told by vulnerable, like the S3 backend does.
+// Markers modified less than minAge ago are skipped. It returns the
+// number of reports repaired.
+func (s *FilesystemStorage) RepairReports(ctx context.Context, minAge time.Duration, vulnerable VulnerableFunc) (int, error) {
+	root, err := s.path(s.Conf.BucketReports, repairDir)
+	if err != nil {
+		return 0, err
+	}
+	var markers [][]string
+	now := time.Now()
+	err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || strings.HasPrefix(info.Name(), ".") || now.Sub(info.ModTime()) < minAge {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		markers = append(markers, strings.Split(filepath.ToSlash(rel), "/"))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, elems := range markers {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		if err := s.repairReport(ctx, vulnerable, elems...); err != nil {
+			return n, err
+		}
+		if err := s.removeFile(s.Conf.BucketReports, append([]string{repairDir}, elems...)...); err != nil {
+			return n, err
+		}
+		contextLogger(ctx, s.logger).WithField("path", filepath.Join(elems...)).Info("vulnerable report repaired")
+		n++
+	}
+	return n, nil
+}
+
+// repairReport makes the gzipped copy of a report agree with the
+// report.
+func (s *FilesystemStorage) repairReport(ctx context.Context, vulnerable VulnerableFunc, elems ...string) error {
+	last := len(elems) - 1
+	gzElems := append(elems[:last:last], elems[last]+".gz")
+	obj, err := s.openFile(ctx, s.Conf.BucketReports, GetOptions{}, elems...)
+	if errors.Is(err, ErrNotFound) {
+		return s.removeObject(s.Conf.BucketVulnerableReports, gzElems...)
+	}
+	if err != nil {
+		return err
+	}
+	defer obj.Body.Close()
+
+	report, err := ioutil.ReadAll(obj.Body)
+	if err != nil {
+		return err
+	}
+	vuln, err := vulnerable(strings.Join(elems, "/"), report, obj.Metadata)
+	if err != nil {
+		contextLogger(ctx, s.logger).WithError(err).WithField("path", filepath.Join(elems...)).
+			Warn("invalid report, vulnerable copy left untouched")
+		return nil
+	}
+	if !vuln {
+		return s.removeObject(s.Conf.BucketVulnerableReports, gzElems...)
+	}
+	gz, err := gzipContent(report)
+	if err != nil {
+		return err
+	}
+	return s.writeObject(ctx, s.Conf.BucketVulnerableReports, gz, reportMeta{Metadata: obj.Metadata}, gzElems...)
+}
+
+// metaName returns the name of the hidden file that stores the metadata
+// of the file with the given name.
+func metaName(name string) string {
+	return "." + name + ".meta"
+}
+
+// readMeta returns the metadata stored in the file identified by elems
+// inside the directory of the given bucket, or empty metadata if it does
+// not exist.
+func (s *FilesystemStorage) readMeta(bucket string, elems ...string) (reportMeta, error) {
+	var meta reportMeta
+	p, err := s.path(bucket, elems...)
+	if err != nil {
+		return meta, err
+	}
+	content, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, err
+	}
+	if err := json.Unmarshal(content, &meta); err != nil {
+		// Corrupt metadata does not prevent storing the report.
+		return reportMeta{}, nil
+	}
+	return meta, nil
+}
+
+// SaveLogs stores the logs in a file.
+func (s *FilesystemStorage) SaveLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs []byte) (link string, err error) {
+	return s.StreamLogs(ctx, scanID, checkID, startedAt, bytes.NewReader(logs))
+}
+
+// StreamLogs copies the logs to a file. The user metadata of ctx is
+// stored in a hidden file next to it.
+func (s *FilesystemStorage) StreamLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs io.Reader) (link string, err error) {
+	dt, scan := Partition(scanID, startedAt)
+	name := checkID + ".log"
+
+	link, err = urlConcat(s.Conf.LinkBase, "logs", dt, scan, name)
+	if err != nil {
+		return "", err
+	}
+
+	// Remove the metadata of the stored logs first, so it never
+	// describes different logs.
+	if err := s.removeFile(s.Conf.BucketLogs, dt, scan, metaName(name)); err != nil {
+		return "", err
+	}
+	err = s.copyObject(ctx, s.Conf.BucketLogs, logs, reportMeta{Metadata: objectMetadata(ctx)}, dt, scan, name)
+	if err != nil {
+		return "", err
+	}
+
+	return link, nil
+}
+
+// GetReport returns the report that corresponds to the input params.
+func (s *FilesystemStorage) GetReport(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error) {
+	return s.openFile(ctx, s.Conf.BucketReports, opts, date, scanID, checkID)
+}
+
+// GetLog returns the log that corresponds to the input params.
+func (s *FilesystemStorage) GetLog(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error) {
+	return s.openFile(ctx, s.Conf.BucketLogs, opts, date, scanID, checkID)
+}
+
+// ListChecks returns a page of the reports and logs stored in the
+// filesystem for the checks of a scan.
+func (s *FilesystemStorage) ListChecks(ctx context.Context, date, scanID, token string, limit int) (*CheckPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	prefix, err := scanPrefix(date, scanID)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := parsePageToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return listSorted(tok, limit, func(phase listPhase) ([]CheckObject, error) {
+		dir := filepath.Join(s.Conf.Root, phase.bucket(s.Conf), filepath.FromSlash(prefix))
+		// ReadDir returns the entries sorted by name.
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var objs []CheckObject
+		for _, e := range entries {
+			name := e.Name()
+			if !e.Mode().IsRegular() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, phase.suffix) {
+				continue
+			}
+			objs = append(objs, CheckObject{
+				CheckID:      strings.TrimSuffix(name, phase.suffix),
+				Kind:         phase.kind,
+				Size:         e.Size(),
+				LastModified: e.ModTime(),
+			})
+		}
+		return objs, nil
+	})
+}
+
+// ListScans returns the scans that stored reports in the filesystem
+// between the given dates, both included.
+func (s *FilesystemStorage) ListScans(ctx context.Context, from, to time.Time) ([]ScanSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	days, err := scanDays(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	scans := []ScanSummary{}
+	for _, day := range days {
+		dt := day.Format("dt=2006-01-02")
+		dirs, err := readDir(filepath.Join(s.Conf.Root, s.Conf.BucketReports, dt))
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dirs {
+			scanID := strings.TrimPrefix(d.Name(), "scan=")
+			if !d.IsDir() || scanID == d.Name() || scanID == "" {
+				continue
+			}
+
+			checks, err := s.countFiles(s.Conf.BucketReports, ".json", dt, d.Name())
+			if err != nil {
+				return nil, err
+			}
+			if checks == 0 {
+				continue
+			}
+			vulnerable, err := s.countFiles(s.Conf.BucketVulnerableReports, ".json.gz", dt, d.Name())
+			if err != nil {
+				return nil, err
+			}
+
+			scans = append(scans, ScanSummary{
+				ScanID:     scanID,
+				Date:       day,
+				Checks:     checks,
+				Vulnerable: vulnerable > 0,
+			})
+		}
+	}
+
+	return scans, nil
+}
+
+// countFiles returns the number of regular files with the given suffix
+// in the directory identified by elems inside the directory of a bucket.
+func (s *FilesystemStorage) countFiles(bucket, suffix string, elems ...string) (int, error) {
+	entries, err := readDir(filepath.Join(append([]string{s.Conf.Root, bucket}, elems...)...))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, e := range entries {
+		name := e.Name()
+		if e.Mode().IsRegular() && !strings.HasPrefix(name, ".") && strings.HasSuffix(name, suffix) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// readDir returns the entries of a directory sorted by name, or none if
+// the directory does not exist.
+func readDir(dir string) ([]os.FileInfo, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// writeFile atomically writes content to the file identified by elems
+// inside the directory of the given bucket.
+func (s *FilesystemStorage) writeFile(ctx context.Context, bucket string, content []byte, elems ...string) error {
+	return s.copyFile(ctx, bucket, bytes.NewReader(content), elems...)
+}
+
+// writeObject writes the content to the file identified by elems, and
+// the metadata to its hidden metadata file.
+func (s *FilesystemStorage) writeObject(ctx context.Context, bucket string, content []byte, meta reportMeta, elems ...string) error {
+	return s.copyObject(ctx, bucket, bytes.NewReader(content), meta, elems...)
+}
+
+// copyObject copies the content read from r to the file identified by
+// elems, and writes the metadata to its hidden metadata file, which is
+// removed if the metadata is empty.
+func (s *FilesystemStorage) copyObject(ctx context.Context, bucket string, r io.Reader, meta reportMeta, elems ...string) error {
+	if err := s.copyFile(ctx, bucket, r, elems...); err != nil {
+		return err
+	}
+	last := len(elems) - 1
+	metaElems := append(elems[:last:last], metaName(elems[last]))
+	if meta.Checksum == "" && meta.EndTime.IsZero() && len(meta.Metadata) == 0 {
+		return s.removeFile(bucket, metaElems...)
+	}
+	content, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return s.writeFile(ctx, bucket, content, metaElems...)
+}
+
+// copyFile atomically writes the content read from r to the file
+// identified by elems inside the directory of the given bucket. The
+// content is first written to a temporary file in the same directory,
+// which is then renamed, so readers never observe a partially written
+// file.
+func (s *FilesystemStorage) copyFile(ctx context.Context, bucket string, r io.Reader, elems ...string) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	p, err := s.path(bucket, elems...)
+	if err != nil {
+		return err
+	}
+
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+		"path": p,
+	}).Debug("writing content to file")
+
+	dir := filepath.Dir(p)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(p)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = io.Copy(f, r); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Chmod(0644); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), p)
+}
+
+// openFile opens the file identified by elems inside the directory of
+// the given bucket and returns it, or the range of it selected by opts,
+// as an object.
+func (s *FilesystemStorage) openFile(ctx context.Context, bucket string, opts GetOptions, elems ...string) (*Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p, err := s.path(bucket, elems...)
+	if err != nil {
+		return nil, err
+	}
+
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+		"path":  p,
+		"range": opts.Range,
+	}).Debug("reading content from file")
+
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s.fileObject(f, opts)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	last := len(elems) - 1
+	meta, err := s.readMeta(bucket, append(elems[:last:last], metaName(elems[last]))...)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	obj.Metadata = meta.Metadata
+	return obj, nil
+}
+
+// fileObject returns the object, or the range of it selected by opts,
+// with the content of f. As computing a hash of the content would
+// require reading the whole file, the ETag is derived from its size and
+// modification time.
+func (s *FilesystemStorage) fileObject(f *os.File, opts GetOptions) (*Object, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: %s is not a file", ErrNotFound, f.Name())
+	}
+
+	obj := &Object{
+		Body:         f,
+		Size:         info.Size(),
+		ETag:         fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()),
+		LastModified: info.ModTime(),
+		CacheControl: s.Conf.CacheControl,
+	}
+	if notModified(opts, obj.ETag, obj.LastModified) {
+		return nil, fmt.Errorf("%w: %s", ErrNotModified, obj.ETag)
+	}
+	spec, ok := parseRange(opts.Range)
+	if !ok {
+		return obj, nil
+	}
+
+	r, err := spec.resolve(obj.Size)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(r.Start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	obj.Range = r
+	obj.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, r.Length()), f}
+	return obj, nil
+}
+
+// removeFile removes a file stored under the directory of a bucket.
+// Removing a file that does not exist succeeds.
+func (s *FilesystemStorage) removeFile(bucket string, elems ...string) error {
+	p, err := s.path(bucket, elems...)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// removeObject removes a file stored under the directory of a bucket
+// and its hidden metadata file.
+func (s *FilesystemStorage) removeObject(bucket string, elems ...string) error {
+	if err := s.removeFile(bucket, elems...); err != nil {
+		return err
+	}
+	last := len(elems) - 1
+	return s.removeFile(bucket, append(elems[:last:last], metaName(elems[last]))...)
+}
+
+// path returns the path of the file identified by elems inside the
+// directory of the given bucket. It fails if any of the elements would
+// make the path escape that directory.
+func (s *FilesystemStorage) path(bucket string, elems ...string) (string, error) {
+	for _, e := range elems {
+		if e == "" || e == "." || e == ".." || strings.ContainsAny(e, `/\`) {
+			return "", fmt.Errorf("%w: invalid path element %q", ErrInvalidKey, e)
+		}
+	}
+	return filepath.Join(append([]string{s.Conf.Root, bucket}, elems...)...), nil
+}
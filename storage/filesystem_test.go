@@ -0,0 +1,231 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestFilesystemStorage(t *testing.T) *FilesystemStorage {
+	c := baseConfig
+	c.Root = t.TempDir()
+	l := logrus.New().WithFields(logrus.Fields{"test": t.Name()})
+	s, err := NewFilesystemStorage(c, l)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return s
+}
+
+func TestFilesystemSaveReports(t *testing.T) {
+	testCases := []struct {
+		name       string
+		vulnerable bool
+	}{
+		{name: "not-vulnerable", vulnerable: false},
+		{name: "vulnerable", vulnerable: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestFilesystemStorage(t)
+			startedAt := time.Date(1984, time.April, 4, 13, 0, 0, 0, time.UTC)
+			report := []byte(`{"report":true}`)
+
+			link, err := s.SaveReports(context.Background(), "9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0", startedAt, report, tc.vulnerable, SaveOptions{})
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			expectedLink := "https://vulcan-results-dev.schibsted.io/v1/reports/dt=1984-04-04/scan=9126034c-7caf-4acd-93f3-bee1941aa140/e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json"
+			if link != expectedLink {
+				t.Fatalf("expected link to %v, got: %v", expectedLink, link)
+			}
+
+			dir := filepath.Join("dt=1984-04-04", "scan=9126034c-7caf-4acd-93f3-bee1941aa140")
+			got, err := ioutil.ReadFile(filepath.Join(s.Conf.Root, s.Conf.BucketReports, dir, "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json"))
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !bytes.Equal(got, report) {
+				t.Fatalf("expected report to be '%s', got: '%s'", report, got)
+			}
+
+			gzPath := filepath.Join(s.Conf.Root, s.Conf.BucketVulnerableReports, dir, "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json.gz")
+			f, err := os.Open(gzPath)
+			if !tc.vulnerable {
+				if !os.IsNotExist(err) {
+					t.Fatalf("expected no vulnerable copy, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected vulnerable copy, got: %v", err)
+			}
+			defer f.Close()
+			zr, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatalf("expected gzip content, got: %v", err)
+			}
+			got, err = ioutil.ReadAll(zr)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if !bytes.Equal(got, report) {
+				t.Fatalf("expected vulnerable report to be '%s', got: '%s'", report, got)
+			}
+			if _, err := os.Stat(filepath.Join(s.Conf.Root, s.Conf.BucketReports, "_repairs", dir, "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json")); !os.IsNotExist(err) {
+				t.Fatalf("expected no repair marker, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestFilesystemSaveReportsRollback(t *testing.T) {
+	s := newTestFilesystemStorage(t)
+	startedAt := time.Date(1984, time.April, 4, 13, 0, 0, 0, time.UTC)
+
+	// A directory where the report should be makes the write of the
+	// report fail.
+	dir := filepath.Join(s.Conf.Root, s.Conf.BucketReports, "dt=1984-04-04", "scan=9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json")
+	if err := os.MkdirAll(filepath.Join(dir, "blocker"), 0755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	_, err := s.SaveReports(context.Background(), "9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0", startedAt, []byte("vulnerable new"), true, SaveOptions{})
+	if err == nil {
+		t.Fatalf("expected an error, got none")
+	}
+
+	gz := filepath.Join(s.Conf.Root, s.Conf.BucketVulnerableReports, "dt=1984-04-04", "scan=9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json.gz")
+	if _, err := os.Stat(gz); !os.IsNotExist(err) {
+		t.Fatalf("expected the vulnerable copy to be rolled back, got: %v", err)
+	}
+	marker := filepath.Join(s.Conf.Root, s.Conf.BucketReports, "_repairs", "dt=1984-04-04", "scan=9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json")
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("expected the repair marker to be kept, got: %v", err)
+	}
+
+	// The report stored is the one that was stored before, which is
+	// not vulnerable.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SaveReports(context.Background(), "9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0", startedAt, []byte("clean old"), false, SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// Put back a stale vulnerable copy, as if the rollback had failed.
+	if err := ioutil.WriteFile(gz, []byte("stale"), 0644); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	vulnerable := func(_ string, report []byte, _ map[string]string) (bool, error) {
+		return bytes.HasPrefix(report, []byte("vulnerable")), nil
+	}
+
+	// Recent markers are skipped.
+	if n, err := s.RepairReports(context.Background(), time.Hour, vulnerable); err != nil || n != 0 {
+		t.Fatalf("expected no reports repaired, got: %d, %v", n, err)
+	}
+	n, err := s.RepairReports(context.Background(), 0, vulnerable)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 report repaired, got: %d", n)
+	}
+	if _, err := os.Stat(gz); !os.IsNotExist(err) {
+		t.Fatalf("expected the vulnerable copy of a report not vulnerable to be removed, got: %v", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("expected the repair marker to be removed, got: %v", err)
+	}
+
+	// A vulnerable report is copied.
+	if err := s.writeFile(context.Background(), s.Conf.BucketReports, nil, "_repairs", "dt=1984-04-04", "scan=9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SaveReports(context.Background(), "9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0", startedAt, []byte("vulnerable old"), false, SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n, err := s.RepairReports(context.Background(), 0, vulnerable); err != nil || n != 1 {
+		t.Fatalf("expected 1 report repaired, got: %d, %v", n, err)
+	}
+	f, err := os.Open(gz)
+	if err != nil {
+		t.Fatalf("expected the vulnerable copy to be rewritten, got: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("expected gzip content, got: %v", err)
+	}
+	if got, err := ioutil.ReadAll(zr); err != nil || string(got) != "vulnerable old" {
+		t.Fatalf("expected the vulnerable copy to agree with the report, got: %q, %v", got, err)
+	}
+}
+
+func TestFilesystemRoundTrip(t *testing.T) {
+	s := newTestFilesystemStorage(t)
+	startedAt := time.Date(2019, time.November, 16, 0, 0, 0, 0, time.UTC)
+
+	if _, err := s.SaveReports(context.Background(), "9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0", startedAt, []byte("report"), false, SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.SaveLogs(context.Background(), "9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0", startedAt, []byte("log")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	report, err := s.GetReport(context.Background(), "dt=2019-11-16", "scan=9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.json", GetOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if content := objectContent(t, report); content != "report" {
+		t.Fatalf("expected report to be 'report', got: '%s'", content)
+	}
+
+	log, err := s.GetLog(context.Background(), "dt=2019-11-16", "scan=9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0.log", GetOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if content := objectContent(t, log); content != "log" {
+		t.Fatalf("expected log to be 'log', got: '%s'", content)
+	}
+
+	// No temporary files must be left behind.
+	dir := filepath.Join(s.Conf.Root, s.Conf.BucketReports, "dt=2019-11-16", "scan=9126034c-7caf-4acd-93f3-bee1941aa140")
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 file in %s, got: %d", dir, len(entries))
+	}
+}
+
+func TestFilesystemGetInvalidPath(t *testing.T) {
+	s := newTestFilesystemStorage(t)
+
+	_, err := s.GetReport(context.Background(), "..", "..", "passwd", GetOptions{})
+	if !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected error %v, got: %v", ErrInvalidKey, err)
+	}
+
+	_, err = s.GetLog(context.Background(), "dt=2019-11-16", "scan=9126034c-7caf-4acd-93f3-bee1941aa140", "missing.log", GetOptions{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected error %v, got: %v", ErrNotFound, err)
+	}
+}
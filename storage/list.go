@@ -0,0 +1,357 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// Kinds of the objects stored for a check.
+const (
+	KindReport = "report"
+	KindLog    = "log"
+)
+
+// CheckObject describes a report or a log stored for a check.
+type CheckObject struct {
+	CheckID      string
+	Kind         string
+	Size         int64
+	LastModified time.Time
+}
+
+// CheckPage is a page of the objects stored for the checks of a scan.
+type CheckPage struct {
+	Checks []CheckObject
+	// Next is the token to pass to ListChecks to get the next page. It
+	// is empty when there are no more pages.
+	Next string
+}
+
+// listPhase is one of the locations walked, in order, when listing the
+// checks of a scan.
+type listPhase struct {
+	kind   string
+	bucket func(Config) string
+	suffix string
+}
+
+var listPhases = []listPhase{
+	{kind: KindReport, bucket: func(c Config) string { return c.BucketReports }, suffix: ".json"},
+	{kind: KindLog, bucket: func(c Config) string { return c.BucketLogs }, suffix: ".log"},
+}
+
+// pageToken identifies where a listing must be resumed: the phase and a
+// backend specific marker inside it.
+type pageToken struct {
+	phase  int
+	marker string
+}
+
+func (t pageToken) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", listPhases[t.phase].kind, t.marker)))
+}
+
+func parsePageToken(s string) (pageToken, error) {
+	if s == "" {
+		return pageToken{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageToken{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	parts := strings.SplitN(string(b), ":", 2)
+	if len(parts) != 2 {
+		return pageToken{}, ErrInvalidToken
+	}
+	for i, p := range listPhases {
+		if p.kind == parts[0] {
+			return pageToken{phase: i, marker: parts[1]}, nil
+		}
+	}
+	return pageToken{}, ErrInvalidToken
+}
+
+// scanPrefix returns the prefix under which the results of a scan
+// started at the given date are stored.
+func scanPrefix(date, scanID string) (string, error) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid date %q", ErrInvalidKey, date)
+	}
+	if scanID == "" || strings.ContainsAny(scanID, `/\`) || scanID == "." || scanID == ".." {
+		return "", fmt.Errorf("%w: invalid scan %q", ErrInvalidKey, scanID)
+	}
+	dt, scan := Partition(scanID, t)
+	return dt + "/" + scan + "/", nil
+}
+
+// ListChecks lists, page by page, the reports and logs stored in S3 for
+// the checks of a scan. The pages are backed by the continuation tokens
+// of ListObjectsV2, so a page can contain less than limit checks even
+// if there are more to list.
+func (s *S3Storage) ListChecks(ctx context.Context, date, scanID, token string, limit int) (*CheckPage, error) {
+	prefix, err := scanPrefix(date, scanID)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := parsePageToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &CheckPage{Checks: []CheckObject{}}
+	for ; tok.phase < len(listPhases); tok = (pageToken{phase: tok.phase + 1}) {
+		phase := listPhases[tok.phase]
+		bucket := phase.bucket(s.Conf)
+
+		for {
+			remaining := limit - len(page.Checks)
+			if remaining <= 0 {
+				page.Next = tok.String()
+				return page, nil
+			}
+
+			contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+				"prefix": prefix,
+				"bucket": bucket,
+			}).Debug("listing objects in S3 bucket")
+
+			params := &s3.ListObjectsV2Input{
+				Bucket:  aws.String(bucket),
+				Prefix:  aws.String(prefix),
+				MaxKeys: aws.Int64(int64(remaining)),
+			}
+			if tok.marker != "" {
+				params.ContinuationToken = aws.String(tok.marker)
+			}
+			out, err := s.listObjectsPage(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, obj := range out.Contents {
+				key := aws.StringValue(obj.Key)
+				if !strings.HasSuffix(key, phase.suffix) {
+					continue
+				}
+				page.Checks = append(page.Checks, CheckObject{
+					CheckID:      strings.TrimSuffix(strings.TrimPrefix(key, prefix), phase.suffix),
+					Kind:         phase.kind,
+					Size:         aws.Int64Value(obj.Size),
+					LastModified: aws.TimeValue(obj.LastModified),
+				})
+			}
+
+			if !aws.BoolValue(out.IsTruncated) {
+				break
+			}
+			tok.marker = aws.StringValue(out.NextContinuationToken)
+		}
+	}
+
+	return page, nil
+}
+
+// listSorted builds a page of checks from objects already sorted by key
+// in every phase, using the key of the last returned object as marker.
+// It is used by the backends that can not paginate natively.
+func listSorted(tok pageToken, limit int, objects func(phase listPhase) ([]CheckObject, error)) (*CheckPage, error) {
+	page := &CheckPage{Checks: []CheckObject{}}
+	for ; tok.phase < len(listPhases); tok = (pageToken{phase: tok.phase + 1}) {
+		objs, err := objects(listPhases[tok.phase])
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if obj.CheckID <= tok.marker {
+				continue
+			}
+			if len(page.Checks) >= limit {
+				page.Next = tok.String()
+				return page, nil
+			}
+			page.Checks = append(page.Checks, obj)
+			tok.marker = obj.CheckID
+		}
+	}
+	return page, nil
+}
+
+// ScanSummary describes the reports stored for a scan.
+type ScanSummary struct {
+	ScanID string
+	// Date is the day the scan started.
+	Date time.Time
+	// Checks is the number of checks of the scan with a report.
+	Checks int
+	// Vulnerable tells whether any of the reports of the scan has
+	// vulnerabilities.
+	Vulnerable bool
+}
+
+// MaxScanRangeDays is the maximum number of days ListScans accepts in a
+// range of dates.
+const MaxScanRangeDays = 31
+
+// scanDays returns the days between from and to, both included.
+func scanDays(from, to time.Time) ([]time.Time, error) {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: the end of the range is before its start", ErrInvalidRange)
+	}
+	if to.Sub(from) >= MaxScanRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: the range can not span more than %d days", ErrInvalidRange, MaxScanRangeDays)
+	}
+
+	var days []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// splitScanKey splits a key stored under the date partition dt into the
+// ID of its scan and the name of the object. It returns false if the key
+// is not directly under a scan partition of that date.
+func splitScanKey(dt, key string) (scanID, name string, ok bool) {
+	rest := strings.TrimPrefix(key, dt+"scan=")
+	if rest == key {
+		return "", "", false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// ListScans returns the scans that stored reports in S3 between the
+// given dates, both included. The scans are found by walking the scan
+// partitions under the date partition of every day in the range.
+func (s *S3Storage) ListScans(ctx context.Context, from, to time.Time) ([]ScanSummary, error) {
+	days, err := scanDays(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	scans := []ScanSummary{}
+	for _, day := range days {
+		dt := day.Format("dt=2006-01-02") + "/"
+
+		var prefixes []string
+		err := s.listObjects(ctx, s.Conf.BucketReports, dt, "/", func(out *s3.ListObjectsV2Output) {
+			for _, p := range out.CommonPrefixes {
+				prefixes = append(prefixes, aws.StringValue(p.Prefix))
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, prefix := range prefixes {
+			scanID := strings.TrimSuffix(strings.TrimPrefix(prefix, dt+"scan="), "/")
+			if scanID == prefix {
+				continue
+			}
+			summary, err := s.scanSummary(ctx, prefix)
+			if err != nil {
+				return nil, err
+			}
+			if summary.Checks == 0 {
+				continue
+			}
+			summary.ScanID = scanID
+			summary.Date = day
+			scans = append(scans, summary)
+		}
+	}
+
+	return scans, nil
+}
+
+// scanSummary counts the reports stored under the prefix of a scan and
+// checks whether any of them has a vulnerable copy.
+func (s *S3Storage) scanSummary(ctx context.Context, prefix string) (ScanSummary, error) {
+	var summary ScanSummary
+	err := s.listObjects(ctx, s.Conf.BucketReports, prefix, "", func(out *s3.ListObjectsV2Output) {
+		for _, obj := range out.Contents {
+			key := aws.StringValue(obj.Key)
+			switch {
+			case strings.HasSuffix(key, ".json"):
+				summary.Checks++
+			case strings.HasSuffix(key, ".json.gz") && s.Conf.BucketVulnerableReports == s.Conf.BucketReports:
+				summary.Vulnerable = true
+			}
+		}
+	})
+	if err != nil {
+		return ScanSummary{}, err
+	}
+
+	if s.Conf.BucketVulnerableReports == s.Conf.BucketReports {
+		return summary, nil
+	}
+
+	out, err := s.listObjectsPage(ctx, &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.Conf.BucketVulnerableReports),
+		Prefix:  aws.String(prefix),
+		MaxKeys: aws.Int64(1),
+	})
+	if err != nil {
+		return ScanSummary{}, err
+	}
+	summary.Vulnerable = len(out.Contents) > 0
+
+	return summary, nil
+}
+
+// listObjects calls f with every page of the listing of the objects of a
+// bucket under the given prefix.
+func (s *S3Storage) listObjects(ctx context.Context, bucket, prefix, delimiter string, f func(*s3.ListObjectsV2Output)) error {
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+		"prefix": prefix,
+		"bucket": bucket,
+	}).Debug("listing objects in S3 bucket")
+
+	params := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	if delimiter != "" {
+		params.Delimiter = aws.String(delimiter)
+	}
+
+	for {
+		out, err := s.listObjectsPage(ctx, params)
+		if err != nil {
+			return err
+		}
+		f(out)
+		if !aws.BoolValue(out.IsTruncated) {
+			return nil
+		}
+		params.ContinuationToken = out.NextContinuationToken
+	}
+}
+
+// listObjectsPage returns a page of the listing of the objects of a
+// bucket.
+func (s *S3Storage) listObjectsPage(ctx context.Context, params *s3.ListObjectsV2Input) (out *s3.ListObjectsV2Output, err error) {
+	err = s.do(ctx, "ListObjectsV2", aws.StringValue(params.Bucket), func(ctx context.Context) (err error) {
+		out, err = s.svc.ListObjectsV2WithContext(ctx, params)
+		return err
+	})
+	return out, err
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"maps"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStorage implements the Storage interface keeping the results in
+// memory. It is meant to be used in tests and local environments.
+type MemoryStorage struct {
+	Conf Config
+
+	mu      sync.RWMutex
+	buckets map[string]map[string]memoryObject
+}
+
+type memoryObject struct {
+	content      []byte
+	etag         string
+	lastModified time.Time
+	meta         reportMeta
+}
+
+// NewMemoryStorage creates an empty MemoryStorage.
+func NewMemoryStorage(c Config) *MemoryStorage {
+	return &MemoryStorage{Conf: c, buckets: map[string]map[string]memoryObject{}}
+}
+
+// SaveReports stores the report in memory.
+func (s *MemoryStorage) SaveReports(ctx context.Context, scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool, opts SaveOptions) (link string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	dt, scan := Partition(scanID, startedAt)
+	key := path.Join(dt, scan, checkID+".json")
+
+	link, err = urlConcat(s.Conf.LinkBase, "reports", dt, scan, checkID+".json")
+	if err != nil {
+		return "", err
+	}
+
+	var gz []byte
+	if vulnerable {
+		gz, err = gzipContent(report)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.buckets[s.Conf.BucketReports][key]; ok {
+		skip, err := checkSave(opts, stored.meta)
+		if err != nil {
+			return "", err
+		}
+		if skip {
+			return link, nil
+		}
+	}
+
+	meta := opts.meta()
+	meta.Metadata = objectMetadata(ctx)
+	if vulnerable {
+		s.put(s.Conf.BucketVulnerableReports, key+".gz", gz, reportMeta{Metadata: meta.Metadata})
+	}
+	s.put(s.Conf.BucketReports, key, report, meta)
+
+	return link, nil
+}
+
+// SaveLogs stores the logs in memory.
+func (s *MemoryStorage) SaveLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs []byte) (link string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	dt, scan := Partition(scanID, startedAt)
+	key := path.Join(dt, scan, checkID+".log")
+
+	link, err = urlConcat(s.Conf.LinkBase, "logs", dt, scan, checkID+".log")
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(s.Conf.BucketLogs, key, logs, reportMeta{Metadata: objectMetadata(ctx)})
+
+	return link, nil
+}
+
+// StreamLogs reads the logs and stores them in memory.
+func (s *MemoryStorage) StreamLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs io.Reader) (link string, err error) {
+	content, err := ioutil.ReadAll(logs)
+	if err != nil {
+		return "", err
+	}
+	return s.SaveLogs(ctx, scanID, checkID, startedAt, content)
+}
+
+// GetReport returns the report that corresponds to the input params.
+func (s *MemoryStorage) GetReport(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error) {
+	return s.get(ctx, s.Conf.BucketReports, date, scanID, checkID, opts)
+}
+
+// GetLog returns the log that corresponds to the input params.
+func (s *MemoryStorage) GetLog(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error) {
+	return s.get(ctx, s.Conf.BucketLogs, date, scanID, checkID, opts)
+}
+
+// Object returns a copy of the content stored under the given bucket
+// and key, and whether it exists.
+func (s *MemoryStorage) Object(bucket, key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.buckets[bucket][key]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), obj.content...), true
+}
+
+// Metadata returns a copy of the user metadata of the object stored
+// under the given bucket and key.
+func (s *MemoryStorage) Metadata(bucket, key string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return maps.Clone(s.buckets[bucket][key].meta.Metadata)
+}
+
+// ListChecks returns a page of the reports and logs stored in memory for
+// the checks of a scan.
+func (s *MemoryStorage) ListChecks(ctx context.Context, date, scanID, token string, limit int) (*CheckPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	prefix, err := scanPrefix(date, scanID)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := parsePageToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return listSorted(tok, limit, func(phase listPhase) ([]CheckObject, error) {
+		var objs []CheckObject
+		for key, obj := range s.buckets[phase.bucket(s.Conf)] {
+			name := strings.TrimPrefix(key, prefix)
+			if name == key || strings.Contains(name, "/") || !strings.HasSuffix(name, phase.suffix) {
+				continue
+			}
+			objs = append(objs, CheckObject{
+				CheckID:      strings.TrimSuffix(name, phase.suffix),
+				Kind:         phase.kind,
+				Size:         int64(len(obj.content)),
+				LastModified: obj.lastModified,
+			})
+		}
+		sort.Slice(objs, func(i, j int) bool { return objs[i].CheckID < objs[j].CheckID })
+		return objs, nil
+	})
+}
+
+// ListScans returns the scans that stored reports in memory between the
+// given dates, both included.
+func (s *MemoryStorage) ListScans(ctx context.Context, from, to time.Time) ([]ScanSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	days, err := scanDays(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	scans := []ScanSummary{}
+	for _, day := range days {
+		dt := day.Format("dt=2006-01-02") + "/"
+		summaries := map[string]*ScanSummary{}
+		for key := range s.buckets[s.Conf.BucketReports] {
+			scanID, name, ok := splitScanKey(dt, key)
+			if !ok || !strings.HasSuffix(name, ".json") {
+				continue
+			}
+			summary, ok := summaries[scanID]
+			if !ok {
+				summary = &ScanSummary{ScanID: scanID, Date: day}
+				summaries[scanID] = summary
+			}
+			summary.Checks++
+		}
+		for key := range s.buckets[s.Conf.BucketVulnerableReports] {
+			scanID, name, ok := splitScanKey(dt, key)
+			if !ok || !strings.HasSuffix(name, ".json.gz") {
+				continue
+			}
+			if summary, ok := summaries[scanID]; ok {
+				summary.Vulnerable = true
+			}
+		}
+
+		ids := make([]string, 0, len(summaries))
+		for id := range summaries {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			scans = append(scans, *summaries[id])
+		}
+	}
+
+	return scans, nil
+}
+
+func (s *MemoryStorage) put(bucket, key string, content []byte, meta reportMeta) {
+	b, ok := s.buckets[bucket]
+	if !ok {
+		b = map[string]memoryObject{}
+		s.buckets[bucket] = b
+	}
+	b[key] = memoryObject{
+		content:      append([]byte(nil), content...),
+		etag:         contentETag(content),
+		lastModified: time.Now(),
+		meta:         meta,
+	}
+}
+
+func (s *MemoryStorage) get(ctx context.Context, bucket, date, scanID, checkID string, opts GetOptions) (*Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", date, scanID, checkID)
+
+	s.mu.RLock()
+	obj, ok := s.buckets[bucket][key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s in bucket %s", ErrNotFound, key, bucket)
+	}
+	// Stored contents are never modified, only replaced, so they can be
+	// returned without copying them.
+	return bytesObject(obj.content, &Object{
+		ETag:         obj.etag,
+		LastModified: obj.lastModified,
+		CacheControl: s.Conf.CacheControl,
+		Metadata:     obj.meta.Metadata,
+	}, opts)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	metrics "github.com/adevinta/vulcan-metrics-client"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// Names of the metrics of the calls to S3.
+const (
+	// MetricDuration is the time, in milliseconds, a call to S3 takes,
+	// including its retries. For GetObject it is the time until the
+	// body of the object starts to be received.
+	MetricDuration = "vulcan.storage.duration"
+	// MetricFailed counts the calls to S3 that fail, tagged with the
+	// code of the error. The answers of S3 for objects that do not
+	// exist, were not modified or do not overlap the requested range
+	// are not failures.
+	MetricFailed = "vulcan.storage.failed"
+	// MetricObjectSize is the size, in bytes, of the content uploaded
+	// to and downloaded from S3, as stored in S3.
+	MetricObjectSize = "vulcan.storage.object_size"
+	// MetricCompressionRatio is the size of the content of the gzipped
+	// objects divided by the size of the objects.
+	MetricCompressionRatio = "vulcan.storage.compression_ratio"
+
+	tagComponent = "component:results"
+)
+
+// Codes of the errors that are not returned by S3.
+const (
+	errorCodeCircuitOpen = "CircuitOpen"
+	errorCodeTimeout     = "Timeout"
+	errorCodeCanceled    = "Canceled"
+	errorCodeUnknown     = "Unknown"
+)
+
+// push pushes a metric of a call to S3 to the metrics client of the
+// storage, if any.
+func (s *S3Storage) push(name string, typ metrics.Type, value float64, op, bucket string, tags ...string) {
+	if s.Metrics == nil {
+		return
+	}
+	s.Metrics.Push(metrics.Metric{
+		Name:  name,
+		Typ:   typ,
+		Value: value,
+		Tags:  append([]string{tagComponent, "op:" + op, "bucket:" + bucket}, tags...),
+	})
+}
+
+// observeCall pushes the duration of a call to S3 and, if it failed, its
+// error.
+func (s *S3Storage) observeCall(op, bucket string, d time.Duration, err error) {
+	s.push(MetricDuration, metrics.Histogram, float64(d)/float64(time.Millisecond), op, bucket)
+	if err != nil && !expectedError(err) {
+		s.push(MetricFailed, metrics.Count, 1, op, bucket, "error_code:"+errorCode(err))
+	}
+}
+
+// expectedError tells whether err, classified with s3Error, is an answer
+// of S3 to a correct call, like the ones to download objects that do not
+// exist or were not modified.
+func expectedError(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrNotModified) || errors.Is(err, ErrRangeNotSatisfiable)
+}
+
+// observeSize pushes the size of an object uploaded to or downloaded
+// from S3.
+func (s *S3Storage) observeSize(op, bucket string, size int64) {
+	s.push(MetricObjectSize, metrics.Histogram, float64(size), op, bucket)
+}
+
+// observeCompression pushes the compression ratio of an object gzipped
+// before being uploaded to S3.
+func (s *S3Storage) observeCompression(bucket string, size, compressed int) {
+	if compressed == 0 {
+		return
+	}
+	s.push(MetricCompressionRatio, metrics.Histogram, float64(size)/float64(compressed), "PutObject", bucket)
+}
+
+// errorCode returns the code of the AWS error in the chain of err or,
+// if there is none, a code that describes why the call to S3 failed.
+func errorCode(err error) string {
+	var aerr awserr.Error
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return errorCodeCircuitOpen
+	case errors.As(err, &aerr):
+		return aerr.Code()
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorCodeTimeout
+	case errors.Is(err, context.Canceled):
+		return errorCodeCanceled
+	default:
+		return errorCodeUnknown
+	}
+}
+
+// countingReader is a reader that counts the bytes read from it.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
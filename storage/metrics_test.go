@@ -0,0 +1,117 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	metrics "github.com/adevinta/vulcan-metrics-client"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+// metricsRecorder is a metrics client that records the metrics pushed
+// to it.
+type metricsRecorder struct {
+	metrics []metrics.Metric
+}
+
+func (r *metricsRecorder) Push(m metrics.Metric) {
+	r.metrics = append(r.metrics, m)
+}
+
+func (r *metricsRecorder) PushWithRate(m metrics.RatedMetric) {
+	r.Push(m.Metric)
+}
+
+// find returns the metrics with the given name and tags.
+func (r *metricsRecorder) find(name string, tags ...string) []metrics.Metric {
+	var found []metrics.Metric
+	for _, m := range r.metrics {
+		if m.Name == name && strings.Contains(strings.Join(m.Tags, ",")+",", strings.Join(tags, ",")+",") {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+func TestS3Metrics(t *testing.T) {
+	c := storage.Config{
+		BucketReports:           "reports",
+		BucketVulnerableReports: "vulnerable",
+		BucketLogs:              "logs",
+	}
+	svc := storagetest.NewFakeS3()
+	rec := &metricsRecorder{}
+	s := storage.NewS3Storage(c, logrus.NewEntry(logrus.New()), svc)
+	s.Metrics = rec
+
+	ctx := context.Background()
+	startedAt := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	report := bytes.Repeat([]byte(`{"vulnerabilities":[]}`), 100)
+	if _, err := s.SaveReports(ctx, "scan", "check", startedAt, report, true, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := s.StreamLogs(ctx, "scan", "check", startedAt, strings.NewReader("logs")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	// The repair marker of the vulnerable report is uploaded first.
+	if m := rec.find(storage.MetricDuration, "component:results", "op:PutObject", "bucket:reports"); len(m) != 2 || m[1].Typ != metrics.Histogram {
+		t.Fatalf("expected the duration of the upload of the report, got: %v", rec.metrics)
+	}
+	if m := rec.find(storage.MetricObjectSize, "component:results", "op:PutObject", "bucket:reports"); len(m) != 2 || m[1].Value != float64(len(report)) {
+		t.Fatalf("expected the size of the report, got: %v", rec.metrics)
+	}
+	m := rec.find(storage.MetricCompressionRatio, "component:results", "op:PutObject", "bucket:vulnerable")
+	if len(m) != 1 || m[0].Value <= 1 {
+		t.Fatalf("expected the compression ratio of the vulnerable report, got: %v", rec.metrics)
+	}
+	if m := rec.find(storage.MetricObjectSize, "component:results", "op:Upload", "bucket:logs"); len(m) != 1 || m[0].Value != 4 {
+		t.Fatalf("expected the size of the logs, got: %v", rec.metrics)
+	}
+	if m := rec.find(storage.MetricFailed); len(m) != 0 {
+		t.Fatalf("expected no failed calls, got: %v", m)
+	}
+
+	// The objects that do not exist or were not modified are answers,
+	// not failures.
+	if _, err := s.GetLog(ctx, "dt=2020-06-01", "scan=missing", "check.log", storage.GetOptions{}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound getting a missing log, got: %v", err)
+	}
+	obj, err := s.GetLog(ctx, "dt=2020-06-01", "scan=scan", "check.log", storage.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	obj.Body.Close()
+	if _, err := s.GetLog(ctx, "dt=2020-06-01", "scan=scan", "check.log", storage.GetOptions{IfNoneMatch: obj.ETag}); !errors.Is(err, storage.ErrNotModified) {
+		t.Fatalf("expected ErrNotModified getting an unmodified log, got: %v", err)
+	}
+	if m := rec.find(storage.MetricDuration, "op:GetObject", "bucket:logs"); len(m) != 3 {
+		t.Fatalf("expected the duration of the GetObject calls, got: %v", rec.metrics)
+	}
+	if m := rec.find(storage.MetricFailed); len(m) != 0 {
+		t.Fatalf("expected no failed calls, got: %v", m)
+	}
+
+	// Errors are tagged with their AWS error code.
+	svc.Fault = func(op, bucket, key string) error {
+		return awserr.New("AccessDenied", "Access Denied", nil)
+	}
+	if _, err := s.SaveLogs(ctx, "scan", "check", startedAt, []byte("logs")); err == nil {
+		t.Fatalf("expected an error saving the logs")
+	}
+	if m := rec.find(storage.MetricFailed, "component:results", "op:PutObject", "bucket:logs", "error_code:AccessDenied"); len(m) != 1 || m[0].Typ != metrics.Count {
+		t.Fatalf("expected a failed PutObject call, got: %v", rec.metrics)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetOptions are the options of a download.
+type GetOptions struct {
+	// Range is the value of the HTTP Range header of the request, if
+	// any. Only single byte ranges are honored, any other range is
+	// ignored and the whole object is returned.
+	Range string
+	// IfNoneMatch is the value of the HTTP If-None-Match header of the
+	// request, if any. The download fails with ErrNotModified if it
+	// matches the ETag of the object.
+	IfNoneMatch string
+	// IfModifiedSince, if not zero, makes the download fail with
+	// ErrNotModified if the object was not modified after it. It is
+	// ignored when IfNoneMatch is set.
+	IfModifiedSince time.Time
+}
+
+// Object is a stored object being downloaded.
+type Object struct {
+	// Body is the content of the object, or of the requested range of
+	// it. It must be closed by the caller.
+	Body io.ReadCloser
+	// Size is the size of the whole object.
+	Size int64
+	// Range is the range of the object returned in Body. It is nil
+	// when the whole object is returned.
+	Range *ByteRange
+	// ETag identifies the version of the object.
+	ETag string
+	// LastModified is the last time the object was modified.
+	LastModified time.Time
+	// CacheControl is the value of the HTTP Cache-Control header to
+	// return with the object, if any.
+	CacheControl string
+	// Metadata is the user metadata stored with the object, other than
+	// the options of the write of a report, with the keys in canonical
+	// form.
+	Metadata map[string]string
+}
+
+type objectMetadataKey struct{}
+
+// withObjectMetadata returns a context that makes the storages store md
+// as the user metadata of the objects written with it, so it is kept
+// with the object, like its content, even if it is copied to other
+// buckets.
+func withObjectMetadata(ctx context.Context, md map[string]string) context.Context {
+	return context.WithValue(ctx, objectMetadataKey{}, md)
+}
+
+// objectMetadata returns the user metadata to store with the objects
+// written with ctx, if any.
+func objectMetadata(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(objectMetadataKey{}).(map[string]string)
+	return md
+}
+
+// ContentLength returns the number of bytes of Body.
+func (o *Object) ContentLength() int64 {
+	if o.Range != nil {
+		return o.Range.Length()
+	}
+	return o.Size
+}
+
+// ByteRange is a range of bytes of an object, both ends included.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Length returns the number of bytes of the range.
+func (r ByteRange) Length() int64 {
+	return r.End - r.Start + 1
+}
+
+// ContentRange returns the value of the HTTP Content-Range header for
+// the range of an object of the given size.
+func (r ByteRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.End, size)
+}
+
+// rangeSpec is a single byte range as specified in a Range header. A
+// negative first means the range is the last bytes of the object, and a
+// negative last means the range ends at the end of the object.
+type rangeSpec struct {
+	first int64
+	last  int64
+}
+
+// parseRange parses a Range header. It returns false if the header is
+// empty, malformed or specifies more than one range, as those headers
+// must be ignored.
+func parseRange(h string) (rangeSpec, bool) {
+	spec := strings.TrimPrefix(h, "bytes=")
+	if spec == h || strings.Contains(spec, ",") {
+		return rangeSpec{}, false
+	}
+	parts := strings.SplitN(strings.TrimSpace(spec), "-", 2)
+	if len(parts) != 2 {
+		return rangeSpec{}, false
+	}
+
+	r := rangeSpec{first: -1, last: -1}
+	var err error
+	if parts[0] != "" {
+		if r.first, err = strconv.ParseInt(parts[0], 10, 64); err != nil || r.first < 0 {
+			return rangeSpec{}, false
+		}
+	}
+	if parts[1] != "" {
+		if r.last, err = strconv.ParseInt(parts[1], 10, 64); err != nil || r.last < 0 {
+			return rangeSpec{}, false
+		}
+	}
+	if r.first < 0 && r.last < 0 || r.first >= 0 && r.last >= 0 && r.last < r.first {
+		return rangeSpec{}, false
+	}
+	return r, true
+}
+
+// resolve returns the range of an object of the given size selected by
+// the spec. It fails with ErrRangeNotSatisfiable if the range does not
+// overlap the object.
+func (r rangeSpec) resolve(size int64) (*ByteRange, error) {
+	if r.first < 0 {
+		// Suffix range: the last r.last bytes.
+		if r.last == 0 || size == 0 {
+			return nil, fmt.Errorf("%w: bytes=-%d of %d bytes", ErrRangeNotSatisfiable, r.last, size)
+		}
+		if r.last > size {
+			r.last = size
+		}
+		return &ByteRange{Start: size - r.last, End: size - 1}, nil
+	}
+	if r.first >= size {
+		return nil, fmt.Errorf("%w: bytes=%d- of %d bytes", ErrRangeNotSatisfiable, r.first, size)
+	}
+	end := size - 1
+	if r.last >= 0 && r.last < end {
+		end = r.last
+	}
+	return &ByteRange{Start: r.first, End: end}, nil
+}
+
+// parseContentRange parses a Content-Range header returned by S3 for a
+// range request.
+func parseContentRange(h string) (r ByteRange, size int64, ok bool) {
+	if _, err := fmt.Sscanf(h, "bytes %d-%d/%d", &r.Start, &r.End, &size); err != nil {
+		return ByteRange{}, 0, false
+	}
+	return r, size, true
+}
+
+// notModified tells whether the conditions in opts mean the object
+// with the given ETag and modification time must not be returned, as
+// specified for the HTTP If-None-Match and If-Modified-Since headers.
+func notModified(opts GetOptions, etag string, lastModified time.Time) bool {
+	if opts.IfNoneMatch != "" {
+		for _, tag := range strings.Split(opts.IfNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if opts.IfModifiedSince.IsZero() {
+		return false
+	}
+	// HTTP dates have a resolution of one second.
+	return !lastModified.Truncate(time.Second).After(opts.IfModifiedSince)
+}
+
+// contentETag returns an ETag for the given content computed as S3 does
+// for objects uploaded in a single part.
+func contentETag(content []byte) string {
+	return fmt.Sprintf(`"%x"`, md5.Sum(content))
+}
+
+// bytesObject returns the object, or the range of it selected by opts,
+// with the given content and metadata.
+func bytesObject(content []byte, obj *Object, opts GetOptions) (*Object, error) {
+	if notModified(opts, obj.ETag, obj.LastModified) {
+		return nil, fmt.Errorf("%w: %s", ErrNotModified, obj.ETag)
+	}
+	obj.Size = int64(len(content))
+	if spec, ok := parseRange(opts.Range); ok {
+		r, err := spec.resolve(obj.Size)
+		if err != nil {
+			return nil, err
+		}
+		obj.Range = r
+		content = content[r.Start : r.End+1]
+	}
+	obj.Body = ioutil.NopCloser(bytes.NewReader(content))
+	return obj, nil
+}
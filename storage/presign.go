@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultPresignExpiry is the time the presigned URLs are valid unless
+// another one is configured.
+const DefaultPresignExpiry = 15 * time.Minute
+
+// MaxPresignExpiry is the longest time S3 accepts the presigned URLs to
+// be valid.
+const MaxPresignExpiry = 7 * 24 * time.Hour
+
+// PresignedURL is a time-limited URL to download an object directly
+// from the storage backend.
+type PresignedURL struct {
+	URL     string
+	Expires time.Time
+}
+
+// Presigner is implemented by the storages that can hand out URLs to
+// download the stored objects without going through the service.
+// Storages that transform the objects, like EnvelopeStorage, must not
+// implement it.
+type Presigner interface {
+	// PresignReport and PresignLog return a presigned URL to download
+	// the report or log identified by the same params as GetReport and
+	// GetLog. They fail with ErrNotFound if the object does not exist.
+	PresignReport(ctx context.Context, date, scanID, checkID string) (PresignedURL, error)
+	PresignLog(ctx context.Context, date, scanID, checkID string) (PresignedURL, error)
+}
+
+// PresignReport returns a presigned URL to download a report from S3.
+func (s *S3Storage) PresignReport(ctx context.Context, date, scanID, checkID string) (PresignedURL, error) {
+	key := fmt.Sprintf("%s/%s/%s", date, scanID, checkID)
+	return s.presign(ctx, s.Conf.BucketReports, key)
+}
+
+// PresignLog returns a presigned URL to download a log from S3.
+func (s *S3Storage) PresignLog(ctx context.Context, date, scanID, checkID string) (PresignedURL, error) {
+	key := fmt.Sprintf("%s/%s/%s", date, scanID, checkID)
+	return s.presign(ctx, s.Conf.BucketLogs, key)
+}
+
+// presign returns a presigned GET URL for an object, valid for the
+// configured expiry. Presigning does not call S3, so the object is
+// checked to exist first.
+func (s *S3Storage) presign(ctx context.Context, bucket, key string) (PresignedURL, error) {
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+		"key":    key,
+		"bucket": bucket,
+	}).Debug("presigning object in S3 bucket")
+
+	err := s.do(ctx, "HeadObject", bucket, func(ctx context.Context) error {
+		_, err := s.svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		return err
+	})
+	if err != nil {
+		return PresignedURL{}, err
+	}
+
+	expiry := s.Conf.PresignExpiry
+	if expiry <= 0 {
+		expiry = DefaultPresignExpiry
+	}
+	req, _ := s.svc.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	expires := time.Now().Add(expiry)
+	u, err := req.Presign(expiry)
+	if err != nil {
+		return PresignedURL{}, fmt.Errorf("presigning %s in bucket %s: %w", key, bucket, err)
+	}
+	return PresignedURL{URL: u, Expires: expires}, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+func TestPresign(t *testing.T) {
+	c := storage.Config{
+		BucketReports:           "reports",
+		BucketVulnerableReports: "vulnerable-reports",
+		BucketLogs:              "logs",
+		LinkBase:                "http://results/v1",
+		PresignExpiry:           time.Hour,
+	}
+	svc := storagetest.NewFakeS3()
+	s := storage.NewS3Storage(c, logrus.NewEntry(logrus.New()), svc)
+
+	ctx := context.Background()
+	startedAt := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	scanID, checkID := "9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0"
+	if _, err := s.SaveLogs(ctx, scanID, checkID, startedAt, []byte("log")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	before := time.Now()
+	u, err := s.PresignLog(ctx, "dt=2019-11-01", "scan="+scanID, checkID+".log")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	parsed, err := url.Parse(u.URL)
+	if err != nil {
+		t.Fatalf("expected a valid URL, got: %v", err)
+	}
+	if parsed.Host != "logs.fake-s3" || parsed.Path != "/dt=2019-11-01/scan="+scanID+"/"+checkID+".log" {
+		t.Fatalf("unexpected presigned URL: %s", u.URL)
+	}
+	if exp := parsed.Query().Get("X-Amz-Expires"); exp != "3600" {
+		t.Fatalf("expected the URL to expire in 3600 seconds, got: %s", exp)
+	}
+	if u.Expires.Before(before.Add(time.Hour)) || u.Expires.After(time.Now().Add(time.Hour)) {
+		t.Fatalf("unexpected expiry time: %v", u.Expires)
+	}
+
+	_, err = s.PresignReport(ctx, "dt=2019-11-01", "scan="+scanID, checkID+".json")
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrNotFound, err)
+	}
+}
+
+func TestPresignDefaultExpiry(t *testing.T) {
+	c := storage.Config{BucketReports: "reports", BucketVulnerableReports: "vulnerable-reports", LinkBase: "http://results/v1"}
+	svc := storagetest.NewFakeS3()
+	s := storage.NewS3Storage(c, logrus.NewEntry(logrus.New()), svc)
+
+	ctx := context.Background()
+	startedAt := time.Date(2019, time.November, 1, 10, 0, 0, 0, time.UTC)
+	scanID, checkID := "9126034c-7caf-4acd-93f3-bee1941aa140", "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0"
+	if _, err := s.SaveReports(ctx, scanID, checkID, startedAt, []byte("{}"), false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	u, err := s.PresignReport(ctx, "dt=2019-11-01", "scan="+scanID, checkID+".json")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	parsed, err := url.Parse(u.URL)
+	if err != nil {
+		t.Fatalf("expected a valid URL, got: %v", err)
+	}
+	if exp := parsed.Query().Get("X-Amz-Expires"); exp != "900" {
+		t.Fatalf("expected the URL to expire in 900 seconds, got: %s", exp)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// canaryDir is the prefix, or the directory, of the canary objects
+// written by the readiness checks.
+const canaryDir = ".readiness"
+
+// DependencyStatus is the status of a dependency of a storage, like a
+// bucket, as seen by a readiness check.
+type DependencyStatus struct {
+	// Name identifies the dependency.
+	Name string
+	// Canary tells whether a canary object was written, read and
+	// deleted.
+	Canary bool
+	// Err is why the dependency can not be accessed, nil if it can.
+	Err error
+}
+
+// ReadinessChecker is implemented by the storages that can check they
+// can access the buckets, or their equivalent, the results are stored
+// in.
+type ReadinessChecker interface {
+	// CheckReadiness returns the status of every bucket the storage
+	// uses. With canary, it also writes, reads back and deletes an
+	// object in every one of them.
+	CheckReadiness(ctx context.Context, canary bool) []DependencyStatus
+}
+
+// buckets returns the distinct buckets the results are stored in. The
+// reports and the vulnerable reports usually share a bucket.
+func (c Config) buckets() []string {
+	var buckets []string
+	seen := map[string]bool{}
+	for _, b := range []string{c.BucketReports, c.BucketVulnerableReports, c.BucketLogs} {
+		if !seen[b] {
+			seen[b] = true
+			buckets = append(buckets, b)
+		}
+	}
+	return buckets
+}
+
+// canaryObject returns a random key, and its content, for a canary
+// object, so concurrent checks do not interfere with each other.
+func canaryObject() (name string, content []byte, err error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	name = hex.EncodeToString(b)
+	return name, []byte("vulcan-results readiness canary " + name), nil
+}
+
+// CheckReadiness checks the buckets exist and can be accessed with the
+// credentials of the storage. The checks call S3 directly, bypassing the
+// retries and the circuit breaker, so they report the current state of
+// S3. The canary objects are encrypted as the rest of the objects of
+// their bucket.
+func (s *S3Storage) CheckReadiness(ctx context.Context, canary bool) []DependencyStatus {
+	var statuses []DependencyStatus
+	for _, bucket := range s.Conf.buckets() {
+		st := DependencyStatus{Name: "s3://" + bucket}
+		_, err := s.svc.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+		if err == nil && canary {
+			st.Canary = true
+			err = s.canary(ctx, bucket)
+		}
+		if err != nil {
+			st.Err = fmt.Errorf("bucket %s: %w", bucket, s3Error(err))
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// canary writes, reads back and deletes a canary object in a bucket.
+func (s *S3Storage) canary(ctx context.Context, bucket string) error {
+	name, content, err := canaryObject()
+	if err != nil {
+		return err
+	}
+	key := canaryDir + "/" + name
+
+	sse, keyID, bucketKey := s.Conf.encryption(bucket).s3Params()
+	_, err = s.svc.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(content),
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          keyID,
+		BucketKeyEnabled:     bucketKey,
+	})
+	if err != nil {
+		return fmt.Errorf("writing canary: %w", err)
+	}
+
+	readErr := s.readCanary(ctx, bucket, key, content)
+	_, err = s.svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if readErr != nil {
+		return readErr
+	}
+	if err != nil {
+		return fmt.Errorf("deleting canary: %w", err)
+	}
+	return nil
+}
+
+// readCanary checks a canary object has the given content.
+func (s *S3Storage) readCanary(ctx context.Context, bucket, key string, content []byte) error {
+	out, err := s.svc.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("reading canary: %w", err)
+	}
+	defer out.Body.Close()
+
+	read, err := ioutil.ReadAll(out.Body)
+	if err != nil {
+		return fmt.Errorf("reading canary: %w", err)
+	}
+	if !bytes.Equal(read, content) {
+		return errors.New("reading canary: content mismatch")
+	}
+	return nil
+}
+
+// CheckReadiness checks the directories of the buckets are
+// directories. They are created on the first write, so the root is
+// checked instead while they do not exist.
+func (s *FilesystemStorage) CheckReadiness(ctx context.Context, canary bool) []DependencyStatus {
+	var statuses []DependencyStatus
+	for _, bucket := range s.Conf.buckets() {
+		st := DependencyStatus{Name: "file://" + filepath.Join(s.Conf.Root, bucket)}
+		err := ctx.Err()
+		if err == nil {
+			err = s.checkDir(bucket)
+		}
+		if err == nil && canary {
+			st.Canary = true
+			err = s.canary(ctx, bucket)
+		}
+		st.Err = err
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// checkDir checks the directory of a bucket, or the root if it was not
+// created yet, is a directory.
+func (s *FilesystemStorage) checkDir(bucket string) error {
+	info, err := os.Stat(filepath.Join(s.Conf.Root, bucket))
+	if os.IsNotExist(err) {
+		info, err = os.Stat(s.Conf.Root)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", info.Name())
+	}
+	return nil
+}
+
+// canary writes, reads back and removes a canary file in the directory
+// of a bucket.
+func (s *FilesystemStorage) canary(ctx context.Context, bucket string) error {
+	name, content, err := canaryObject()
+	if err != nil {
+		return err
+	}
+	if err := s.writeFile(ctx, bucket, content, canaryDir, name); err != nil {
+		return fmt.Errorf("writing canary: %w", err)
+	}
+
+	p, err := s.path(bucket, canaryDir, name)
+	if err != nil {
+		return err
+	}
+	read, readErr := ioutil.ReadFile(p)
+	if readErr == nil && !bytes.Equal(read, content) {
+		readErr = errors.New("content mismatch")
+	}
+	err = s.removeFile(bucket, canaryDir, name)
+	if readErr != nil {
+		return fmt.Errorf("reading canary: %w", readErr)
+	}
+	if err != nil {
+		return fmt.Errorf("removing canary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+func TestS3Readiness(t *testing.T) {
+	c := storage.Config{
+		BucketReports:           "reports",
+		BucketVulnerableReports: "reports",
+		BucketLogs:              "logs",
+	}
+	svc := storagetest.NewFakeS3()
+	svc.AddBucket("reports")
+	s := storage.NewS3Storage(c, logrus.NewEntry(logrus.New()), svc)
+
+	statuses := s.CheckReadiness(context.Background(), true)
+	if len(statuses) != 2 {
+		t.Fatalf("expected the status of 2 buckets, got: %+v", statuses)
+	}
+	if st := statuses[0]; st.Name != "s3://reports" || st.Err != nil || !st.Canary {
+		t.Fatalf("expected the reports bucket to be ready, got: %+v", st)
+	}
+	if st := statuses[1]; st.Name != "s3://logs" || !errors.Is(st.Err, storage.ErrNotFound) || st.Canary {
+		t.Fatalf("expected the missing logs bucket not to be ready, got: %+v", st)
+	}
+
+	// The canary objects are deleted.
+	out, err := svc.ListObjectsV2WithContext(context.Background(), &s3.ListObjectsV2Input{
+		Bucket: aws.String("reports"),
+		Prefix: aws.String(".readiness/"),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(out.Contents) != 0 {
+		t.Fatalf("expected the canary objects to be deleted, got: %v", out.Contents)
+	}
+
+	svc.AddBucket("logs")
+	svc.Fault = func(op, bucket, key string) error {
+		if op == "PutObject" && bucket == "logs" {
+			return errors.New("access denied")
+		}
+		return nil
+	}
+	statuses = s.CheckReadiness(context.Background(), true)
+	if statuses[0].Err != nil {
+		t.Fatalf("expected the reports bucket to be ready, got: %v", statuses[0].Err)
+	}
+	if statuses[1].Err == nil {
+		t.Fatalf("expected the canary of the logs bucket to fail")
+	}
+	statuses = s.CheckReadiness(context.Background(), false)
+	if statuses[1].Err != nil || statuses[1].Canary {
+		t.Fatalf("expected the logs bucket to be ready without canary, got: %+v", statuses[1])
+	}
+}
+
+func TestFilesystemReadiness(t *testing.T) {
+	root := t.TempDir()
+	s, err := storage.NewFilesystemStorage(storage.Config{Root: root}, logrus.NewEntry(logrus.New()))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	statuses := s.CheckReadiness(context.Background(), true)
+	if len(statuses) != 3 {
+		t.Fatalf("expected the status of 3 directories, got: %+v", statuses)
+	}
+	for _, st := range statuses {
+		if st.Err != nil || !st.Canary {
+			t.Fatalf("expected %s to be ready, got: %v", st.Name, st.Err)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(root, "logs", ".readiness"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected the canary files to be removed, got: %v", entries)
+	}
+
+	// A file in place of a directory.
+	if err := os.RemoveAll(filepath.Join(root, "logs")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "logs"), nil, 0600); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	statuses = s.CheckReadiness(context.Background(), false)
+	if st := statuses[2]; st.Name != "file://"+filepath.Join(root, "logs") || st.Err == nil {
+		t.Fatalf("expected the logs directory not to be ready, got: %+v", st)
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// ReconcileOptions are the options of a reconciliation of the reports
+// and vulnerable reports buckets.
+type ReconcileOptions struct {
+	// Vulnerable tells whether a stored report is vulnerable.
+	Vulnerable VulnerableFunc
+	// DryRun makes the reconciliation only report the changes it would
+	// make.
+	DryRun bool
+}
+
+// ReconcileSummary summarizes a reconciliation of the reports and
+// vulnerable reports buckets.
+type ReconcileSummary struct {
+	// Reports is the number of reports checked.
+	Reports int
+	// Invalid is the number of reports that could not be checked
+	// because Vulnerable failed. They are left untouched.
+	Invalid int
+	// Created, Updated and Deleted are the number of vulnerable copies
+	// created, rewritten because they did not match the report, and
+	// deleted because the report is not vulnerable or does not exist.
+	Created int
+	Updated int
+	Deleted int
+}
+
+// Changes returns the number of vulnerable copies changed.
+func (s ReconcileSummary) Changes() int {
+	return s.Created + s.Updated + s.Deleted
+}
+
+// Reconcile walks the partitions of the reports and vulnerable reports
+// buckets for the days between from and to, both included, making the
+// vulnerable reports bucket hold a copy of every vulnerable report and
+// only of them.
+func (s *S3Storage) Reconcile(ctx context.Context, from, to time.Time, opts ReconcileOptions) (ReconcileSummary, error) {
+	var summary ReconcileSummary
+	if to.Before(from) {
+		return summary, fmt.Errorf("%w: %s is before %s", ErrInvalidRange, to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if err := s.reconcileDay(ctx, day, opts, &summary); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// reconcileDay reconciles the partition of the given day.
+func (s *S3Storage) reconcileDay(ctx context.Context, day time.Time, opts ReconcileOptions, summary *ReconcileSummary) error {
+	prefix := day.Format("dt=2006-01-02/")
+
+	var reports []string
+	err := s.listObjects(ctx, s.Conf.BucketReports, prefix, "", func(out *s3.ListObjectsV2Output) {
+		for _, obj := range out.Contents {
+			if key := aws.StringValue(obj.Key); strings.HasSuffix(key, ".json") {
+				reports = append(reports, key)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	copies := map[string]bool{}
+	err = s.listObjects(ctx, s.Conf.BucketVulnerableReports, prefix, "", func(out *s3.ListObjectsV2Output) {
+		for _, obj := range out.Contents {
+			if key := aws.StringValue(obj.Key); strings.HasSuffix(key, ".json.gz") {
+				copies[strings.TrimSuffix(key, ".gz")] = true
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range reports {
+		summary.Reports++
+		hasCopy := copies[key]
+		delete(copies, key)
+
+		report, md, err := s.readObject(ctx, s.Conf.BucketReports, key)
+		if err != nil {
+			return err
+		}
+		vulnerable, err := opts.Vulnerable(key, report, md)
+		if err != nil {
+			summary.Invalid++
+			contextLogger(ctx, s.logger).WithError(err).WithField("key", key).Warn("invalid report")
+			continue
+		}
+
+		switch {
+		case vulnerable && !hasCopy:
+			summary.Created++
+			err = s.reconcileCopy(ctx, "create", key, report, md, opts.DryRun)
+		case vulnerable:
+			var matches bool
+			matches, err = s.copyMatches(ctx, key, report, md)
+			if err != nil {
+				return err
+			}
+			if matches {
+				continue
+			}
+			summary.Updated++
+			err = s.reconcileCopy(ctx, "update", key, report, md, opts.DryRun)
+		case hasCopy:
+			summary.Deleted++
+			err = s.reconcileCopy(ctx, "delete", key, nil, nil, opts.DryRun)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// The remaining copies belong to reports that do not exist.
+	orphans := make([]string, 0, len(copies))
+	for key := range copies {
+		orphans = append(orphans, key)
+	}
+	sort.Strings(orphans)
+	for _, key := range orphans {
+		summary.Deleted++
+		if err := s.reconcileCopy(ctx, "delete", key, nil, nil, opts.DryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileCopy creates, updates or deletes the vulnerable copy of the
+// report with the given key and user metadata, unless dryRun is true.
+func (s *S3Storage) reconcileCopy(ctx context.Context, action, key string, report []byte, md map[string]string, dryRun bool) error {
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+		"action":  action,
+		"key":     key,
+		"dry_run": dryRun,
+	}).Info("reconciling vulnerable report")
+
+	if dryRun {
+		return nil
+	}
+	if action == "delete" {
+		return s.deleteObject(ctx, s.Conf.BucketVulnerableReports, key+".gz")
+	}
+	return s.uploadToBucket(ctx, s.Conf.BucketVulnerableReports, key+".gz", report, true, aws.String("gzip"), s3UserMetadata(md))
+}
+
+// copyMatches tells whether the vulnerable copy of the report with the
+// given key holds the report and its user metadata.
+func (s *S3Storage) copyMatches(ctx context.Context, key string, report []byte, md map[string]string) (bool, error) {
+	gz, gzmd, err := s.readObject(ctx, s.Conf.BucketVulnerableReports, key+".gz")
+	if err != nil {
+		return false, err
+	}
+	content, err := gunzipContent(gz)
+	if err != nil {
+		// A corrupt copy does not match.
+		return false, nil
+	}
+	return bytes.Equal(content, report) && maps.Equal(gzmd, md), nil
+}
+
+// readObject returns the content and the user metadata of an object
+// stored in S3.
+func (s *S3Storage) readObject(ctx context.Context, bucket, key string) ([]byte, map[string]string, error) {
+	obj, err := s.downloadFromBucket(ctx, bucket, key, GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer obj.Body.Close()
+
+	content, err := ioutil.ReadAll(obj.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, obj.Metadata, nil
+}
+
+func gunzipContent(content []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return ioutil.ReadAll(zr)
+}
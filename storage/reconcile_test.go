@@ -0,0 +1,205 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+var reconcileDay = time.Date(1984, time.April, 4, 0, 0, 0, 0, time.UTC)
+
+// reconcileKey returns the key of the report of the given check in the
+// reconciled day.
+func reconcileKey(checkID string) string {
+	return "dt=1984-04-04/scan=" + repairScanID + "/" + checkID + ".json"
+}
+
+// vulnerable tells whether a test report is vulnerable. Reports are
+// invalid unless they start with "vulnerable" or "clean".
+func vulnerable(_ string, report []byte, _ map[string]string) (bool, error) {
+	switch {
+	case bytes.HasPrefix(report, []byte("vulnerable")):
+		return true, nil
+	case bytes.HasPrefix(report, []byte("clean")):
+		return false, nil
+	}
+	return false, errors.New("invalid report")
+}
+
+func putObject(t *testing.T, svc *storagetest.FakeS3, bucket, key string, content []byte) {
+	t.Helper()
+
+	if _, err := svc.PutObjectWithContext(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(content),
+	}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func gzipContent(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// newDriftedStorage returns a storage whose buckets disagree in every
+// possible way.
+func newDriftedStorage(t *testing.T) (*storage.S3Storage, *storagetest.FakeS3) {
+	t.Helper()
+
+	svc := storagetest.NewFakeS3()
+	// A vulnerable report without copy.
+	putObject(t, svc, "reports", reconcileKey("missing"), []byte("vulnerable missing"))
+	// A vulnerable report with a stale copy.
+	putObject(t, svc, "reports", reconcileKey("stale"), []byte("vulnerable new"))
+	putObject(t, svc, "vulnerable-reports", reconcileKey("stale")+".gz", gzipContent(t, "vulnerable old"))
+	// A vulnerable report with an up to date copy.
+	putObject(t, svc, "reports", reconcileKey("ok"), []byte("vulnerable ok"))
+	putObject(t, svc, "vulnerable-reports", reconcileKey("ok")+".gz", gzipContent(t, "vulnerable ok"))
+	// A non vulnerable report with a copy.
+	putObject(t, svc, "reports", reconcileKey("clean"), []byte("clean"))
+	putObject(t, svc, "vulnerable-reports", reconcileKey("clean")+".gz", gzipContent(t, "vulnerable clean"))
+	// A copy of a report that does not exist.
+	putObject(t, svc, "vulnerable-reports", reconcileKey("orphan")+".gz", gzipContent(t, "vulnerable orphan"))
+	// An invalid report with a copy.
+	putObject(t, svc, "reports", reconcileKey("invalid"), []byte("{"))
+	putObject(t, svc, "vulnerable-reports", reconcileKey("invalid")+".gz", gzipContent(t, "{"))
+	// A report of a day not reconciled.
+	putObject(t, svc, "reports", strings.Replace(reconcileKey("missing"), "04-04", "04-05", 1), []byte("vulnerable"))
+
+	s := storage.NewS3Storage(repairConfig, logrus.New().WithFields(logrus.Fields{"test": t.Name()}), svc)
+	return s, svc
+}
+
+func TestReconcile(t *testing.T) {
+	s, svc := newDriftedStorage(t)
+
+	summary, err := s.Reconcile(context.Background(), reconcileDay, reconcileDay, storage.ReconcileOptions{Vulnerable: vulnerable})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	expected := storage.ReconcileSummary{Reports: 5, Invalid: 1, Created: 1, Updated: 1, Deleted: 2}
+	if summary != expected {
+		t.Fatalf("expected summary %+v, got: %+v", expected, summary)
+	}
+
+	copies := map[string]string{
+		"missing": "vulnerable missing",
+		"stale":   "vulnerable new",
+		"ok":      "vulnerable ok",
+		"invalid": "{",
+	}
+	for check, content := range copies {
+		obj, ok := svc.Object("vulnerable-reports", reconcileKey(check)+".gz")
+		if !ok {
+			t.Fatalf("expected the copy of %s to exist", check)
+		}
+		if got := gunzip(t, obj.Body); got != content {
+			t.Fatalf("unexpected copy of %s: %q", check, got)
+		}
+	}
+	checkExists(t, svc, "vulnerable-reports", reconcileKey("clean")+".gz", false)
+	checkExists(t, svc, "vulnerable-reports", reconcileKey("orphan")+".gz", false)
+	checkExists(t, svc, "vulnerable-reports", strings.Replace(reconcileKey("missing"), "04-04", "04-05", 1)+".gz", false)
+
+	// Reconciling again changes nothing.
+	summary, err = s.Reconcile(context.Background(), reconcileDay, reconcileDay, storage.ReconcileOptions{Vulnerable: vulnerable})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if summary.Changes() != 0 {
+		t.Fatalf("expected no changes, got: %+v", summary)
+	}
+}
+
+func TestReconcileMetadata(t *testing.T) {
+	svc := storagetest.NewFakeS3()
+	s := storage.NewS3Storage(repairConfig, logrus.New().WithFields(logrus.Fields{"test": t.Name()}), svc)
+	// A copy with the same content as the report, but stored with the
+	// envelope the report had before its data key was rewrapped.
+	md := map[string]string{"Envelope-Key-Id": "new"}
+	putObjectWithMetadata(t, svc, "reports", reconcileKey("rewrapped"), []byte("vulnerable"), md)
+	putObjectWithMetadata(t, svc, "vulnerable-reports", reconcileKey("rewrapped")+".gz", gzipContent(t, "vulnerable"),
+		map[string]string{"Envelope-Key-Id": "old"})
+
+	var got map[string]string
+	opts := storage.ReconcileOptions{
+		Vulnerable: func(key string, report []byte, md map[string]string) (bool, error) {
+			got = md
+			return vulnerable(key, report, md)
+		},
+	}
+	summary, err := s.Reconcile(context.Background(), reconcileDay, reconcileDay, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if summary.Updated != 1 {
+		t.Fatalf("expected the copy to be updated, got: %+v", summary)
+	}
+	if !maps.Equal(got, md) {
+		t.Fatalf("expected the metadata of the report %v, got: %v", md, got)
+	}
+	obj, _ := svc.Object("vulnerable-reports", reconcileKey("rewrapped")+".gz")
+	if v := aws.StringValue(obj.Metadata["Envelope-Key-Id"]); v != "new" {
+		t.Fatalf("expected the copy to have the metadata of the report, got: %q", v)
+	}
+}
+
+func TestReconcileDryRun(t *testing.T) {
+	s, svc := newDriftedStorage(t)
+	svc.Fault = func(op, bucket, key string) error {
+		if op == "PutObject" || op == "DeleteObject" {
+			t.Errorf("unexpected %s of %s/%s", op, bucket, key)
+		}
+		return nil
+	}
+
+	summary, err := s.Reconcile(context.Background(), reconcileDay, reconcileDay, storage.ReconcileOptions{
+		Vulnerable: vulnerable,
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if summary.Changes() != 4 {
+		t.Fatalf("expected 4 changes, got: %+v", summary)
+	}
+	checkExists(t, svc, "vulnerable-reports", reconcileKey("missing")+".gz", false)
+	checkExists(t, svc, "vulnerable-reports", reconcileKey("clean")+".gz", true)
+	checkExists(t, svc, "vulnerable-reports", reconcileKey("orphan")+".gz", true)
+}
+
+func TestReconcileInvalidRange(t *testing.T) {
+	s, _ := newDriftedStorage(t)
+
+	_, err := s.Reconcile(context.Background(), reconcileDay, reconcileDay.AddDate(0, 0, -1), storage.ReconcileOptions{Vulnerable: vulnerable})
+	if !errors.Is(err, storage.ErrInvalidRange) {
+		t.Fatalf("expected ErrInvalidRange, got: %v", err)
+	}
+}
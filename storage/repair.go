@@ -0,0 +1,195 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// repairPrefix is the prefix of the keys of the repair markers, stored
+// in the reports bucket outside the date partitions, and ignored by the
+// query engines reading the bucket as it starts with an underscore. The
+// rest of the key of a marker is the key of the report it refers to.
+const repairPrefix = "_repairs/"
+
+// repairDir is the directory of the repair markers of the filesystem
+// backend, in the reports directory.
+var repairDir = strings.TrimSuffix(repairPrefix, "/")
+
+// Defaults of the repair of the vulnerable reports left unfinished.
+const (
+	// DefaultRepairInterval is the interval the service repairs the
+	// vulnerable reports at unless another one is configured.
+	DefaultRepairInterval = 10 * time.Minute
+	// DefaultRepairMinAge is the minimum age of the repair markers
+	// completed, so writes still in progress are not touched.
+	DefaultRepairMinAge = time.Hour
+)
+
+// VulnerableFunc tells whether a report, stored under the given key of
+// the reports bucket with the given user metadata, is vulnerable.
+type VulnerableFunc func(key string, report []byte, metadata map[string]string) (bool, error)
+
+// Repairer is implemented by the storages that complete the writes of
+// the vulnerable reports left unfinished.
+type Repairer interface {
+	// RepairReports makes the vulnerable copies of the reports with
+	// repair markers modified at least minAge ago agree with the
+	// reports, as told by vulnerable, and returns the number of reports
+	// repaired.
+	RepairReports(ctx context.Context, minAge time.Duration, vulnerable VulnerableFunc) (int, error)
+}
+
+// WatchRepairs repairs the vulnerable reports left unfinished every
+// interval, until ctx is done. If interval is not positive
+// DefaultRepairInterval is used.
+func WatchRepairs(ctx context.Context, r Repairer, vulnerable VulnerableFunc, interval time.Duration, logger *logrus.Entry) {
+	if interval <= 0 {
+		interval = DefaultRepairInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		n, err := r.RepairReports(ctx, DefaultRepairMinAge, vulnerable)
+		if err != nil {
+			logger.WithError(err).Error("vulnerable reports not repaired")
+			continue
+		}
+		if n > 0 {
+			logger.WithField("reports", n).Info("vulnerable reports repaired")
+		}
+	}
+}
+
+// saveVulnerableReport stores a vulnerable report both in the vulnerable
+// reports bucket, gzipped, and in the reports bucket with the given
+// metadata, so both buckets agree even if one of the writes fails. The
+// gzipped copy is stored only with the user metadata.
+//
+// A repair marker is stored before the writes and removed once both
+// succeed. If any of them fails, the copy in the vulnerable reports
+// bucket is rolled back, and the marker is kept so RepairReports can
+// later make that bucket agree with the reports bucket even if the
+// rollback failed or the failed write was actually performed.
+func (s *S3Storage) saveVulnerableReport(ctx context.Context, key string, report []byte, meta reportMeta) error {
+	marker := repairPrefix + key
+	if err := s.uploadToBucket(ctx, s.Conf.BucketReports, marker, nil, false, nil, nil); err != nil {
+		return err
+	}
+
+	err := s.uploadToBucket(ctx, s.Conf.BucketVulnerableReports, key+".gz", report, true, aws.String("gzip"), s3UserMetadata(meta.Metadata))
+	if err == nil {
+		err = s.uploadToBucket(ctx, s.Conf.BucketReports, key, report, false, aws.String("text/json"), meta.s3Metadata())
+	}
+
+	// Finish the transaction even if the request was canceled.
+	ctx = context.WithoutCancel(ctx)
+	if err != nil {
+		if rerr := s.deleteObject(ctx, s.Conf.BucketVulnerableReports, key+".gz"); rerr != nil {
+			contextLogger(ctx, s.logger).WithError(rerr).WithField("key", key).
+				Error("rolling back vulnerable report, left for repair")
+		}
+		return err
+	}
+
+	if err := s.deleteObject(ctx, s.Conf.BucketReports, marker); err != nil {
+		// The report is stored, the marker will be removed on repair.
+		contextLogger(ctx, s.logger).WithError(err).WithField("key", key).
+			Warn("removing repair marker")
+	}
+	return nil
+}
+
+// RepairReports completes the writes of the vulnerable reports left
+// unfinished, making the vulnerable reports bucket agree with the
+// reports bucket: the gzipped copy of the report is rewritten if the
+// stored report is vulnerable, and removed if it is not or it does not
+// exist. The stored report is not necessarily the one of the unfinished
+// write, so vulnerable decides. The copies of the reports vulnerable
+// can not tell about are left untouched.
+//
+// Markers modified less than minAge ago are skipped, as the writes they
+// refer to may still be in progress. It returns the number of reports
+// repaired.
+func (s *S3Storage) RepairReports(ctx context.Context, minAge time.Duration, vulnerable VulnerableFunc) (int, error) {
+	var markers []string
+	now := time.Now()
+	err := s.listObjects(ctx, s.Conf.BucketReports, repairPrefix, "", func(out *s3.ListObjectsV2Output) {
+		for _, obj := range out.Contents {
+			if now.Sub(aws.TimeValue(obj.LastModified)) < minAge {
+				continue
+			}
+			markers = append(markers, aws.StringValue(obj.Key))
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, marker := range markers {
+		key := strings.TrimPrefix(marker, repairPrefix)
+		if err := s.repairReport(ctx, key, vulnerable); err != nil {
+			return n, err
+		}
+		if err := s.deleteObject(ctx, s.Conf.BucketReports, marker); err != nil {
+			return n, err
+		}
+		contextLogger(ctx, s.logger).WithField("key", key).Info("vulnerable report repaired")
+		n++
+	}
+	return n, nil
+}
+
+// repairReport makes the gzipped copy of a report agree with the
+// report.
+func (s *S3Storage) repairReport(ctx context.Context, key string, vulnerable VulnerableFunc) error {
+	report, md, err := s.readObject(ctx, s.Conf.BucketReports, key)
+	if errors.Is(err, ErrNotFound) {
+		return s.deleteObject(ctx, s.Conf.BucketVulnerableReports, key+".gz")
+	}
+	if err != nil {
+		return err
+	}
+	vuln, err := vulnerable(key, report, md)
+	if err != nil {
+		contextLogger(ctx, s.logger).WithError(err).WithField("key", key).Warn("invalid report, vulnerable copy left untouched")
+		return nil
+	}
+	if !vuln {
+		return s.deleteObject(ctx, s.Conf.BucketVulnerableReports, key+".gz")
+	}
+	return s.uploadToBucket(ctx, s.Conf.BucketVulnerableReports, key+".gz", report, true, aws.String("gzip"), s3UserMetadata(md))
+}
+
+// deleteObject deletes an object from S3. Deleting an object that does
+// not exist succeeds.
+func (s *S3Storage) deleteObject(ctx context.Context, bucket, key string) error {
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+		"key":    key,
+		"bucket": bucket,
+	}).Debug("deleting content from S3 bucket")
+
+	return s.do(ctx, "DeleteObject", bucket, func(ctx context.Context) error {
+		_, err := s.svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		return err
+	})
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/adevinta/vulcan-results/storage"
+	"github.com/adevinta/vulcan-results/storage/storagetest"
+)
+
+const (
+	repairScanID  = "9126034c-7caf-4acd-93f3-bee1941aa140"
+	repairCheckID = "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0"
+	repairKey     = "dt=1984-04-04/scan=" + repairScanID + "/" + repairCheckID + ".json"
+	repairMarker  = "_repairs/" + repairKey
+)
+
+var repairConfig = storage.Config{
+	BucketVulnerableReports: "vulnerable-reports",
+	BucketReports:           "reports",
+	BucketLogs:              "logs",
+	LinkBase:                "http://results/v1",
+}
+
+var errAccessDenied = awserr.New("AccessDenied", "Access Denied", nil)
+
+// failOn returns a FakeS3 fault that fails the given operations on an
+// object.
+func failOn(bucket, key string, ops ...string) func(op, bucket, key string) error {
+	failingBucket, failingKey := bucket, key
+	return func(op, bucket, key string) error {
+		for _, o := range ops {
+			if o == op && bucket == failingBucket && key == failingKey {
+				return errAccessDenied
+			}
+		}
+		return nil
+	}
+}
+
+func saveVulnerableReport(s *storage.S3Storage, report string) error {
+	startedAt := time.Date(1984, time.April, 4, 13, 0, 0, 0, time.UTC)
+	_, err := s.SaveReports(context.Background(), repairScanID, repairCheckID, startedAt, []byte(report), true, storage.SaveOptions{})
+	return err
+}
+
+func gunzip(t *testing.T, content []byte) string {
+	t.Helper()
+
+	zr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	got, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return string(got)
+}
+
+func checkExists(t *testing.T, svc *storagetest.FakeS3, bucket, key string, exists bool) {
+	t.Helper()
+
+	if _, ok := svc.Object(bucket, key); ok != exists {
+		t.Fatalf("expected %s/%s to exist to be %v", bucket, key, exists)
+	}
+}
+
+func TestSaveVulnerableReport(t *testing.T) {
+	svc := storagetest.NewFakeS3()
+	s := storage.NewS3Storage(repairConfig, logrus.New().WithFields(logrus.Fields{"test": t.Name()}), svc)
+
+	if err := saveVulnerableReport(s, "report"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkExists(t, svc, "reports", repairKey, true)
+	checkExists(t, svc, "vulnerable-reports", repairKey+".gz", true)
+	checkExists(t, svc, "reports", repairMarker, false)
+}
+
+func TestSaveVulnerableReportRollback(t *testing.T) {
+	svc := storagetest.NewFakeS3()
+	s := storage.NewS3Storage(repairConfig, logrus.New().WithFields(logrus.Fields{"test": t.Name()}), svc)
+
+	svc.Fault = failOn("reports", repairKey, "PutObject")
+	if err := saveVulnerableReport(s, "report"); !errors.Is(err, errAccessDenied) {
+		t.Fatalf("expected the write error, got: %v", err)
+	}
+	checkExists(t, svc, "reports", repairKey, false)
+	checkExists(t, svc, "vulnerable-reports", repairKey+".gz", false)
+	checkExists(t, svc, "reports", repairMarker, true)
+
+	svc.Fault = nil
+	n, err := s.RepairReports(context.Background(), 0, vulnerable)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 report repaired, got: %d", n)
+	}
+	checkExists(t, svc, "vulnerable-reports", repairKey+".gz", false)
+	checkExists(t, svc, "reports", repairMarker, false)
+}
+
+func TestRepairReports(t *testing.T) {
+	tests := []struct {
+		name string
+		// stored is the report stored before the unfinished write.
+		stored string
+		// want is the content of the vulnerable copy after the repair,
+		// empty if there must be none.
+		want string
+	}{
+		{name: "Vulnerable", stored: "vulnerable old report", want: "vulnerable old report"},
+		{name: "NotVulnerable", stored: "clean old report"},
+		{name: "Invalid", stored: "invalid old report", want: "vulnerable new report"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := storagetest.NewFakeS3()
+			s := storage.NewS3Storage(repairConfig, logrus.New().WithFields(logrus.Fields{"test": t.Name()}), svc)
+
+			// Neither the report can be written nor the vulnerable copy
+			// rolled back.
+			svc.Fault = func(op, bucket, key string) error {
+				if op == "PutObject" && bucket == "reports" && key == repairKey || op == "DeleteObject" {
+					return errAccessDenied
+				}
+				return nil
+			}
+			if err := saveVulnerableReport(s, "vulnerable new report"); err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+			checkExists(t, svc, "vulnerable-reports", repairKey+".gz", true)
+			checkExists(t, svc, "reports", repairMarker, true)
+
+			// The report stored is the one that was stored before.
+			svc.Fault = nil
+			if _, err := svc.PutObjectWithContext(context.Background(), &s3.PutObjectInput{
+				Bucket: aws.String("reports"),
+				Key:    aws.String(repairKey),
+				Body:   bytes.NewReader([]byte(tt.stored)),
+			}); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			// Recent markers are skipped.
+			if n, err := s.RepairReports(context.Background(), time.Hour, vulnerable); err != nil || n != 0 {
+				t.Fatalf("expected no reports repaired, got: %d, %v", n, err)
+			}
+
+			n, err := s.RepairReports(context.Background(), 0, vulnerable)
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if n != 1 {
+				t.Fatalf("expected 1 report repaired, got: %d", n)
+			}
+			checkExists(t, svc, "reports", repairMarker, false)
+			obj, ok := svc.Object("vulnerable-reports", repairKey+".gz")
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("expected the vulnerable copy to be deleted")
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected a vulnerable copy")
+			}
+			if got := gunzip(t, obj.Body); got != tt.want {
+				t.Fatalf("expected the vulnerable copy to be %q, got: %q", tt.want, got)
+			}
+		})
+	}
+}
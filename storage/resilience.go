@@ -0,0 +1,341 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default values of the ResilienceConfig fields.
+const (
+	DefaultRetryBaseDelay  = 100 * time.Millisecond
+	DefaultRetryMaxDelay   = 5 * time.Second
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling the storage backend while
+// the circuit breaker around it is open. It wraps ErrUnavailable.
+var ErrCircuitOpen = fmt.Errorf("%w: circuit breaker open", ErrUnavailable)
+
+// ResilienceConfig configures how the S3 backend copes with S3 being
+// unavailable. The zero value disables retries, timeouts and the circuit
+// breaker.
+type ResilienceConfig struct {
+	// MaxRetries is the number of times a call to S3 is retried when S3
+	// is unavailable.
+	MaxRetries int
+	// RetryBaseDelay and RetryMaxDelay bound the jittered exponential
+	// backoff between retries.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// CallTimeout, if not zero, is the maximum time to wait for S3 to
+	// answer a call. Calls that time out are considered failures of S3.
+	CallTimeout time.Duration
+	// BreakerThreshold, if not zero, is the number of consecutive
+	// failed calls that open the circuit breaker. While it is open
+	// calls fail fast with ErrCircuitOpen.
+	BreakerThreshold int
+	// BreakerCooldown is the time the circuit breaker stays open before
+	// letting a call through to check whether S3 recovered.
+	BreakerCooldown time.Duration
+}
+
+// Health is the health of the backend of a storage.
+type Health struct {
+	// CircuitBreaker is the state of the circuit breaker around the
+	// backend: "closed", "open" or "half-open".
+	CircuitBreaker string `json:"circuit_breaker"`
+	// ConsecutiveFailures is the number of consecutive failed calls to
+	// the backend.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+}
+
+// HealthReporter is implemented by the storages that track the health
+// of their backend.
+type HealthReporter interface {
+	Health() Health
+}
+
+// breakerState is the state of a circuit breaker.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker is a circuit breaker. It opens after threshold consecutive
+// failures and, once the cooldown elapses, half-opens letting a single
+// call through: the breaker closes if it succeeds and opens again if it
+// fails. A breaker with a zero threshold, or a nil breaker, never
+// opens.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	// onChange is called, holding the lock, when the state changes.
+	onChange func(from, to breakerState)
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow returns ErrCircuitOpen if a call must not be made. Otherwise
+// the outcome of the call must be reported calling record.
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.setState(breakerHalfOpen)
+		b.probing = true
+	case breakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record reports the outcome of a call made with ctx to the backend,
+// given the error it returned classified with s3Error. Errors other than
+// ErrUnavailable mean the backend answered, so they are successes for
+// the breaker, and calls aborted because ctx is done tell nothing about
+// the backend.
+func (b *breaker) record(ctx context.Context, err error) {
+	switch {
+	case err == nil:
+		b.success()
+	case ctx.Err() != nil:
+		b.abort()
+	case errors.Is(err, ErrUnavailable):
+		b.failure()
+	default:
+		b.success()
+	}
+}
+
+// success reports a call to the backend succeeded.
+func (b *breaker) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.setState(breakerClosed)
+}
+
+// failure reports a call to the backend failed.
+func (b *breaker) failure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == breakerHalfOpen || b.threshold > 0 && b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(breakerOpen)
+	}
+}
+
+// abort reports a call was aborted before knowing whether the backend
+// is healthy.
+func (b *breaker) abort() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// health returns the state of the breaker.
+func (b *breaker) health() Health {
+	if b == nil {
+		return Health{CircuitBreaker: breakerClosed.String()}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return Health{CircuitBreaker: b.state.String(), ConsecutiveFailures: b.failures}
+}
+
+func (b *breaker) setState(to breakerState) {
+	if b.state == to {
+		return
+	}
+	from := b.state
+	b.state = to
+	if b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
+
+// newBreaker returns the circuit breaker for the given config, logging
+// its state transitions to l.
+func newBreaker(c ResilienceConfig, l *logrus.Entry) *breaker {
+	cooldown := c.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &breaker{
+		threshold: c.BreakerThreshold,
+		cooldown:  cooldown,
+		onChange: func(from, to breakerState) {
+			l.WithFields(logrus.Fields{
+				"from": from.String(),
+				"to":   to.String(),
+			}).Warn("S3 circuit breaker state changed")
+		},
+	}
+}
+
+// Health returns the health of S3 as seen by the storage.
+func (s *S3Storage) Health() Health {
+	return s.breaker.health()
+}
+
+// do runs an S3 call as described in call, releasing its context as
+// soon as it returns.
+func (s *S3Storage) do(ctx context.Context, op, bucket string, f func(context.Context) error) error {
+	release, err := s.call(ctx, op, bucket, f)
+	release()
+	return err
+}
+
+// call runs an S3 call made by f, retrying it with jittered exponential
+// backoff while S3 is unavailable and failing fast while the circuit
+// breaker is open. The error returned by f is classified with s3Error.
+//
+// Every attempt is bounded by the configured call timeout, but the
+// context of the successful attempt is kept alive until the returned
+// release func is called, so responses can be streamed after call
+// returns.
+//
+// The duration and the error of the call, including its retries, are
+// pushed to the metrics client of the storage, tagged with op and the
+// bucket the call is made to.
+func (s *S3Storage) call(ctx context.Context, op, bucket string, f func(context.Context) error) (release func(), err error) {
+	start := time.Now()
+	defer func() { s.observeCall(op, bucket, time.Since(start), err) }()
+
+	c := s.Conf.Resilience
+	for attempt := 0; ; attempt++ {
+		release, err = s.attempt(ctx, op, f)
+		if err == nil || !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) || attempt >= c.MaxRetries {
+			return release, err
+		}
+
+		delay := retryDelay(c, attempt)
+		contextLogger(ctx, s.logger).WithError(err).WithFields(logrus.Fields{
+			"op":      op,
+			"attempt": attempt + 1,
+			"delay":   delay,
+		}).Debug("retrying S3 call")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return release, err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt runs a single S3 call made by f.
+func (s *S3Storage) attempt(ctx context.Context, op string, f func(context.Context) error) (release func(), err error) {
+	if err := s.breaker.allow(); err != nil {
+		return func() {}, err
+	}
+
+	actx, cancel := context.WithCancel(ctx)
+	var timedOut atomic.Bool
+	timeout := s.Conf.Resilience.CallTimeout
+	var timer *time.Timer
+	if timeout > 0 {
+		timer = time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+	}
+
+	err = f(actx)
+	if timer != nil {
+		timer.Stop()
+	}
+	if err == nil {
+		s.breaker.record(ctx, nil)
+		return cancel, nil
+	}
+	cancel()
+
+	if timedOut.Load() && ctx.Err() == nil {
+		err = fmt.Errorf("%w: %s timed out after %s: %w", ErrUnavailable, op, timeout, err)
+	} else {
+		err = s3Error(err)
+	}
+	s.breaker.record(ctx, err)
+	return func() {}, err
+}
+
+// retryDelay returns the time to wait before retrying the call that
+// failed in the given attempt, starting from 0. Half of the delay is
+// random so clients retrying at the same time spread their calls.
+func retryDelay(c ResilienceConfig, attempt int) time.Duration {
+	base, max := c.RetryBaseDelay, c.RetryMaxDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	if max <= 0 {
+		max = DefaultRetryMaxDelay
+	}
+
+	d := base
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/sirupsen/logrus"
+)
+
+// flakyS3Client is a S3 client whose calls fail with the configured
+// error the configured number of times before succeeding.
+type flakyS3Client struct {
+	s3iface.S3API
+
+	mu       sync.Mutex
+	failures int
+	err      error
+	calls    int
+	// hang makes GetObject block until the context of the call is done.
+	hang bool
+}
+
+func (m *flakyS3Client) fail() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	if m.failures > 0 {
+		m.failures--
+		return m.err
+	}
+	return nil
+}
+
+func (m *flakyS3Client) setFailures(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failures = n
+}
+
+func (m *flakyS3Client) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls
+}
+
+func (m *flakyS3Client) PutObjectWithContext(ctx aws.Context, in *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	if err := m.fail(); err != nil {
+		return nil, err
+	}
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *flakyS3Client) GetObjectWithContext(ctx aws.Context, in *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
+	if m.hang {
+		<-ctx.Done()
+		return nil, awserr.New(request.CanceledErrorCode, "request context canceled", ctx.Err())
+	}
+	if err := m.fail(); err != nil {
+		return nil, err
+	}
+	return &s3.GetObjectOutput{
+		Body:          ctxReader{ctx: ctx, r: strings.NewReader("content")},
+		ContentLength: aws.Int64(7),
+	}, nil
+}
+
+// ctxReader is a body that fails to read once the context of the call
+// that returned it is done, as the bodies returned by the SDK do.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+func (c ctxReader) Close() error {
+	return nil
+}
+
+var errSlowDown = awserr.New("SlowDown", "Please reduce your request rate.", nil)
+
+func newResilientStorage(c ResilienceConfig, svc s3iface.S3API) *S3Storage {
+	conf := baseConfig
+	conf.Resilience = c
+	return NewS3Storage(conf, logrus.NewEntry(logrus.New()), svc)
+}
+
+func TestRetry(t *testing.T) {
+	testCases := []struct {
+		name     string
+		failures int
+		err      error
+		ok       bool
+		calls    int
+	}{
+		{
+			name:     "recovers",
+			failures: 2,
+			err:      errSlowDown,
+			ok:       true,
+			calls:    3,
+		},
+		{
+			name:     "retries exhausted",
+			failures: 5,
+			err:      errSlowDown,
+			calls:    3,
+		},
+		{
+			name:     "not retried",
+			failures: 1,
+			err:      awserr.New("AccessDenied", "Access Denied", nil),
+			calls:    1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &flakyS3Client{failures: tc.failures, err: tc.err}
+			s := newResilientStorage(ResilienceConfig{MaxRetries: 2, RetryBaseDelay: time.Millisecond}, svc)
+
+			_, err := s.SaveLogs(context.Background(), "scan", "check", time.Now(), []byte("log"))
+			if (err == nil) != tc.ok {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if calls := svc.callCount(); calls != tc.calls {
+				t.Fatalf("expected %d calls, got: %d", tc.calls, calls)
+			}
+		})
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	svc := &flakyS3Client{hang: true}
+	s := newResilientStorage(ResilienceConfig{CallTimeout: 10 * time.Millisecond}, svc)
+
+	_, err := s.GetLog(context.Background(), "dt=2019-11-01", "scan", "check.log", GetOptions{})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("expected ErrUnavailable, got: %v", err)
+	}
+	if h := s.Health(); h.ConsecutiveFailures != 1 {
+		t.Fatalf("expected the timeout to count as a failure, got: %+v", h)
+	}
+}
+
+func TestCallTimeoutStreaming(t *testing.T) {
+	svc := &flakyS3Client{}
+	s := newResilientStorage(ResilienceConfig{CallTimeout: 10 * time.Millisecond}, svc)
+
+	obj, err := s.GetLog(context.Background(), "dt=2019-11-01", "scan", "check.log", GetOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer obj.Body.Close()
+
+	// The timeout only bounds the call, not reading the body.
+	time.Sleep(20 * time.Millisecond)
+	content, err := ioutil.ReadAll(obj.Body)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if string(content) != "content" {
+		t.Fatalf("unexpected content: %q", content)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	svc := &flakyS3Client{failures: 2, err: errSlowDown}
+	s := newResilientStorage(ResilienceConfig{BreakerThreshold: 2, BreakerCooldown: 20 * time.Millisecond}, svc)
+
+	save := func() error {
+		_, err := s.SaveLogs(context.Background(), "scan", "check", time.Now(), []byte("log"))
+		return err
+	}
+	checkState := func(state string) {
+		t.Helper()
+		if h := s.Health(); h.CircuitBreaker != state {
+			t.Fatalf("expected the breaker to be %s, got: %+v", state, h)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := save(); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("expected ErrUnavailable, got: %v", err)
+		}
+	}
+	checkState("open")
+
+	if err := save(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got: %v", err)
+	}
+	if calls := svc.callCount(); calls != 2 {
+		t.Fatalf("expected the open breaker to fail fast, got %d calls", calls)
+	}
+
+	// A failed probe opens the breaker again.
+	time.Sleep(30 * time.Millisecond)
+	svc.setFailures(1)
+	if err := save(); !errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the probe to fail, got: %v", err)
+	}
+	checkState("open")
+
+	// A successful probe closes it.
+	time.Sleep(30 * time.Millisecond)
+	if err := save(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkState("closed")
+	if h := s.Health(); h.ConsecutiveFailures != 0 {
+		t.Fatalf("expected the failures to be reset, got: %+v", h)
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := &breaker{threshold: 1, cooldown: time.Millisecond}
+	b.failure()
+	time.Sleep(2 * time.Millisecond)
+
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected the probe to be allowed, got: %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected a single probe while half-open, got: %v", err)
+	}
+	b.abort()
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected a new probe after an aborted one, got: %v", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	c := ResilienceConfig{RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: time.Second}
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		d := retryDelay(c, attempt)
+		if d < max/2 || d > max {
+			t.Fatalf("expected delay of attempt %d in [%v, %v], got: %v", attempt, max/2, max, d)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/sirupsen/logrus"
+)
+
+// Keys of the S3 metadata stored with the reports.
+const (
+	metaChecksum = "Sha256"
+	metaEndTime  = "End-Time"
+)
+
+// SaveOptions are the options of a report write. They make writing the
+// same report several times idempotent, and keep late writes from
+// overwriting newer reports of the same check.
+type SaveOptions struct {
+	// Checksum is the hex encoded SHA-256 of the report. If not empty it
+	// is stored with the report, and the write is skipped when the
+	// stored report has the same checksum.
+	Checksum string
+	// EndTime is the time the check finished. If not zero it is stored
+	// with the report, and the write fails with ErrConflict when the
+	// stored report finished later.
+	EndTime time.Time
+	// Force stores the report regardless of the stored one.
+	Force bool
+}
+
+// conditional tells whether the write depends on the stored report.
+func (o SaveOptions) conditional() bool {
+	return o.Checksum != "" || !o.EndTime.IsZero()
+}
+
+// reportMeta is the metadata stored with a report. Metadata is the rest
+// of the user metadata of the object.
+type reportMeta struct {
+	Checksum string            `json:"checksum,omitempty"`
+	EndTime  time.Time         `json:"end_time"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// meta returns the metadata to store with a report written with the
+// options.
+func (o SaveOptions) meta() reportMeta {
+	return reportMeta{Checksum: o.Checksum, EndTime: o.EndTime}
+}
+
+// checkSave tells whether a write with the given options can be skipped
+// because the stored report, with the given metadata, is the same. It
+// fails with ErrConflict if the stored report is newer.
+func checkSave(opts SaveOptions, stored reportMeta) (skip bool, err error) {
+	if opts.Force {
+		return false, nil
+	}
+	if opts.Checksum != "" && opts.Checksum == stored.Checksum {
+		return true, nil
+	}
+	if !opts.EndTime.IsZero() && opts.EndTime.Before(stored.EndTime) {
+		return false, fmt.Errorf("%w: the stored report ended at %s, after %s", ErrConflict,
+			stored.EndTime.Format(time.RFC3339), opts.EndTime.Format(time.RFC3339))
+	}
+	return false, nil
+}
+
+// s3Metadata returns the S3 metadata that stores m.
+func (m reportMeta) s3Metadata() map[string]*string {
+	md := map[string]*string{}
+	for k, v := range m.Metadata {
+		md[k] = aws.String(v)
+	}
+	if m.Checksum != "" {
+		md[metaChecksum] = aws.String(m.Checksum)
+	}
+	if !m.EndTime.IsZero() {
+		md[metaEndTime] = aws.String(m.EndTime.UTC().Format(time.RFC3339Nano))
+	}
+	if len(md) == 0 {
+		return nil
+	}
+	return md
+}
+
+// s3UserMetadata returns the S3 metadata that stores the user metadata
+// md, or nil if it is empty.
+func s3UserMetadata(md map[string]string) map[string]*string {
+	return reportMeta{Metadata: md}.s3Metadata()
+}
+
+// parseS3Metadata returns the report metadata stored in the S3 metadata
+// md, with the keys of the rest of the user metadata in canonical form.
+// Invalid values are ignored.
+func parseS3Metadata(md map[string]*string) reportMeta {
+	var m reportMeta
+	for k, v := range md {
+		// S3 does not preserve the case of the keys.
+		switch {
+		case strings.EqualFold(k, metaChecksum):
+			m.Checksum = aws.StringValue(v)
+		case strings.EqualFold(k, metaEndTime):
+			if t, err := time.Parse(time.RFC3339Nano, aws.StringValue(v)); err == nil {
+				m.EndTime = t
+			}
+		default:
+			if m.Metadata == nil {
+				m.Metadata = map[string]string{}
+			}
+			m.Metadata[http.CanonicalHeaderKey(k)] = aws.StringValue(v)
+		}
+	}
+	return m
+}
+
+// storedReportMeta returns the metadata of the report stored in S3 under
+// the given key, or empty metadata if the report does not exist.
+func (s *S3Storage) storedReportMeta(ctx context.Context, key string) (reportMeta, error) {
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+		"key":    key,
+		"bucket": s.Conf.BucketReports,
+	}).Debug("reading metadata from S3 bucket")
+
+	var out *s3.HeadObjectOutput
+	err := s.do(ctx, "HeadObject", s.Conf.BucketReports, func(ctx context.Context) (err error) {
+		out, err = s.svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(s.Conf.BucketReports),
+			Key:    aws.String(key),
+		})
+		return err
+	})
+	if errors.Is(err, ErrNotFound) {
+		return reportMeta{}, nil
+	}
+	if err != nil {
+		return reportMeta{}, err
+	}
+	return parseS3Metadata(out.Metadata), nil
+}
@@ -7,20 +7,38 @@ package storage
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/url"
 	"path"
 	"time"
 
+	metrics "github.com/adevinta/vulcan-metrics-client"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"github.com/goadesign/goa/middleware"
 	"github.com/sirupsen/logrus"
 )
 
-// Config represents the configuration options for S3Storage objects
+// Supported storage backends.
+const (
+	BackendS3         = "s3"
+	BackendFilesystem = "filesystem"
+)
+
+// Config represents the configuration options for Storage objects.
 type Config struct {
+	// Backend selects the storage implementation, either "s3" (default)
+	// or "filesystem".
+	Backend string
+	// Root is the directory under which the filesystem backend stores
+	// the results. It is ignored by the S3 backend.
+	Root string
+
 	BucketVulnerableReports string
 	BucketReports           string
 	BucketLogs              string
@@ -28,50 +46,125 @@ type Config struct {
 	LinkBase                string
 	Endpoint                string
 	PathStyle               bool
+
+	// CacheControl is the value of the HTTP Cache-Control header
+	// returned with the stored reports and logs. The S3 backend also
+	// stores it in the metadata of the objects it uploads.
+	CacheControl string
+
+	// PresignExpiry is the time the presigned URLs handed out by the S3
+	// backend are valid, DefaultPresignExpiry if zero. S3 does not
+	// accept more than MaxPresignExpiry.
+	PresignExpiry time.Duration
+
+	// RepairInterval is the interval the service completes the writes
+	// of the vulnerable reports left unfinished at,
+	// DefaultRepairInterval if zero.
+	RepairInterval time.Duration
+
+	// Resilience configures how the S3 backend copes with S3 being
+	// unavailable.
+	Resilience ResilienceConfig `toml:"Resilience"`
+
+	// Encryption configures the server-side encryption of the objects
+	// uploaded by the S3 backend.
+	Encryption EncryptionConfig `toml:"Encryption"`
+
+	// Envelope configures the encryption of the results by the service
+	// before they are stored.
+	Envelope EnvelopeConfig `toml:"Envelope"`
 }
 
 // Storage is an interface of a type that can save a result.
+//
+// Every method receives the context of the request that triggered it.
+// Implementations must abort the operation when the context is done.
 type Storage interface {
-	SaveReports(scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool) (link string, err error)
-	SaveLogs(scanID, checkID string, startedAt time.Time, logs []byte) (link string, err error)
-
-	GetReport(date, scanID, checkID string) ([]byte, error)
-	GetLog(date, scanID, checkID string) ([]byte, error)
+	// SaveReports stores a report. Depending on opts, the write is
+	// skipped if the stored report is the same, and fails with
+	// ErrConflict if the stored report is newer.
+	SaveReports(ctx context.Context, scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool, opts SaveOptions) (link string, err error)
+	SaveLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs []byte) (link string, err error)
+	// StreamLogs stores the logs read from r without holding them in
+	// memory. It fails with the error returned by r, if any, and the
+	// partially read logs are not stored.
+	StreamLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs io.Reader) (link string, err error)
+
+	// GetReport and GetLog return the stored object, or the range of it
+	// selected by opts, so it can be streamed to the client. The caller
+	// must close the body of the object.
+	GetReport(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error)
+	GetLog(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error)
+
+	// ListChecks returns a page of at most limit reports and logs stored
+	// for the checks of the scan started at the given date, in the
+	// YYYY-MM-DD format. The token is the Next field of the previous
+	// page, or empty to get the first one.
+	ListChecks(ctx context.Context, date, scanID, token string, limit int) (*CheckPage, error)
+	// ListScans returns the scans that stored reports between the given
+	// dates, both included.
+	ListScans(ctx context.Context, from, to time.Time) ([]ScanSummary, error)
 }
 
 // S3Storage implements the Storage interface storing the results in S3.
 type S3Storage struct {
-	Conf   Config
-	logger *logrus.Entry
-	svc    s3iface.S3API
+	Conf Config
+	// Metrics, if not nil, receives the duration and the errors of the
+	// calls to S3, and the sizes of the objects.
+	Metrics metrics.Client
+
+	logger   *logrus.Entry
+	svc      s3iface.S3API
+	uploader s3manageriface.UploaderAPI
+	breaker  *breaker
 }
 
 // NewS3Storage creates a S3Storage for a specified bucket.
 func NewS3Storage(c Config, l *logrus.Entry, s s3iface.S3API) *S3Storage {
-	return &S3Storage{Conf: c, logger: l, svc: s}
+	return &S3Storage{
+		Conf:     c,
+		logger:   l,
+		svc:      s,
+		uploader: s3manager.NewUploaderWithClient(s),
+		breaker:  newBreaker(c.Resilience, l),
+	}
 }
 
-// SaveReports stores the result in an S3 file.
-func (s *S3Storage) SaveReports(scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool) (link string, err error) {
-	//see http://docs.aws.amazon.com/athena/latest/ug/partitions.html
-	dt := startedAt.Format("dt=2006-01-02")
-	scan := "scan=" + scanID
+// SaveReports stores the result in an S3 file. The checksum and end
+// time in opts, and the user metadata of ctx, are stored in the metadata
+// of the object.
+func (s *S3Storage) SaveReports(ctx context.Context, scanID, checkID string, startedAt time.Time, report []byte, vulnerable bool, opts SaveOptions) (link string, err error) {
+	dt, scan := Partition(scanID, startedAt)
 
 	key := fmt.Sprintf("%s/%s/%s.json", dt, scan, checkID)
-	if vulnerable {
-		compress := true
-		err = s.uploadToBucket(s.Conf.BucketVulnerableReports, key+".gz", report, compress, aws.String("gzip"))
-		if err != nil {
-			return "", err
-		}
-	}
 
 	link, err = urlConcat(s.Conf.LinkBase, "reports", dt, scan, checkID+".json")
 	if err != nil {
 		return "", err
 	}
 
-	err = s.uploadToBucket(s.Conf.BucketReports, key, report, false, aws.String("text/json"))
+	if opts.conditional() {
+		stored, err := s.storedReportMeta(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		skip, err := checkSave(opts, stored)
+		if err != nil {
+			return "", err
+		}
+		if skip {
+			contextLogger(ctx, s.logger).WithField("key", key).Debug("report unchanged, not uploaded")
+			return link, nil
+		}
+	}
+
+	meta := opts.meta()
+	meta.Metadata = objectMetadata(ctx)
+	if vulnerable {
+		err = s.saveVulnerableReport(ctx, key, report, meta)
+	} else {
+		err = s.uploadToBucket(ctx, s.Conf.BucketReports, key, report, false, aws.String("text/json"), meta.s3Metadata())
+	}
 	if err != nil {
 		return "", err
 	}
@@ -80,10 +173,8 @@ func (s *S3Storage) SaveReports(scanID, checkID string, startedAt time.Time, rep
 }
 
 // SaveLogs stores the result in an S3 file.
-func (s *S3Storage) SaveLogs(scanID, checkID string, startedAt time.Time, logs []byte) (link string, err error) {
-	//see http://docs.aws.amazon.com/athena/latest/ug/partitions.html
-	dt := startedAt.Format("dt=2006-01-02")
-	scan := "scan=" + scanID
+func (s *S3Storage) SaveLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs []byte) (link string, err error) {
+	dt, scan := Partition(scanID, startedAt)
 
 	key := fmt.Sprintf("%s/%s/%s.log", dt, scan, checkID)
 
@@ -92,7 +183,7 @@ func (s *S3Storage) SaveLogs(scanID, checkID string, startedAt time.Time, logs [
 		return "", err
 	}
 
-	err = s.uploadToBucket(s.Conf.BucketLogs, key, logs, false, nil)
+	err = s.uploadToBucket(ctx, s.Conf.BucketLogs, key, logs, false, nil, s3UserMetadata(objectMetadata(ctx)))
 	if err != nil {
 		return "", err
 	}
@@ -100,72 +191,213 @@ func (s *S3Storage) SaveLogs(scanID, checkID string, startedAt time.Time, logs [
 	return
 }
 
+// StreamLogs uploads the logs to S3 using a multipart upload, so only
+// the parts being uploaded are held in memory. If reading the logs fails
+// the upload is aborted.
+func (s *S3Storage) StreamLogs(ctx context.Context, scanID, checkID string, startedAt time.Time, logs io.Reader) (link string, err error) {
+	dt, scan := Partition(scanID, startedAt)
+
+	key := fmt.Sprintf("%s/%s/%s.log", dt, scan, checkID)
+
+	link, err = urlConcat(s.Conf.LinkBase, "logs", dt, scan, checkID+".log")
+	if err != nil {
+		return "", err
+	}
+
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
+		"key":    key,
+		"bucket": s.Conf.BucketLogs,
+	}).Debug("streaming content to S3 bucket")
+
+	// The logs can only be read once, so the upload is not retried nor
+	// bounded by the call timeout, but it is still guarded by the
+	// circuit breaker.
+	if err := s.breaker.allow(); err != nil {
+		s.observeCall("Upload", s.Conf.BucketLogs, 0, err)
+		return "", err
+	}
+	start := time.Now()
+	body := &countingReader{Reader: logs}
+	sse, keyID, bucketKey := s.Conf.encryption(s.Conf.BucketLogs).s3Params()
+	_, err = s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:               aws.String(s.Conf.BucketLogs),
+		Key:                  aws.String(key),
+		Body:                 body,
+		CacheControl:         s.cacheControl(),
+		Metadata:             s3UserMetadata(objectMetadata(ctx)),
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          keyID,
+		BucketKeyEnabled:     bucketKey,
+	})
+	if rerr := readError(err); rerr != nil {
+		s.breaker.abort()
+		return "", rerr
+	}
+	err = s3Error(err)
+	s.breaker.record(ctx, err)
+	s.observeCall("Upload", s.Conf.BucketLogs, time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+	s.observeSize("Upload", s.Conf.BucketLogs, body.n)
+
+	return link, nil
+}
+
 // GetReport downloads from S3 and returns the report that corresponds
 // to the input params.
-func (s *S3Storage) GetReport(date, scanID, checkID string) ([]byte, error) {
+func (s *S3Storage) GetReport(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error) {
 	key := fmt.Sprintf("%s/%s/%s", date, scanID, checkID)
 
-	return s.downloadFromBucket(s.Conf.BucketReports, key)
+	return s.downloadFromBucket(ctx, s.Conf.BucketReports, key, opts)
 }
 
 // GetLog downloads from S3 and returns the report that corresponds
 // to the input params.
-func (s *S3Storage) GetLog(date, scanID, checkID string) ([]byte, error) {
+func (s *S3Storage) GetLog(ctx context.Context, date, scanID, checkID string, opts GetOptions) (*Object, error) {
 	key := fmt.Sprintf("%s/%s/%s", date, scanID, checkID)
 
-	return s.downloadFromBucket(s.Conf.BucketLogs, key)
+	return s.downloadFromBucket(ctx, s.Conf.BucketLogs, key, opts)
 }
 
-func (s *S3Storage) uploadToBucket(bucket, key string, content []byte, compress bool, contentType *string) (err error) {
+func (s *S3Storage) uploadToBucket(ctx context.Context, bucket, key string, content []byte, compress bool, contentType *string, metadata map[string]*string) (err error) {
 	if compress {
-		var buf bytes.Buffer
-		zw := gzip.NewWriter(&buf)
-		_, err = zw.Write(content)
+		size := len(content)
+		content, err = gzipContent(content)
 		if err != nil {
 			return err
 		}
-
-		if err = zw.Close(); err != nil {
-			return err
-		}
-		content = buf.Bytes()
+		s.observeCompression(bucket, size, len(content))
 	}
 
-	s.logger.WithFields(logrus.Fields{
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
 		"content": string(content),
 		"key":     key,
 		"bucket":  bucket,
 	}).Debug("uploading content to S3 bucket")
 
-	params := &s3.PutObjectInput{
-		Bucket:      aws.String(bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(content),
-		ContentType: contentType,
+	sse, keyID, bucketKey := s.Conf.encryption(bucket).s3Params()
+	err = s.do(ctx, "PutObject", bucket, func(ctx context.Context) error {
+		// The body is created for every attempt, as a failed attempt
+		// may have read it.
+		_, err := s.svc.PutObjectWithContext(ctx, &s3.PutObjectInput{
+			Bucket:               aws.String(bucket),
+			Key:                  aws.String(key),
+			Body:                 bytes.NewReader(content),
+			ContentType:          contentType,
+			CacheControl:         s.cacheControl(),
+			Metadata:             metadata,
+			ServerSideEncryption: sse,
+			SSEKMSKeyId:          keyID,
+			BucketKeyEnabled:     bucketKey,
+		})
+		return err
+	})
+	if err != nil {
+		return err
 	}
-	_, err = s.svc.PutObject(params)
+	s.observeSize("PutObject", bucket, int64(len(content)))
+	return nil
+}
 
-	return
+// cacheControl returns the Cache-Control metadata of the uploaded
+// objects, or nil if it is not configured.
+func (s *S3Storage) cacheControl() *string {
+	if s.Conf.CacheControl == "" {
+		return nil
+	}
+	return aws.String(s.Conf.CacheControl)
 }
 
-func (s *S3Storage) downloadFromBucket(bucket, key string) ([]byte, error) {
-	s.logger.WithFields(logrus.Fields{
+// downloadFromBucket returns the object stored in S3 without reading its
+// content. Ranges and conditions are resolved by S3.
+func (s *S3Storage) downloadFromBucket(ctx context.Context, bucket, key string, opts GetOptions) (*Object, error) {
+	contextLogger(ctx, s.logger).WithFields(logrus.Fields{
 		"key":    key,
 		"bucket": bucket,
+		"range":  opts.Range,
 	}).Debug("downloading content from S3 bucket")
 
 	params := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	}
+	if _, ok := parseRange(opts.Range); ok {
+		params.Range = aws.String(opts.Range)
+	}
+	if opts.IfNoneMatch != "" {
+		params.IfNoneMatch = aws.String(opts.IfNoneMatch)
+	} else if !opts.IfModifiedSince.IsZero() {
+		params.IfModifiedSince = aws.Time(opts.IfModifiedSince)
+	}
 
-	obj, err := s.svc.GetObject(params)
+	var out *s3.GetObjectOutput
+	release, err := s.call(ctx, "GetObject", bucket, func(ctx context.Context) (err error) {
+		out, err = s.svc.GetObjectWithContext(ctx, params)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer obj.Body.Close()
 
-	return ioutil.ReadAll(obj.Body)
+	obj := &Object{
+		Body:         releaseCloser{ReadCloser: out.Body, release: release},
+		Size:         aws.Int64Value(out.ContentLength),
+		ETag:         aws.StringValue(out.ETag),
+		LastModified: aws.TimeValue(out.LastModified),
+		CacheControl: aws.StringValue(out.CacheControl),
+		Metadata:     parseS3Metadata(out.Metadata).Metadata,
+	}
+	s.observeSize("GetObject", bucket, obj.Size)
+	if obj.CacheControl == "" {
+		// Objects uploaded before the setting was configured.
+		obj.CacheControl = s.Conf.CacheControl
+	}
+	if r, size, ok := parseContentRange(aws.StringValue(out.ContentRange)); ok {
+		obj.Range = &r
+		obj.Size = size
+	}
+	return obj, nil
+}
+
+// releaseCloser is a ReadCloser that calls release when it is closed.
+type releaseCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r releaseCloser) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
+
+// contextLogger returns l annotated with the ID of the request ctx
+// belongs to, if any.
+func contextLogger(ctx context.Context, l *logrus.Entry) *logrus.Entry {
+	if id := middleware.ContextRequestID(ctx); id != "" {
+		return l.WithField("request_id", id)
+	}
+	return l
+}
+
+// Partition returns the Athena partition path elements under which the
+// results of a scan are stored, which identify them in the links and
+// downloads too.
+// See http://docs.aws.amazon.com/athena/latest/ug/partitions.html
+func Partition(scanID string, startedAt time.Time) (dt, scan string) {
+	return startedAt.Format("dt=2006-01-02"), "scan=" + scanID
+}
+
+func gzipContent(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 func urlConcat(baseURL string, toConcat ...string) (string, error) {
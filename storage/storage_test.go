@@ -5,12 +5,16 @@ Copyright 2019 Adevinta
 package storage
 
 import (
+	"context"
 	"errors"
 	"io/ioutil"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/sirupsen/logrus"
@@ -301,11 +305,15 @@ type mockS3Client struct {
 	err error
 }
 
-func (m mockS3Client) PutObject(s *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+func (m mockS3Client) PutObjectWithContext(ctx aws.Context, s *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
 	return m.putObjectOutput, m.err
 }
 
-func (m mockS3Client) GetObject(s *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+func (m mockS3Client) DeleteObjectWithContext(ctx aws.Context, s *s3.DeleteObjectInput, opts ...request.Option) (*s3.DeleteObjectOutput, error) {
+	return &s3.DeleteObjectOutput{}, m.err
+}
+
+func (m mockS3Client) GetObjectWithContext(ctx aws.Context, s *s3.GetObjectInput, opts ...request.Option) (*s3.GetObjectOutput, error) {
 	if *s.Bucket != m.expectedBucket || *s.Key != m.expectedKey {
 		return nil, errors.New("Invalid bucket or key")
 	}
@@ -325,7 +333,7 @@ func TestSaveReports(t *testing.T) {
 			l := logrus.New().WithFields(logrus.Fields{"test": tc.name})
 			s := &S3Storage{Conf: tc.config, logger: l, svc: tc.s3Mock}
 
-			link, err := s.SaveReports(tc.scanID, tc.checkID, tc.startedAt, tc.report, tc.vulnerable)
+			link, err := s.SaveReports(context.Background(), tc.scanID, tc.checkID, tc.startedAt, tc.report, tc.vulnerable, SaveOptions{})
 			if tc.expectedErr && err == nil {
 				t.Fatalf("expected error, got none")
 			} else if !tc.expectedErr && err != nil {
@@ -352,7 +360,7 @@ func TestSaveLogs(t *testing.T) {
 			l := logrus.New().WithFields(logrus.Fields{"test": tc.name})
 			s := &S3Storage{Conf: tc.config, logger: l, svc: tc.s3Mock}
 
-			link, err := s.SaveLogs(tc.scanID, tc.checkID, tc.startedAt, tc.logs)
+			link, err := s.SaveLogs(context.Background(), tc.scanID, tc.checkID, tc.startedAt, tc.logs)
 			if tc.expectedErr && err == nil {
 				t.Fatalf("expected error, got none")
 			} else if !tc.expectedErr && err != nil {
@@ -375,15 +383,15 @@ func TestGetReport(t *testing.T) {
 			l := logrus.New().WithFields(logrus.Fields{"test": tc.name})
 			s := &S3Storage{Conf: tc.config, logger: l, svc: tc.s3Mock}
 
-			report, err := s.GetReport(tc.date, tc.scanID, tc.checkID)
+			report, err := s.GetReport(context.Background(), tc.date, tc.scanID, tc.checkID, GetOptions{})
 			if tc.expectedErr && err == nil {
 				t.Fatalf("expected error, got none")
 			} else if !tc.expectedErr && err != nil {
 				t.Fatalf("expected no error, got: %v", err)
 			} else
 
-			if tc.expectedReport != string(report) {
-				t.Fatalf("expected report to be '%s', got: '%s'", tc.expectedReport, string(report))
+			if content := objectContent(t, report); tc.expectedReport != content {
+				t.Fatalf("expected report to be '%s', got: '%s'", tc.expectedReport, content)
 			}
 		})
 	}
@@ -398,15 +406,105 @@ func TestGetLog(t *testing.T) {
 			l := logrus.New().WithFields(logrus.Fields{"test": tc.name})
 			s := &S3Storage{Conf: tc.config, logger: l, svc: tc.s3Mock}
 
-			log, err := s.GetLog(tc.date, tc.scanID, tc.checkID)
+			log, err := s.GetLog(context.Background(), tc.date, tc.scanID, tc.checkID, GetOptions{})
 			if tc.expectedErr && err == nil {
 				t.Fatalf("expected error, got none")
 			} else if !tc.expectedErr && err != nil {
 				t.Fatalf("expected no error, got: %v", err)
 			} else
 
-			if tc.expectedReport != string(log) {
-				t.Fatalf("expected log to be '%s', got: '%s'", tc.expectedReport, string(log))
+			if content := objectContent(t, log); tc.expectedReport != content {
+				t.Fatalf("expected log to be '%s', got: '%s'", tc.expectedReport, content)
+			}
+		})
+	}
+}
+
+// objectContent reads and closes the body of obj. It returns an empty
+// string if obj is nil.
+func objectContent(t *testing.T, obj *Object) string {
+	t.Helper()
+
+	if obj == nil {
+		return ""
+	}
+	defer obj.Body.Close()
+	content, err := ioutil.ReadAll(obj.Body)
+	if err != nil {
+		t.Fatalf("expected no error reading object, got: %v", err)
+	}
+	return string(content)
+}
+
+func TestS3Error(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "no such key",
+			err:      awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil),
+			expected: ErrNotFound,
+		},
+		{
+			name:     "not found on head",
+			err:      awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), 404, "id"),
+			expected: ErrNotFound,
+		},
+		{
+			name:     "not modified",
+			err:      awserr.NewRequestFailure(awserr.New("NotModified", "Not Modified", nil), 304, "id"),
+			expected: ErrNotModified,
+		},
+		{
+			name:     "invalid range",
+			err:      awserr.NewRequestFailure(awserr.New("InvalidRange", "The requested range is not satisfiable", nil), 416, "id"),
+			expected: ErrRangeNotSatisfiable,
+		},
+		{
+			name:     "slow down",
+			err:      awserr.NewRequestFailure(awserr.New("SlowDown", "Please reduce your request rate.", nil), 503, "id"),
+			expected: ErrUnavailable,
+		},
+		{
+			name:     "connection error",
+			err:      awserr.New(request.ErrCodeRequestError, "send request failed", errors.New("connection refused")),
+			expected: ErrUnavailable,
+		},
+		{
+			name:     "server error",
+			err:      awserr.NewRequestFailure(awserr.New("Unknown", "unknown", nil), 500, "id"),
+			expected: ErrUnavailable,
+		},
+		{
+			name:     "no such bucket",
+			err:      awserr.NewRequestFailure(awserr.New("NoSuchBucket", "The specified bucket does not exist", nil), 404, "id"),
+			expected: ErrMisconfigured,
+		},
+		{
+			name: "access denied",
+			err:  awserr.NewRequestFailure(awserr.New("AccessDenied", "Access Denied", nil), 403, "id"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := s3Error(tc.err)
+			if tc.expected != nil && !errors.Is(err, tc.expected) {
+				t.Fatalf("expected error %v, got: %v", tc.expected, err)
+			}
+			if tc.expected == nil && (errors.Is(err, ErrNotFound) || errors.Is(err, ErrUnavailable)) {
+				t.Fatalf("expected unclassified error, got: %v", err)
+			}
+			if tc.expected != ErrUnavailable && errors.Is(err, ErrUnavailable) {
+				t.Fatalf("expected an error that is not retried, got: %v", err)
+			}
+			var aerr awserr.Error
+			if !errors.As(err, &aerr) {
+				t.Fatalf("expected the AWS error to be kept, got: %v", err)
 			}
 		})
 	}
@@ -0,0 +1,547 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+package storagetest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// FakeS3 is an in-memory implementation of the subset of s3iface.S3API
+// used by storage.S3Storage. Calling any other method panics.
+type FakeS3 struct {
+	s3iface.S3API
+
+	// Fault, if not nil, is called before getting, heading, putting or
+	// deleting an object, and before heading a bucket, with an empty key.
+	// The operation fails with the error it returns, if any.
+	Fault func(op, bucket, key string) error
+
+	mu      sync.RWMutex
+	buckets map[string]map[string]*FakeObject
+	uploads map[string]*fakeUpload
+	nextID  int
+}
+
+// fakeUpload is a multipart upload in progress.
+type fakeUpload struct {
+	bucket, key  string
+	contentType  *string
+	cacheControl *string
+	metadata     map[string]*string
+	encryption   FakeEncryption
+	parts        map[int64][]byte
+}
+
+// FakeObject is an object stored in a FakeS3.
+type FakeObject struct {
+	Body         []byte
+	ContentType  *string
+	CacheControl *string
+	Metadata     map[string]*string
+	Encryption   FakeEncryption
+	ETag         string
+	LastModified time.Time
+}
+
+// FakeEncryption holds the server-side encryption params an object was
+// uploaded with.
+type FakeEncryption struct {
+	ServerSideEncryption *string
+	SSEKMSKeyID          *string
+	BucketKeyEnabled     *bool
+}
+
+// NewFakeS3 returns an empty FakeS3.
+func NewFakeS3() *FakeS3 {
+	return &FakeS3{
+		buckets: map[string]map[string]*FakeObject{},
+		uploads: map[string]*fakeUpload{},
+	}
+}
+
+// PutObjectWithContext stores an object in the fake.
+func (f *FakeS3) PutObjectWithContext(ctx aws.Context, in *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	if err := f.check(ctx, "PutObject", in.Bucket, in.Key); err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if in.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(in.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	obj := f.put(aws.StringValue(in.Bucket), aws.StringValue(in.Key), body, in.ContentType, in.CacheControl)
+	obj.Encryption = FakeEncryption{in.ServerSideEncryption, in.SSEKMSKeyId, in.BucketKeyEnabled}
+	obj.Metadata = fakeMetadata(in.Metadata)
+
+	return &s3.PutObjectOutput{}, nil
+}
+
+// fakeMetadata returns a copy of the metadata of an object being stored,
+// with the keys in canonical form as S3 returns them.
+func fakeMetadata(md map[string]*string) map[string]*string {
+	if len(md) == 0 {
+		return nil
+	}
+	m := map[string]*string{}
+	for k, v := range md {
+		m[http.CanonicalHeaderKey(k)] = aws.String(aws.StringValue(v))
+	}
+	return m
+}
+
+// PutObjectRequest returns a request that stores an object in the fake
+// when sent. It is used by s3manager.Uploader for single part uploads.
+func (f *FakeS3) PutObjectRequest(in *s3.PutObjectInput) (*request.Request, *s3.PutObjectOutput) {
+	out := &s3.PutObjectOutput{}
+	req := fakeRequest("PutObject", in, out, func(r *request.Request) {
+		_, r.Error = f.PutObjectWithContext(r.Context(), in)
+	})
+	return req, out
+}
+
+// GetObjectRequest returns a request that gets an object from the fake
+// when sent. It is used by s3manager.Uploader to presign the location of
+// multipart uploads, and by storage.S3Storage to presign downloads. The
+// presigned URLs have the form
+// https://<bucket>.fake-s3/<key>?X-Amz-Expires=<seconds>.
+func (f *FakeS3) GetObjectRequest(in *s3.GetObjectInput) (*request.Request, *s3.GetObjectOutput) {
+	out := &s3.GetObjectOutput{}
+	req := fakeRequest("GetObject", in, out, func(r *request.Request) {
+		var res *s3.GetObjectOutput
+		res, r.Error = f.GetObjectWithContext(r.Context(), in)
+		if res != nil {
+			*out = *res
+		}
+	})
+	req.HTTPRequest.URL = &url.URL{
+		Scheme: "https",
+		Host:   aws.StringValue(in.Bucket) + ".fake-s3",
+		Path:   "/" + aws.StringValue(in.Key),
+	}
+	req.Handlers.Sign.PushBack(func(r *request.Request) {
+		q := r.HTTPRequest.URL.Query()
+		q.Set("X-Amz-Expires", strconv.FormatInt(int64(r.ExpireTime/time.Second), 10))
+		r.HTTPRequest.URL.RawQuery = q.Encode()
+	})
+	return req, out
+}
+
+// CreateMultipartUploadWithContext starts a multipart upload.
+func (f *FakeS3) CreateMultipartUploadWithContext(ctx aws.Context, in *s3.CreateMultipartUploadInput, _ ...request.Option) (*s3.CreateMultipartUploadOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nextID++
+	id := strconv.Itoa(f.nextID)
+	f.uploads[id] = &fakeUpload{
+		bucket:       aws.StringValue(in.Bucket),
+		key:          aws.StringValue(in.Key),
+		contentType:  in.ContentType,
+		cacheControl: in.CacheControl,
+		metadata:     fakeMetadata(in.Metadata),
+		encryption:   FakeEncryption{in.ServerSideEncryption, in.SSEKMSKeyId, in.BucketKeyEnabled},
+		parts:        map[int64][]byte{},
+	}
+
+	return &s3.CreateMultipartUploadOutput{
+		Bucket:   in.Bucket,
+		Key:      in.Key,
+		UploadId: aws.String(id),
+	}, nil
+}
+
+// UploadPartWithContext stores a part of a multipart upload.
+func (f *FakeS3) UploadPartWithContext(ctx aws.Context, in *s3.UploadPartInput, _ ...request.Option) (*s3.UploadPartOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	u, ok := f.uploads[aws.StringValue(in.UploadId)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchUpload, "The specified upload does not exist.", nil)
+	}
+	u.parts[aws.Int64Value(in.PartNumber)] = body
+
+	return &s3.UploadPartOutput{ETag: aws.String(strconv.FormatInt(aws.Int64Value(in.PartNumber), 10))}, nil
+}
+
+// CompleteMultipartUploadWithContext stores the object made of the parts
+// of a multipart upload.
+func (f *FakeS3) CompleteMultipartUploadWithContext(ctx aws.Context, in *s3.CompleteMultipartUploadInput, _ ...request.Option) (*s3.CompleteMultipartUploadOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	u, ok := f.uploads[aws.StringValue(in.UploadId)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchUpload, "The specified upload does not exist.", nil)
+	}
+
+	var body []byte
+	for _, p := range in.MultipartUpload.Parts {
+		part, ok := u.parts[aws.Int64Value(p.PartNumber)]
+		if !ok {
+			return nil, awserr.New("InvalidPart", "One or more of the specified parts could not be found.", nil)
+		}
+		body = append(body, part...)
+	}
+	delete(f.uploads, aws.StringValue(in.UploadId))
+	obj := f.put(u.bucket, u.key, body, u.contentType, u.cacheControl)
+	obj.Metadata = u.metadata
+	obj.Encryption = u.encryption
+
+	return &s3.CompleteMultipartUploadOutput{Bucket: in.Bucket, Key: in.Key}, nil
+}
+
+// AbortMultipartUploadWithContext discards a multipart upload.
+func (f *FakeS3) AbortMultipartUploadWithContext(ctx aws.Context, in *s3.AbortMultipartUploadInput, _ ...request.Option) (*s3.AbortMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.uploads, aws.StringValue(in.UploadId))
+
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+// DeleteObjectWithContext deletes an object from the fake. As S3 does,
+// it succeeds if the object does not exist.
+func (f *FakeS3) DeleteObjectWithContext(ctx aws.Context, in *s3.DeleteObjectInput, _ ...request.Option) (*s3.DeleteObjectOutput, error) {
+	if err := f.check(ctx, "DeleteObject", in.Bucket, in.Key); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.buckets[aws.StringValue(in.Bucket)], aws.StringValue(in.Key))
+
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+// check returns the error an operation on an object must fail with, if
+// any.
+func (f *FakeS3) check(ctx aws.Context, op string, bucket, key *string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if f.Fault != nil {
+		return f.Fault(op, aws.StringValue(bucket), aws.StringValue(key))
+	}
+	return nil
+}
+
+// Uploads returns the number of multipart uploads in progress.
+func (f *FakeS3) Uploads() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return len(f.uploads)
+}
+
+// put stores an object and returns it so the caller can set the rest of
+// its fields.
+func (f *FakeS3) put(bucket, key string, body []byte, contentType, cacheControl *string) *FakeObject {
+	b, ok := f.buckets[bucket]
+	if !ok {
+		b = map[string]*FakeObject{}
+		f.buckets[bucket] = b
+	}
+	obj := &FakeObject{
+		Body:         body,
+		ContentType:  contentType,
+		CacheControl: cacheControl,
+		ETag:         fmt.Sprintf(`"%x"`, md5.Sum(body)),
+		LastModified: time.Now(),
+	}
+	b[key] = obj
+	return obj
+}
+
+// fakeRequest returns a request that runs send instead of calling S3.
+func fakeRequest(name string, in, out interface{}, send func(*request.Request)) *request.Request {
+	req := request.New(aws.Config{}, metadata.ClientInfo{}, request.Handlers{}, nil,
+		&request.Operation{Name: name}, in, out)
+	req.Handlers.Send.PushBack(send)
+	return req
+}
+
+// GetObjectWithContext returns an object from the fake. It fails with a
+// NoSuchKey error if the object does not exist.
+func (f *FakeS3) GetObjectWithContext(ctx aws.Context, in *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	if err := f.check(ctx, "GetObject", in.Bucket, in.Key); err != nil {
+		return nil, err
+	}
+
+	obj, ok := f.Object(aws.StringValue(in.Bucket), aws.StringValue(in.Key))
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "The specified key does not exist.", nil)
+	}
+
+	if fakeNotModified(in, obj) {
+		return nil, awserr.NewRequestFailure(
+			awserr.New("NotModified", "Not Modified", nil),
+			http.StatusNotModified, "fake")
+	}
+
+	out := &s3.GetObjectOutput{
+		ContentType:  obj.ContentType,
+		CacheControl: obj.CacheControl,
+		Metadata:     obj.Metadata,
+		ETag:         aws.String(obj.ETag),
+		LastModified: aws.Time(obj.LastModified),
+	}
+	body := obj.Body
+	if in.Range != nil {
+		size := int64(len(obj.Body))
+		start, end, ok := fakeRange(aws.StringValue(in.Range), size)
+		if !ok {
+			return nil, awserr.NewRequestFailure(
+				awserr.New("InvalidRange", "The requested range is not satisfiable", nil),
+				http.StatusRequestedRangeNotSatisfiable, "fake")
+		}
+		body = body[start : end+1]
+		out.ContentRange = aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	}
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	out.ContentLength = aws.Int64(int64(len(body)))
+
+	return out, nil
+}
+
+// HeadObjectWithContext returns the metadata of an object in the fake.
+// It fails with a NotFound error if the object does not exist.
+func (f *FakeS3) HeadObjectWithContext(ctx aws.Context, in *s3.HeadObjectInput, _ ...request.Option) (*s3.HeadObjectOutput, error) {
+	if err := f.check(ctx, "HeadObject", in.Bucket, in.Key); err != nil {
+		return nil, err
+	}
+
+	obj, ok := f.Object(aws.StringValue(in.Bucket), aws.StringValue(in.Key))
+	if !ok {
+		return nil, awserr.NewRequestFailure(
+			awserr.New("NotFound", "Not Found", nil),
+			http.StatusNotFound, "fake")
+	}
+
+	return &s3.HeadObjectOutput{
+		ContentType:   obj.ContentType,
+		CacheControl:  obj.CacheControl,
+		Metadata:      obj.Metadata,
+		ETag:          aws.String(obj.ETag),
+		LastModified:  aws.Time(obj.LastModified),
+		ContentLength: aws.Int64(int64(len(obj.Body))),
+	}, nil
+}
+
+// AddBucket creates an empty bucket in the fake. Buckets are also
+// created when the first object is stored in them.
+func (f *FakeS3) AddBucket(bucket string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.buckets[bucket]; !ok {
+		f.buckets[bucket] = map[string]*FakeObject{}
+	}
+}
+
+// HeadBucketWithContext checks a bucket exists in the fake. It fails
+// with a NotFound error if it does not.
+func (f *FakeS3) HeadBucketWithContext(ctx aws.Context, in *s3.HeadBucketInput, _ ...request.Option) (*s3.HeadBucketOutput, error) {
+	if err := f.check(ctx, "HeadBucket", in.Bucket, nil); err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	_, ok := f.buckets[aws.StringValue(in.Bucket)]
+	f.mu.RUnlock()
+	if !ok {
+		return nil, awserr.NewRequestFailure(
+			awserr.New("NotFound", "Not Found", nil),
+			http.StatusNotFound, "fake")
+	}
+
+	return &s3.HeadBucketOutput{}, nil
+}
+
+// fakeNotModified tells whether the conditions of a GetObject request
+// are not met because the object was not modified.
+func fakeNotModified(in *s3.GetObjectInput, obj FakeObject) bool {
+	if in.IfNoneMatch != nil {
+		for _, tag := range strings.Split(aws.StringValue(in.IfNoneMatch), ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || tag == obj.ETag {
+				return true
+			}
+		}
+		return false
+	}
+	if in.IfModifiedSince != nil {
+		return !obj.LastModified.Truncate(time.Second).After(aws.TimeValue(in.IfModifiedSince))
+	}
+	return false
+}
+
+// fakeRange resolves a single byte range, as S3 does, for an object of
+// the given size.
+func fakeRange(rng string, size int64) (start, end int64, ok bool) {
+	var n int64
+	switch {
+	case strings.HasPrefix(rng, "bytes=-"):
+		if _, err := fmt.Sscanf(rng, "bytes=-%d", &n); err != nil || n == 0 || size == 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true
+	case strings.HasSuffix(rng, "-"):
+		if _, err := fmt.Sscanf(rng, "bytes=%d-", &start); err != nil || start >= size {
+			return 0, 0, false
+		}
+		return start, size - 1, true
+	default:
+		if _, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); err != nil || start >= size {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+		return start, end, true
+	}
+}
+
+// ListObjectsV2WithContext lists the objects of a bucket in lexicographic
+// order. When a delimiter is given, the keys that contain it after the
+// prefix are rolled up into common prefixes. The continuation tokens it
+// returns are the last key or common prefix listed.
+func (f *FakeS3) ListObjectsV2WithContext(ctx aws.Context, in *s3.ListObjectsV2Input, _ ...request.Option) (*s3.ListObjectsV2Output, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	prefix := aws.StringValue(in.Prefix)
+	delimiter := aws.StringValue(in.Delimiter)
+	after := aws.StringValue(in.StartAfter)
+	if in.ContinuationToken != nil {
+		after = aws.StringValue(in.ContinuationToken)
+	}
+	maxKeys := int(aws.Int64Value(in.MaxKeys))
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+
+	b := f.buckets[aws.StringValue(in.Bucket)]
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		if strings.HasPrefix(k, prefix) && k > after {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	out := &s3.ListObjectsV2Output{
+		Name:      in.Bucket,
+		Prefix:    in.Prefix,
+		Delimiter: in.Delimiter,
+	}
+	var (
+		n    int
+		last string
+	)
+	for _, k := range keys {
+		common := ""
+		if delimiter != "" {
+			if i := strings.Index(k[len(prefix):], delimiter); i >= 0 {
+				common = k[:len(prefix)+i+len(delimiter)]
+			}
+		}
+		// A common prefix is listed once, and skipped when a listing is
+		// resumed from it.
+		if common != "" && (common == last || strings.HasPrefix(after, common)) {
+			continue
+		}
+		if n == maxKeys {
+			out.IsTruncated = aws.Bool(true)
+			out.NextContinuationToken = aws.String(last)
+			break
+		}
+		n++
+		if common != "" {
+			last = common
+			out.CommonPrefixes = append(out.CommonPrefixes, &s3.CommonPrefix{Prefix: aws.String(common)})
+			continue
+		}
+		last = k
+		obj := b[k]
+		out.Contents = append(out.Contents, &s3.Object{
+			Key:          aws.String(k),
+			Size:         aws.Int64(int64(len(obj.Body))),
+			LastModified: aws.Time(obj.LastModified),
+		})
+	}
+	out.KeyCount = aws.Int64(int64(n))
+	if out.IsTruncated == nil {
+		out.IsTruncated = aws.Bool(false)
+	}
+
+	return out, nil
+}
+
+// Object returns a copy of the object stored under the given bucket and
+// key, and whether it exists.
+func (f *FakeS3) Object(bucket, key string) (FakeObject, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	obj, ok := f.buckets[bucket][key]
+	if !ok {
+		return FakeObject{}, false
+	}
+	o := *obj
+	o.Body = append([]byte(nil), obj.Body...)
+	return o, true
+}
@@ -0,0 +1,666 @@
+/*
+Copyright 2019 Adevinta
+*/
+
+// Package storagetest provides a conformance test suite for
+// implementations of the storage.Storage interface.
+package storagetest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adevinta/vulcan-results/storage"
+)
+
+// Config is the configuration the suite passes to the factory. Backends
+// must honor the bucket names and the link base.
+var Config = storage.Config{
+	BucketReports:           "reports",
+	BucketVulnerableReports: "vulnerable-reports",
+	BucketLogs:              "logs",
+	LinkBase:                "http://results.example.com/v1",
+	CacheControl:            "private, max-age=3600",
+}
+
+// Harness is the storage under test together with the hooks the suite
+// needs to inspect what the backend actually wrote.
+type Harness struct {
+	Storage storage.Storage
+	// Object returns the raw content stored under the given bucket and
+	// key, and whether it exists.
+	Object func(bucket, key string) ([]byte, bool)
+	// NoChecksums tells the storage does not store the checksums of
+	// the reports, so the writes of the same report are not skipped.
+	NoChecksums bool
+}
+
+// Factory returns a new, empty Harness configured with c.
+type Factory func(t *testing.T, c storage.Config) Harness
+
+const (
+	scanID  = "9126034c-7caf-4acd-93f3-bee1941aa140"
+	checkID = "e0c1ac1a-1036-4e0e-b5cc-d18ae6673eb0"
+)
+
+var startedAt = time.Date(2019, time.November, 16, 13, 0, 0, 0, time.UTC)
+
+// Run runs the conformance suite against the storages returned by
+// newHarness.
+func Run(t *testing.T, newHarness Factory) {
+	tests := []struct {
+		name string
+		f    func(t *testing.T, h Harness)
+	}{
+		{"SaveReports", testSaveReports},
+		{"SaveReportsVulnerable", testSaveReportsVulnerable},
+		{"SaveReportsOverwrite", testSaveReportsOverwrite},
+		{"SaveReportsUnchanged", testSaveReportsUnchanged},
+		{"SaveReportsConflict", testSaveReportsConflict},
+		{"SaveLogs", testSaveLogs},
+		{"StreamLogs", testStreamLogs},
+		{"StreamLogsLarge", testStreamLogsLarge},
+		{"StreamLogsReadError", testStreamLogsReadError},
+		{"GetLogRange", testGetLogRange},
+		{"GetLogRangeNotSatisfiable", testGetLogRangeNotSatisfiable},
+		{"GetReportConditional", testGetReportConditional},
+		{"ReportNotFound", testReportNotFound},
+		{"LogNotFound", testLogNotFound},
+		{"CanceledContext", testCanceledContext},
+		{"ListChecks", testListChecks},
+		{"ListChecksEmpty", testListChecksEmpty},
+		{"ListChecksInvalid", testListChecksInvalid},
+		{"ListScans", testListScans},
+		{"ListScansInvalidRange", testListScansInvalidRange},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.f(t, newHarness(t, Config))
+		})
+	}
+}
+
+func testSaveReports(t *testing.T, h Harness) {
+	report := []byte(`{"vulnerabilities":[]}`)
+
+	link, err := h.Storage.SaveReports(context.Background(), scanID, checkID, startedAt, report, false, storage.SaveOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".json"
+	checkLink(t, link, "reports", key)
+	checkObject(t, h, Config.BucketReports, key, report)
+
+	if _, ok := h.Object(Config.BucketVulnerableReports, key+".gz"); ok {
+		t.Fatalf("expected no vulnerable copy for a not vulnerable report")
+	}
+
+	date, scan, check := splitKey(key)
+	obj, err := h.Storage.GetReport(context.Background(), date, scan, check, storage.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := readObject(t, obj); !bytes.Equal(got, report) {
+		t.Fatalf("expected report to be '%s', got: '%s'", report, got)
+	}
+}
+
+func testSaveReportsVulnerable(t *testing.T, h Harness) {
+	report := []byte(`{"vulnerabilities":[{}]}`)
+
+	link, err := h.Storage.SaveReports(context.Background(), scanID, checkID, startedAt, report, true, storage.SaveOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".json"
+	checkLink(t, link, "reports", key)
+	checkObject(t, h, Config.BucketReports, key, report)
+
+	gz, ok := h.Object(Config.BucketVulnerableReports, key+".gz")
+	if !ok {
+		t.Fatalf("expected vulnerable copy %s, got none", key+".gz")
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		t.Fatalf("expected vulnerable copy to be gzipped, got: %v", err)
+	}
+	got, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !bytes.Equal(got, report) {
+		t.Fatalf("expected vulnerable copy to be '%s', got: '%s'", report, got)
+	}
+}
+
+func testSaveReportsOverwrite(t *testing.T, h Harness) {
+	if _, err := h.Storage.SaveReports(context.Background(), scanID, checkID, startedAt, []byte("first"), false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := h.Storage.SaveReports(context.Background(), scanID, checkID, startedAt, []byte("second"), false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".json"
+	date, scan, check := splitKey(key)
+	obj, err := h.Storage.GetReport(context.Background(), date, scan, check, storage.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := readObject(t, obj); string(got) != "second" {
+		t.Fatalf("expected report to be 'second', got: '%s'", got)
+	}
+}
+
+func testSaveReportsUnchanged(t *testing.T, h Harness) {
+	if h.NoChecksums {
+		t.Skip("the storage does not store the checksums")
+	}
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".json"
+
+	// The checksum is trusted, so a different report with the same
+	// checksum shows whether the write was skipped.
+	opts := storage.SaveOptions{Checksum: "same"}
+	if _, err := h.Storage.SaveReports(context.Background(), scanID, checkID, startedAt, []byte("first"), false, opts); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	link, err := h.Storage.SaveReports(context.Background(), scanID, checkID, startedAt, []byte("second"), false, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkLink(t, link, "reports", key)
+	checkObject(t, h, Config.BucketReports, key, []byte("first"))
+
+	opts.Checksum = "other"
+	if _, err := h.Storage.SaveReports(context.Background(), scanID, checkID, startedAt, []byte("second"), false, opts); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkObject(t, h, Config.BucketReports, key, []byte("second"))
+}
+
+func testSaveReportsConflict(t *testing.T, h Harness) {
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".json"
+	endTime := startedAt.Add(time.Hour)
+
+	save := func(report string, opts storage.SaveOptions) error {
+		_, err := h.Storage.SaveReports(context.Background(), scanID, checkID, startedAt, []byte(report), true, opts)
+		return err
+	}
+
+	if err := save("new", storage.SaveOptions{EndTime: endTime}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := save("old", storage.SaveOptions{EndTime: endTime.Add(-time.Minute)}); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got: %v", err)
+	}
+	checkObject(t, h, Config.BucketReports, key, []byte("new"))
+
+	if err := save("newer", storage.SaveOptions{EndTime: endTime.Add(time.Minute)}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkObject(t, h, Config.BucketReports, key, []byte("newer"))
+
+	if err := save("forced", storage.SaveOptions{EndTime: endTime, Force: true}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	checkObject(t, h, Config.BucketReports, key, []byte("forced"))
+}
+
+func testSaveLogs(t *testing.T, h Harness) {
+	logs := []byte("check output\n")
+
+	link, err := h.Storage.SaveLogs(context.Background(), scanID, checkID, startedAt, logs)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".log"
+	checkLink(t, link, "logs", key)
+	checkObject(t, h, Config.BucketLogs, key, logs)
+
+	date, scan, check := splitKey(key)
+	obj, err := h.Storage.GetLog(context.Background(), date, scan, check, storage.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := readObject(t, obj); !bytes.Equal(got, logs) {
+		t.Fatalf("expected log to be '%s', got: '%s'", logs, got)
+	}
+}
+
+func testStreamLogs(t *testing.T, h Harness) {
+	logs := []byte("check output\n")
+
+	link, err := h.Storage.StreamLogs(context.Background(), scanID, checkID, startedAt, bytes.NewReader(logs))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".log"
+	checkLink(t, link, "logs", key)
+	checkObject(t, h, Config.BucketLogs, key, logs)
+}
+
+// testStreamLogsLarge streams logs bigger than the minimum part size of
+// an S3 multipart upload.
+func testStreamLogsLarge(t *testing.T, h Harness) {
+	logs := bytes.Repeat([]byte("0123456789abcdef"), 6<<20/16+1)
+
+	_, err := h.Storage.StreamLogs(context.Background(), scanID, checkID, startedAt, bytes.NewReader(logs))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".log"
+	content, ok := h.Object(Config.BucketLogs, key)
+	if !ok {
+		t.Fatalf("expected object %s to exist in bucket %s", key, Config.BucketLogs)
+	}
+	if !bytes.Equal(content, logs) {
+		t.Fatalf("expected object of %d bytes to match the logs, got %d bytes", len(logs), len(content))
+	}
+}
+
+var errRead = errors.New("read error")
+
+// failingReader returns errRead after returning n bytes.
+type failingReader struct {
+	n int
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, errRead
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	for i := range p {
+		p[i] = 'x'
+	}
+	r.n -= len(p)
+	return len(p), nil
+}
+
+func testStreamLogsReadError(t *testing.T, h Harness) {
+	for _, n := range []int{10, 6 << 20} {
+		_, err := h.Storage.StreamLogs(context.Background(), scanID, checkID, startedAt, &failingReader{n: n})
+		if !errors.Is(err, errRead) {
+			t.Fatalf("expected error %v reading %d bytes, got: %v", errRead, n, err)
+		}
+
+		key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".log"
+		if _, ok := h.Object(Config.BucketLogs, key); ok {
+			t.Fatalf("expected object %s to not exist after reading %d bytes", key, n)
+		}
+	}
+}
+
+func testGetLogRange(t *testing.T, h Harness) {
+	logs := []byte("0123456789")
+	if _, err := h.Storage.SaveLogs(context.Background(), scanID, checkID, startedAt, logs); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	testCases := []struct {
+		rng      string
+		expected string
+		r        *storage.ByteRange
+	}{
+		{"", "0123456789", nil},
+		{"bytes=2-4", "234", &storage.ByteRange{Start: 2, End: 4}},
+		{"bytes=7-", "789", &storage.ByteRange{Start: 7, End: 9}},
+		{"bytes=-3", "789", &storage.ByteRange{Start: 7, End: 9}},
+		{"bytes=8-100", "89", &storage.ByteRange{Start: 8, End: 9}},
+		{"bytes=-100", "0123456789", &storage.ByteRange{Start: 0, End: 9}},
+		// Multiple and malformed ranges are ignored.
+		{"bytes=0-1,3-4", "0123456789", nil},
+		{"bytes=4-2", "0123456789", nil},
+		{"lines=1-2", "0123456789", nil},
+	}
+
+	for _, tc := range testCases {
+		obj, err := h.Storage.GetLog(context.Background(), "dt=2019-11-16", "scan="+scanID, checkID+".log", storage.GetOptions{Range: tc.rng})
+		if err != nil {
+			t.Fatalf("range %q: expected no error, got: %v", tc.rng, err)
+		}
+		if got := readObject(t, obj); string(got) != tc.expected {
+			t.Fatalf("range %q: expected content '%s', got: '%s'", tc.rng, tc.expected, got)
+		}
+		if obj.Size != int64(len(logs)) {
+			t.Fatalf("range %q: expected size %d, got: %d", tc.rng, len(logs), obj.Size)
+		}
+		if tc.r == nil && obj.Range != nil || tc.r != nil && (obj.Range == nil || *obj.Range != *tc.r) {
+			t.Fatalf("range %q: expected range %+v, got: %+v", tc.rng, tc.r, obj.Range)
+		}
+	}
+}
+
+func testGetLogRangeNotSatisfiable(t *testing.T, h Harness) {
+	if _, err := h.Storage.SaveLogs(context.Background(), scanID, checkID, startedAt, []byte("0123456789")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	for _, rng := range []string{"bytes=10-", "bytes=20-30", "bytes=-0"} {
+		_, err := h.Storage.GetLog(context.Background(), "dt=2019-11-16", "scan="+scanID, checkID+".log", storage.GetOptions{Range: rng})
+		if !errors.Is(err, storage.ErrRangeNotSatisfiable) {
+			t.Fatalf("range %q: expected error %v, got: %v", rng, storage.ErrRangeNotSatisfiable, err)
+		}
+	}
+}
+
+func testGetReportConditional(t *testing.T, h Harness) {
+	ctx := context.Background()
+	if _, err := h.Storage.SaveReports(ctx, scanID, checkID, startedAt, []byte("first"), false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	date, scan, check := "dt=2019-11-16", "scan="+scanID, checkID+".json"
+	obj, err := h.Storage.GetReport(ctx, date, scan, check, storage.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	readObject(t, obj)
+	if obj.ETag == "" || obj.LastModified.IsZero() {
+		t.Fatalf("expected ETag and last modified time, got: %+v", obj)
+	}
+	if obj.CacheControl != Config.CacheControl {
+		t.Fatalf("expected Cache-Control %q, got: %q", Config.CacheControl, obj.CacheControl)
+	}
+
+	notModified := []storage.GetOptions{
+		{IfNoneMatch: obj.ETag},
+		{IfNoneMatch: `"other", ` + obj.ETag},
+		{IfNoneMatch: "*"},
+		{IfModifiedSince: obj.LastModified},
+		{IfModifiedSince: obj.LastModified.Add(time.Hour)},
+		// Conditions take precedence over ranges.
+		{IfNoneMatch: obj.ETag, Range: "bytes=100-"},
+	}
+	for _, opts := range notModified {
+		_, err := h.Storage.GetReport(ctx, date, scan, check, opts)
+		if !errors.Is(err, storage.ErrNotModified) {
+			t.Fatalf("options %+v: expected error %v, got: %v", opts, storage.ErrNotModified, err)
+		}
+	}
+
+	modified := []storage.GetOptions{
+		{IfNoneMatch: `"other"`},
+		{IfModifiedSince: obj.LastModified.Add(-time.Hour)},
+		// If-Modified-Since is ignored when If-None-Match is given.
+		{IfNoneMatch: `"other"`, IfModifiedSince: obj.LastModified.Add(time.Hour)},
+	}
+	for _, opts := range modified {
+		got, err := h.Storage.GetReport(ctx, date, scan, check, opts)
+		if err != nil {
+			t.Fatalf("options %+v: expected no error, got: %v", opts, err)
+		}
+		if content := readObject(t, got); string(content) != "first" {
+			t.Fatalf("options %+v: expected report to be 'first', got: '%s'", opts, content)
+		}
+	}
+
+	if _, err := h.Storage.SaveReports(ctx, scanID, checkID, startedAt, []byte("second!"), false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	got, err := h.Storage.GetReport(ctx, date, scan, check, storage.GetOptions{IfNoneMatch: obj.ETag})
+	if err != nil {
+		t.Fatalf("expected no error after the report changed, got: %v", err)
+	}
+	readObject(t, got)
+	if got.ETag == obj.ETag {
+		t.Fatalf("expected ETag to change after the report changed, got: %s", got.ETag)
+	}
+}
+
+func testReportNotFound(t *testing.T, h Harness) {
+	_, err := h.Storage.GetReport(context.Background(), "dt=2019-11-16", "scan="+scanID, checkID+".json", storage.GetOptions{})
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrNotFound, err)
+	}
+}
+
+func testLogNotFound(t *testing.T, h Harness) {
+	_, err := h.Storage.GetLog(context.Background(), "dt=2019-11-16", "scan="+scanID, checkID+".log", storage.GetOptions{})
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrNotFound, err)
+	}
+}
+
+func testCanceledContext(t *testing.T, h Harness) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := h.Storage.SaveReports(ctx, scanID, checkID, startedAt, []byte("report"), false, storage.SaveOptions{}); err == nil {
+		t.Fatalf("expected error saving a report with a canceled context, got none")
+	}
+	if _, err := h.Storage.SaveLogs(ctx, scanID, checkID, startedAt, []byte("log")); err == nil {
+		t.Fatalf("expected error saving logs with a canceled context, got none")
+	}
+
+	key := "dt=2019-11-16/scan=" + scanID + "/" + checkID + ".json"
+	if _, ok := h.Object(Config.BucketReports, key); ok {
+		t.Fatalf("expected no report to be stored with a canceled context")
+	}
+}
+
+func testListChecks(t *testing.T, h Harness) {
+	ctx := context.Background()
+	checks := []string{
+		"0a1ed5a7-5f5a-4b5a-8e0c-4c4c1b7a0001",
+		"0a1ed5a7-5f5a-4b5a-8e0c-4c4c1b7a0002",
+		"0a1ed5a7-5f5a-4b5a-8e0c-4c4c1b7a0003",
+	}
+	for i, id := range checks {
+		if _, err := h.Storage.SaveReports(ctx, scanID, id, startedAt, []byte("report"), i == 0, storage.SaveOptions{}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if i == 2 {
+			continue
+		}
+		if _, err := h.Storage.SaveLogs(ctx, scanID, id, startedAt, []byte("logs")); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	// Results of other scans must not be listed.
+	if _, err := h.Storage.SaveReports(ctx, "5a0c1b0e-7d57-4d8a-9b3a-0f7f3d7e0000", checkID, startedAt, []byte("report"), false, storage.SaveOptions{}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	var got []storage.CheckObject
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatalf("expected pagination to end, got more than %d pages", pages)
+		}
+		page, err := h.Storage.ListChecks(ctx, "2019-11-16", scanID, token, 2)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(page.Checks) > 2 {
+			t.Fatalf("expected at most 2 checks per page, got: %d", len(page.Checks))
+		}
+		got = append(got, page.Checks...)
+		if page.Next == "" {
+			break
+		}
+		token = page.Next
+	}
+
+	expected := []struct{ id, kind string }{
+		{checks[0], storage.KindReport},
+		{checks[1], storage.KindReport},
+		{checks[2], storage.KindReport},
+		{checks[0], storage.KindLog},
+		{checks[1], storage.KindLog},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d checks, got: %+v", len(expected), got)
+	}
+	for i, e := range expected {
+		if got[i].CheckID != e.id || got[i].Kind != e.kind {
+			t.Fatalf("expected check %d to be %s %s, got: %+v", i, e.kind, e.id, got[i])
+		}
+		if got[i].Size != 6 && got[i].Kind == storage.KindReport || got[i].Size != 4 && got[i].Kind == storage.KindLog {
+			t.Fatalf("expected size of the content, got: %+v", got[i])
+		}
+		if got[i].LastModified.IsZero() {
+			t.Fatalf("expected last modified time, got: %+v", got[i])
+		}
+	}
+}
+
+func testListChecksEmpty(t *testing.T, h Harness) {
+	page, err := h.Storage.ListChecks(context.Background(), "2019-11-16", scanID, "", 10)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(page.Checks) != 0 || page.Next != "" {
+		t.Fatalf("expected empty page, got: %+v", page)
+	}
+}
+
+func testListChecksInvalid(t *testing.T, h Harness) {
+	_, err := h.Storage.ListChecks(context.Background(), "dt=2019-11-16", scanID, "", 10)
+	if !errors.Is(err, storage.ErrInvalidKey) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrInvalidKey, err)
+	}
+
+	_, err = h.Storage.ListChecks(context.Background(), "2019-11-16", "../"+scanID, "", 10)
+	if !errors.Is(err, storage.ErrInvalidKey) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrInvalidKey, err)
+	}
+
+	_, err = h.Storage.ListChecks(context.Background(), "2019-11-16", scanID, "not a token", 10)
+	if !errors.Is(err, storage.ErrInvalidToken) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrInvalidToken, err)
+	}
+}
+
+func testListScans(t *testing.T, h Harness) {
+	ctx := context.Background()
+	otherScan := "5a0c1b0e-7d57-4d8a-9b3a-0f7f3d7e0000"
+	saves := []struct {
+		scanID, checkID string
+		startedAt       time.Time
+		vulnerable      bool
+	}{
+		{scanID, checkID, startedAt, false},
+		{scanID, "0a1ed5a7-5f5a-4b5a-8e0c-4c4c1b7a0001", startedAt, true},
+		{otherScan, checkID, startedAt, false},
+		{otherScan, checkID, startedAt.AddDate(0, 0, 1), false},
+		// Scans out of the range must not be listed.
+		{otherScan, checkID, startedAt.AddDate(0, 0, 2), true},
+	}
+	for _, s := range saves {
+		if _, err := h.Storage.SaveReports(ctx, s.scanID, s.checkID, s.startedAt, []byte("report"), s.vulnerable, storage.SaveOptions{}); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+	}
+	// Scans with only logs must not be listed.
+	if _, err := h.Storage.SaveLogs(ctx, "3c9e3b8a-0d1e-4f5b-a2c4-6e7f8a9b0c1d", checkID, startedAt, []byte("logs")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	got, err := h.Storage.ListScans(ctx, startedAt, startedAt.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	day := startedAt.Truncate(24 * time.Hour)
+	expected := []storage.ScanSummary{
+		{ScanID: otherScan, Date: day, Checks: 1, Vulnerable: false},
+		{ScanID: scanID, Date: day, Checks: 2, Vulnerable: true},
+		{ScanID: otherScan, Date: day.AddDate(0, 0, 1), Checks: 1, Vulnerable: false},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d scans, got: %+v", len(expected), got)
+	}
+	for i, e := range expected {
+		if got[i].ScanID != e.ScanID || !got[i].Date.Equal(e.Date) || got[i].Checks != e.Checks || got[i].Vulnerable != e.Vulnerable {
+			t.Fatalf("expected scan %d to be %+v, got: %+v", i, e, got[i])
+		}
+	}
+
+	got, err = h.Storage.ListScans(ctx, startedAt.AddDate(0, 0, -3), startedAt.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no scans, got: %+v", got)
+	}
+}
+
+func testListScansInvalidRange(t *testing.T, h Harness) {
+	_, err := h.Storage.ListScans(context.Background(), startedAt, startedAt.AddDate(0, 0, -1))
+	if !errors.Is(err, storage.ErrInvalidRange) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrInvalidRange, err)
+	}
+
+	_, err = h.Storage.ListScans(context.Background(), startedAt, startedAt.AddDate(0, 0, storage.MaxScanRangeDays))
+	if !errors.Is(err, storage.ErrInvalidRange) {
+		t.Fatalf("expected error %v, got: %v", storage.ErrInvalidRange, err)
+	}
+}
+
+// readObject reads and closes the body of obj, checking its length
+// matches the one reported by the object.
+func readObject(t *testing.T, obj *storage.Object) []byte {
+	t.Helper()
+
+	defer obj.Body.Close()
+	content, err := ioutil.ReadAll(obj.Body)
+	if err != nil {
+		t.Fatalf("expected no error reading object, got: %v", err)
+	}
+	if int64(len(content)) != obj.ContentLength() {
+		t.Fatalf("expected content length %d, got: %d", obj.ContentLength(), len(content))
+	}
+	return content
+}
+
+// checkLink verifies that link points to the given key under the
+// configured link base.
+func checkLink(t *testing.T, link, kind, key string) {
+	t.Helper()
+
+	expected := strings.TrimSuffix(Config.LinkBase, "/") + "/" + kind + "/" + key
+	if link != expected {
+		t.Fatalf("expected link to %v, got: %v", expected, link)
+	}
+	if _, err := url.Parse(link); err != nil {
+		t.Fatalf("expected link to be a valid URL, got: %v", err)
+	}
+}
+
+func checkObject(t *testing.T, h Harness, bucket, key string, expected []byte) {
+	t.Helper()
+
+	got, ok := h.Object(bucket, key)
+	if !ok {
+		t.Fatalf("expected object %s in bucket %s, got none", key, bucket)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("expected object %s to be '%s', got: '%s'", key, expected, got)
+	}
+}
+
+// splitKey returns the date, scan and check elements of a key in the
+// form expected by GetReport and GetLog.
+func splitKey(key string) (date, scan, check string) {
+	dir, check := path.Split(key)
+	date, scan = path.Split(strings.TrimSuffix(dir, "/"))
+	return strings.TrimSuffix(date, "/"), scan, check
+}
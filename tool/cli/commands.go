@@ -35,7 +35,15 @@ type (
 		// Report date
 		Date string
 		// Scan ID
-		Scan        string
+		Scan string
+		// Redirect to a presigned URL instead of returning the content
+		Redirect string
+		// Date of the cached version of the object
+		IfModifiedSince string
+		// ETags of the cached versions of the object
+		IfNoneMatch string
+		// Single byte range to download, e.g. bytes=-4096 for the last 4KB
+		Range       string
 		PrettyPrint bool
 	}
 
@@ -46,6 +54,25 @@ type (
 		// Report date
 		Date string
 		// Scan ID
+		Scan string
+		// Redirect to a presigned URL instead of returning the content
+		Redirect string
+		// Date of the cached version of the object
+		IfModifiedSince string
+		// ETags of the cached versions of the object
+		IfNoneMatch string
+		// Single byte range to download, e.g. bytes=-4096 for the last 4KB
+		Range       string
+		PrettyPrint bool
+	}
+
+	// PutLogResultsCommand is the command line data structure for the putLog action of Results
+	PutLogResultsCommand struct {
+		// Log file name (<check ID>.log)
+		Check string
+		// Scan date partition (dt=YYYY-MM-DD)
+		Date string
+		// Scan partition (scan=<scan ID>)
 		Scan        string
 		PrettyPrint bool
 	}
@@ -64,18 +91,68 @@ type (
 		PrettyPrint bool
 	}
 
+	// ListChecksCommand is the command line data structure for the list action of checks
+	ListChecksCommand struct {
+		// Scan date (YYYY-MM-DD)
+		Date string
+		// Scan ID
+		Scan string
+		// Maximum number of checks to return
+		Limit int
+		// Token returned by a previous request to get the next page
+		Next        string
+		PrettyPrint bool
+	}
+
 	// ShowHealthcheckCommand is the command line data structure for the show action of healthcheck
 	ShowHealthcheckCommand struct {
 		PrettyPrint bool
 	}
+
+	// LogLinksCommand is the command line data structure for the log action of links
+	LogLinksCommand struct {
+		// Check ID
+		Check string
+		// Report date
+		Date string
+		// Scan ID
+		Scan        string
+		PrettyPrint bool
+	}
+
+	// ReportLinksCommand is the command line data structure for the report action of links
+	ReportLinksCommand struct {
+		// Check ID
+		Check string
+		// Report date
+		Date string
+		// Scan ID
+		Scan        string
+		PrettyPrint bool
+	}
+
+	// ShowReadinessCommand is the command line data structure for the show action of readiness
+	ShowReadinessCommand struct {
+		PrettyPrint bool
+	}
+
+	// ListScansCommand is the command line data structure for the list action of scans
+	ListScansCommand struct {
+		// First date of the range (YYYY-MM-DD)
+		From string
+		// Last date of the range (YYYY-MM-DD), defaults to from
+		To          string
+		PrettyPrint bool
+	}
 )
 
 // RegisterCommands registers the resource action CLI commands.
 func RegisterCommands(app *cobra.Command, c *client.Client) {
 	var command, sub *cobra.Command
 	command = &cobra.Command{
-		Use:   "get-log",
-		Short: `Download a log`,
+		Use: "get-log",
+		Short: `Download a log, or a range of it.
+With redirect, or if the service is configured to redirect by default, answers 302 Found with a time-limited URL to download it directly from the storage, if the storage supports it.`,
 	}
 	tmp1 := new(GetLogResultsCommand)
 	sub = &cobra.Command{
@@ -88,8 +165,9 @@ func RegisterCommands(app *cobra.Command, c *client.Client) {
 	command.AddCommand(sub)
 	app.AddCommand(command)
 	command = &cobra.Command{
-		Use:   "get-report",
-		Short: `Download a report`,
+		Use: "get-report",
+		Short: `Download a report, or a range of it.
+With redirect, or if the service is configured to redirect by default, answers 302 Found with a time-limited URL to download it directly from the storage, if the storage supports it.`,
 	}
 	tmp2 := new(GetReportResultsCommand)
 	sub = &cobra.Command{
@@ -102,10 +180,63 @@ func RegisterCommands(app *cobra.Command, c *client.Client) {
 	command.AddCommand(sub)
 	app.AddCommand(command)
 	command = &cobra.Command{
-		Use:   "raw",
-		Short: `Update the Raw of a Check`,
+		Use:   "list",
+		Short: `list action`,
+	}
+	tmp3 := new(ListChecksCommand)
+	sub = &cobra.Command{
+		Use:   `checks ["/v1/scans/DATE/SCAN/checks"]`,
+		Short: ``,
+		RunE:  func(cmd *cobra.Command, args []string) error { return tmp3.Run(c, args) },
+	}
+	tmp3.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp3.PrettyPrint, "pp", false, "Pretty print response body")
+	command.AddCommand(sub)
+	tmp4 := new(ListScansCommand)
+	sub = &cobra.Command{
+		Use:   `scans ["/v1/scans"]`,
+		Short: ``,
+		RunE:  func(cmd *cobra.Command, args []string) error { return tmp4.Run(c, args) },
+	}
+	tmp4.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp4.PrettyPrint, "pp", false, "Pretty print response body")
+	command.AddCommand(sub)
+	app.AddCommand(command)
+	command = &cobra.Command{
+		Use:   "log",
+		Short: `Get a time-limited URL to download a log directly from the storage`,
 	}
-	tmp3 := new(RawResultsCommand)
+	tmp5 := new(LogLinksCommand)
+	sub = &cobra.Command{
+		Use:   `links ["/v1/links/logs/DATE/SCAN/CHECK"]`,
+		Short: ``,
+		RunE:  func(cmd *cobra.Command, args []string) error { return tmp5.Run(c, args) },
+	}
+	tmp5.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp5.PrettyPrint, "pp", false, "Pretty print response body")
+	command.AddCommand(sub)
+	app.AddCommand(command)
+	command = &cobra.Command{
+		Use: "put-log",
+		Short: `Upload the log of a check streaming the request body to the storage.
+The body is either the raw log or a multipart/form-data form with the log in the "log" field.`,
+	}
+	tmp6 := new(PutLogResultsCommand)
+	sub = &cobra.Command{
+		Use:   `results ["/v1/logs/DATE/SCAN/CHECK"]`,
+		Short: ``,
+		RunE:  func(cmd *cobra.Command, args []string) error { return tmp6.Run(c, args) },
+	}
+	tmp6.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp6.PrettyPrint, "pp", false, "Pretty print response body")
+	command.AddCommand(sub)
+	app.AddCommand(command)
+	command = &cobra.Command{
+		Use: "raw",
+		Short: `Update the Raw of a Check.
+Answers 202 Accepted if the storage is unavailable and the logs were spooled to be stored later.`,
+	}
+	tmp7 := new(RawResultsCommand)
 	sub = &cobra.Command{
 		Use:   `results ["/v1/raw"]`,
 		Short: ``,
@@ -114,22 +245,22 @@ func RegisterCommands(app *cobra.Command, c *client.Client) {
 Payload example:
 
 {
-   "check_id": "64fbe3e2-93ab-4cfd-9939-7e7439af3bb4",
+   "check_id": "bff0b171-f1d0-4ba0-a597-58aa1a608c1c",
    "raw": "{ raw : \"BASE_64_FORMAT\" }",
-   "scan_id": "9761ce78-ec8a-41b3-855f-d13e2e862adc",
-   "scan_start_time": "2006-04-22T05:57:42Z"
+   "scan_id": "1f3886d1-f8e1-447b-98f7-eed7f4b73ca1",
+   "scan_start_time": "1982-10-25T14:20:52Z"
 }`,
-		RunE: func(cmd *cobra.Command, args []string) error { return tmp3.Run(c, args) },
+		RunE: func(cmd *cobra.Command, args []string) error { return tmp7.Run(c, args) },
 	}
-	tmp3.RegisterFlags(sub, c)
-	sub.PersistentFlags().BoolVar(&tmp3.PrettyPrint, "pp", false, "Pretty print response body")
+	tmp7.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp7.PrettyPrint, "pp", false, "Pretty print response body")
 	command.AddCommand(sub)
 	app.AddCommand(command)
 	command = &cobra.Command{
 		Use:   "report",
-		Short: `Update the Report of a Check`,
+		Short: `report action`,
 	}
-	tmp4 := new(ReportResultsCommand)
+	tmp8 := new(ReportResultsCommand)
 	sub = &cobra.Command{
 		Use:   `results ["/v1/report"]`,
 		Short: ``,
@@ -138,29 +269,48 @@ Payload example:
 Payload example:
 
 {
-   "check_id": "7a4c4427-5dac-4225-a600-016ce3ff4bbf",
+   "check_id": "d42e154e-fe29-492a-8d9e-5ceffbc29cd2",
+   "force": true,
    "report": "{ report : \"{\"report\":\"{\\\"check_id\\\":\\\"aabbccdd-abcd-0123-4567-abcdef012345\\\", .....}}\" }",
-   "scan_id": "a4e012e5-d24d-4dc6-a49b-e97d862c76bf",
-   "scan_start_time": "1995-08-24T02:02:26Z"
+   "scan_id": "54e6a1d4-56dd-49ad-9b07-a0a921a5c9c8",
+   "scan_start_time": "2007-09-15T04:41:44Z"
 }`,
-		RunE: func(cmd *cobra.Command, args []string) error { return tmp4.Run(c, args) },
+		RunE: func(cmd *cobra.Command, args []string) error { return tmp8.Run(c, args) },
 	}
-	tmp4.RegisterFlags(sub, c)
-	sub.PersistentFlags().BoolVar(&tmp4.PrettyPrint, "pp", false, "Pretty print response body")
+	tmp8.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp8.PrettyPrint, "pp", false, "Pretty print response body")
+	command.AddCommand(sub)
+	tmp9 := new(ReportLinksCommand)
+	sub = &cobra.Command{
+		Use:   `links ["/v1/links/reports/DATE/SCAN/CHECK"]`,
+		Short: ``,
+		RunE:  func(cmd *cobra.Command, args []string) error { return tmp9.Run(c, args) },
+	}
+	tmp9.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp9.PrettyPrint, "pp", false, "Pretty print response body")
 	command.AddCommand(sub)
 	app.AddCommand(command)
 	command = &cobra.Command{
 		Use:   "show",
-		Short: `Get the health status for the application`,
+		Short: `show action`,
 	}
-	tmp5 := new(ShowHealthcheckCommand)
+	tmp10 := new(ShowHealthcheckCommand)
 	sub = &cobra.Command{
 		Use:   `healthcheck ["/healthcheck"]`,
 		Short: ``,
-		RunE:  func(cmd *cobra.Command, args []string) error { return tmp5.Run(c, args) },
+		RunE:  func(cmd *cobra.Command, args []string) error { return tmp10.Run(c, args) },
 	}
-	tmp5.RegisterFlags(sub, c)
-	sub.PersistentFlags().BoolVar(&tmp5.PrettyPrint, "pp", false, "Pretty print response body")
+	tmp10.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp10.PrettyPrint, "pp", false, "Pretty print response body")
+	command.AddCommand(sub)
+	tmp11 := new(ShowReadinessCommand)
+	sub = &cobra.Command{
+		Use:   `readiness ["/readiness"]`,
+		Short: ``,
+		RunE:  func(cmd *cobra.Command, args []string) error { return tmp11.Run(c, args) },
+	}
+	tmp11.RegisterFlags(sub, c)
+	sub.PersistentFlags().BoolVar(&tmp11.PrettyPrint, "pp", false, "Pretty print response body")
 	command.AddCommand(sub)
 	app.AddCommand(command)
 }
@@ -328,7 +478,16 @@ func (cmd *GetLogResultsCommand) Run(c *client.Client, args []string) error {
 	}
 	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
 	ctx := goa.WithLogger(context.Background(), logger)
-	resp, err := c.GetLogResults(ctx, path)
+	var tmp12 *bool
+	if cmd.Redirect != "" {
+		var err error
+		tmp12, err = boolVal(cmd.Redirect)
+		if err != nil {
+			goa.LogError(ctx, "failed to parse flag into *bool value", "flag", "--redirect", "err", err)
+			return err
+		}
+	}
+	resp, err := c.GetLogResults(ctx, path, tmp12, stringFlagVal("If-Modified-Since", cmd.IfModifiedSince), stringFlagVal("If-None-Match", cmd.IfNoneMatch), stringFlagVal("Range", cmd.Range))
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
@@ -346,6 +505,11 @@ func (cmd *GetLogResultsCommand) RegisterFlags(cc *cobra.Command, c *client.Clie
 	cc.Flags().StringVar(&cmd.Date, "date", date, `Report date`)
 	var scan string
 	cc.Flags().StringVar(&cmd.Scan, "scan", scan, `Scan ID`)
+	var redirect string
+	cc.Flags().StringVar(&cmd.Redirect, "redirect", redirect, `Redirect to a presigned URL instead of returning the content`)
+	cc.Flags().StringVar(&cmd.IfModifiedSince, "If-Modified-Since", "", `Date of the cached version of the object`)
+	cc.Flags().StringVar(&cmd.IfNoneMatch, "If-None-Match", "", `ETags of the cached versions of the object`)
+	cc.Flags().StringVar(&cmd.Range, "Range", "", `Single byte range to download, e.g. bytes=-4096 for the last 4KB`)
 }
 
 // Run makes the HTTP request corresponding to the GetReportResultsCommand command.
@@ -358,7 +522,16 @@ func (cmd *GetReportResultsCommand) Run(c *client.Client, args []string) error {
 	}
 	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
 	ctx := goa.WithLogger(context.Background(), logger)
-	resp, err := c.GetReportResults(ctx, path)
+	var tmp13 *bool
+	if cmd.Redirect != "" {
+		var err error
+		tmp13, err = boolVal(cmd.Redirect)
+		if err != nil {
+			goa.LogError(ctx, "failed to parse flag into *bool value", "flag", "--redirect", "err", err)
+			return err
+		}
+	}
+	resp, err := c.GetReportResults(ctx, path, tmp13, stringFlagVal("If-Modified-Since", cmd.IfModifiedSince), stringFlagVal("If-None-Match", cmd.IfNoneMatch), stringFlagVal("Range", cmd.Range))
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
@@ -376,6 +549,41 @@ func (cmd *GetReportResultsCommand) RegisterFlags(cc *cobra.Command, c *client.C
 	cc.Flags().StringVar(&cmd.Date, "date", date, `Report date`)
 	var scan string
 	cc.Flags().StringVar(&cmd.Scan, "scan", scan, `Scan ID`)
+	var redirect string
+	cc.Flags().StringVar(&cmd.Redirect, "redirect", redirect, `Redirect to a presigned URL instead of returning the content`)
+	cc.Flags().StringVar(&cmd.IfModifiedSince, "If-Modified-Since", "", `Date of the cached version of the object`)
+	cc.Flags().StringVar(&cmd.IfNoneMatch, "If-None-Match", "", `ETags of the cached versions of the object`)
+	cc.Flags().StringVar(&cmd.Range, "Range", "", `Single byte range to download, e.g. bytes=-4096 for the last 4KB`)
+}
+
+// Run makes the HTTP request corresponding to the PutLogResultsCommand command.
+func (cmd *PutLogResultsCommand) Run(c *client.Client, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		path = fmt.Sprintf("/v1/logs/%v/%v/%v", url.QueryEscape(cmd.Date), url.QueryEscape(cmd.Scan), url.QueryEscape(cmd.Check))
+	}
+	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
+	ctx := goa.WithLogger(context.Background(), logger)
+	resp, err := c.PutLogResults(ctx, path)
+	if err != nil {
+		goa.LogError(ctx, "failed", "err", err)
+		return err
+	}
+
+	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+	return nil
+}
+
+// RegisterFlags registers the command flags with the command line.
+func (cmd *PutLogResultsCommand) RegisterFlags(cc *cobra.Command, c *client.Client) {
+	var check string
+	cc.Flags().StringVar(&cmd.Check, "check", check, `Log file name (<check ID>.log)`)
+	var date string
+	cc.Flags().StringVar(&cmd.Date, "date", date, `Scan date partition (dt=YYYY-MM-DD)`)
+	var scan string
+	cc.Flags().StringVar(&cmd.Scan, "scan", scan, `Scan partition (scan=<scan ID>)`)
 }
 
 // Run makes the HTTP request corresponding to the RawResultsCommand command.
@@ -444,6 +652,37 @@ func (cmd *ReportResultsCommand) RegisterFlags(cc *cobra.Command, c *client.Clie
 	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request content type override, e.g. 'application/x-www-form-urlencoded'")
 }
 
+// Run makes the HTTP request corresponding to the ListChecksCommand command.
+func (cmd *ListChecksCommand) Run(c *client.Client, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		path = fmt.Sprintf("/v1/scans/%v/%v/checks", url.QueryEscape(cmd.Date), url.QueryEscape(cmd.Scan))
+	}
+	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
+	ctx := goa.WithLogger(context.Background(), logger)
+	resp, err := c.ListChecks(ctx, path, intFlagVal("limit", cmd.Limit), stringFlagVal("next", cmd.Next))
+	if err != nil {
+		goa.LogError(ctx, "failed", "err", err)
+		return err
+	}
+
+	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+	return nil
+}
+
+// RegisterFlags registers the command flags with the command line.
+func (cmd *ListChecksCommand) RegisterFlags(cc *cobra.Command, c *client.Client) {
+	var date string
+	cc.Flags().StringVar(&cmd.Date, "date", date, `Scan date (YYYY-MM-DD)`)
+	var scan string
+	cc.Flags().StringVar(&cmd.Scan, "scan", scan, `Scan ID`)
+	cc.Flags().IntVar(&cmd.Limit, "limit", 100, `Maximum number of checks to return`)
+	var next string
+	cc.Flags().StringVar(&cmd.Next, "next", next, `Token returned by a previous request to get the next page`)
+}
+
 // Run makes the HTTP request corresponding to the ShowHealthcheckCommand command.
 func (cmd *ShowHealthcheckCommand) Run(c *client.Client, args []string) error {
 	var path string
@@ -467,3 +706,115 @@ func (cmd *ShowHealthcheckCommand) Run(c *client.Client, args []string) error {
 // RegisterFlags registers the command flags with the command line.
 func (cmd *ShowHealthcheckCommand) RegisterFlags(cc *cobra.Command, c *client.Client) {
 }
+
+// Run makes the HTTP request corresponding to the LogLinksCommand command.
+func (cmd *LogLinksCommand) Run(c *client.Client, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		path = fmt.Sprintf("/v1/links/logs/%v/%v/%v", url.QueryEscape(cmd.Date), url.QueryEscape(cmd.Scan), url.QueryEscape(cmd.Check))
+	}
+	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
+	ctx := goa.WithLogger(context.Background(), logger)
+	resp, err := c.LogLinks(ctx, path)
+	if err != nil {
+		goa.LogError(ctx, "failed", "err", err)
+		return err
+	}
+
+	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+	return nil
+}
+
+// RegisterFlags registers the command flags with the command line.
+func (cmd *LogLinksCommand) RegisterFlags(cc *cobra.Command, c *client.Client) {
+	var check string
+	cc.Flags().StringVar(&cmd.Check, "check", check, `Check ID`)
+	var date string
+	cc.Flags().StringVar(&cmd.Date, "date", date, `Report date`)
+	var scan string
+	cc.Flags().StringVar(&cmd.Scan, "scan", scan, `Scan ID`)
+}
+
+// Run makes the HTTP request corresponding to the ReportLinksCommand command.
+func (cmd *ReportLinksCommand) Run(c *client.Client, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		path = fmt.Sprintf("/v1/links/reports/%v/%v/%v", url.QueryEscape(cmd.Date), url.QueryEscape(cmd.Scan), url.QueryEscape(cmd.Check))
+	}
+	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
+	ctx := goa.WithLogger(context.Background(), logger)
+	resp, err := c.ReportLinks(ctx, path)
+	if err != nil {
+		goa.LogError(ctx, "failed", "err", err)
+		return err
+	}
+
+	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+	return nil
+}
+
+// RegisterFlags registers the command flags with the command line.
+func (cmd *ReportLinksCommand) RegisterFlags(cc *cobra.Command, c *client.Client) {
+	var check string
+	cc.Flags().StringVar(&cmd.Check, "check", check, `Check ID`)
+	var date string
+	cc.Flags().StringVar(&cmd.Date, "date", date, `Report date`)
+	var scan string
+	cc.Flags().StringVar(&cmd.Scan, "scan", scan, `Scan ID`)
+}
+
+// Run makes the HTTP request corresponding to the ShowReadinessCommand command.
+func (cmd *ShowReadinessCommand) Run(c *client.Client, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		path = "/readiness"
+	}
+	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
+	ctx := goa.WithLogger(context.Background(), logger)
+	resp, err := c.ShowReadiness(ctx, path)
+	if err != nil {
+		goa.LogError(ctx, "failed", "err", err)
+		return err
+	}
+
+	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+	return nil
+}
+
+// RegisterFlags registers the command flags with the command line.
+func (cmd *ShowReadinessCommand) RegisterFlags(cc *cobra.Command, c *client.Client) {
+}
+
+// Run makes the HTTP request corresponding to the ListScansCommand command.
+func (cmd *ListScansCommand) Run(c *client.Client, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		path = "/v1/scans"
+	}
+	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
+	ctx := goa.WithLogger(context.Background(), logger)
+	resp, err := c.ListScans(ctx, path, cmd.From, stringFlagVal("to", cmd.To))
+	if err != nil {
+		goa.LogError(ctx, "failed", "err", err)
+		return err
+	}
+
+	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+	return nil
+}
+
+// RegisterFlags registers the command flags with the command line.
+func (cmd *ListScansCommand) RegisterFlags(cc *cobra.Command, c *client.Client) {
+	var from string
+	cc.Flags().StringVar(&cmd.From, "from", from, `First date of the range (YYYY-MM-DD)`)
+	var to string
+	cc.Flags().StringVar(&cmd.To, "to", to, `Last date of the range (YYYY-MM-DD), defaults to from`)
+}
@@ -28,7 +28,17 @@ func main() {
 	app.PersistentFlags().DurationVarP(&httpClient.Timeout, "timeout", "t", time.Duration(20)*time.Second, "Set the request timeout")
 	app.PersistentFlags().BoolVar(&c.Dump, "dump", false, "Dump HTTP request and response.")
 
+	// Register signer flags
+	var key, format string
+	app.PersistentFlags().StringVar(&key, "key", "", "API key used for authentication")
+	app.PersistentFlags().StringVar(&format, "format", "Bearer %s", "Format used to create auth header or query from key")
+
+	// Parse flags and setup signers
+	app.ParseFlags(os.Args)
+	resultsSigner := newResultsSigner(key, format)
+
 	// Initialize API client
+	c.SetResultsSigner(resultsSigner)
 	c.UserAgent = "vulcan-results-cli/0"
 
 	// Register API commands
@@ -47,3 +57,15 @@ func newHTTPClient() *http.Client {
 	// disable cert validation or...)
 	return http.DefaultClient
 }
+
+// newResultsSigner returns the request signer used for authenticating
+// against the results security scheme.
+func newResultsSigner(key, format string) goaclient.Signer {
+	return &goaclient.APIKeySigner{
+		SignQuery: false,
+		KeyName:   "Authorization",
+		KeyValue:  key,
+		Format:    format,
+	}
+
+}